@@ -0,0 +1,583 @@
+// Package analysis inspects policies for privilege escalation, redundancy and conflicts.
+package analysis
+
+import (
+	"fmt"
+	"github.com/PM-Master/policy-machine-go/epp"
+	"github.com/PM-Master/policy-machine-go/internal/sets"
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
+	"github.com/PM-Master/policy-machine-go/pdp"
+	"github.com/PM-Master/policy-machine-go/pip/memory"
+	"sort"
+	"strings"
+)
+
+// The administrative operations considered by the escalation analysis. A user can assign a node to another node if
+// they have AssignOp on the child and AssignToOp on the parent, associate a user attribute with a target if they have
+// AssociateOp on both, and create obligations if they have CreateObligationOp on any node.
+const (
+	AssignOp           = "assign"
+	AssignToOp         = "assign_to"
+	AssociateOp        = "associate"
+	CreateObligationOp = "create_obligation"
+)
+
+type (
+	// Escalation describes permissions a user can gain by exercising their administrative permissions.
+	Escalation struct {
+		User string `json:"user"`
+		// Gained holds the permissions the user gains by taking the steps, keyed by target.
+		Gained map[string]graph.Operations `json:"gained"`
+		Steps  []string                    `json:"steps"`
+		// Unbounded is true if the user can create obligations. Obligation responses are applied without checking
+		// the permissions of the user that created them, so the user can gain any permission.
+		Unbounded bool `json:"unbounded,omitempty"`
+	}
+
+	// action is an administrative action a user can take.
+	action struct {
+		description string
+		apply       func(fe ngac.FunctionalEntity) error
+		// undo reverts apply. Actions without undo are evaluated on a copy of the policy.
+		undo func(fe ngac.FunctionalEntity) error
+		// affected returns the targets the user's permissions can change on when the action is taken. It is only
+		// used for actions that can be undone.
+		affected func() ([]string, error)
+	}
+
+	// escalationState is the policy and the user's permissions after the steps taken so far.
+	escalationState struct {
+		user        string
+		fe          ngac.FunctionalEntity
+		permissions map[string]graph.Operations
+		// ancestors and descendants cache the nodes each node is contained in and contains in fe
+		ancestors   map[string]map[string]bool
+		descendants map[string]map[string]bool
+	}
+)
+
+// Escalations returns the escalations of every user in the policy that can gain permissions, ordered by user.
+func Escalations(fe ngac.FunctionalEntity) ([]Escalation, error) {
+	users, err := fe.Graph().Find(graph.User, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	escalations := make([]Escalation, 0)
+	for _, user := range sortedNames(users) {
+		escalation, err := UserEscalation(fe, user)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(escalation.Steps) > 0 || escalation.Unbounded {
+			escalations = append(escalations, escalation)
+		}
+	}
+
+	return escalations, nil
+}
+
+// UserEscalation finds the permissions the user can gain and the steps to gain them. The policy is not modified.
+//
+// Administrative actions only add assignments and associations, and responses of obligations the user can trigger
+// are applied, so the analysis repeatedly takes any action that gives the user a permission they do not have until
+// no such action remains. Prohibitions and constraints of the policy are respected when evaluating each action.
+//
+// The steps are the first escalating action found at each point, so they show one way to gain the permissions, not
+// the shortest one. Assignments and associations are evaluated by taking them on a copy of the policy and undoing
+// them, recomputing only the permissions on the nodes below the assigned child or the targets the user gains
+// associations with. Conditions that depend on nodes elsewhere in the graph are not re-evaluated for an action.
+func UserEscalation(fe ngac.FunctionalEntity, user string) (Escalation, error) {
+	clone, err := memory.Clone(fe)
+	if err != nil {
+		return Escalation{}, err
+	}
+
+	state, err := newEscalationState(clone, user)
+	if err != nil {
+		return Escalation{}, err
+	}
+
+	initial := state.permissions
+	escalation := Escalation{User: user, Steps: make([]string, 0)}
+	for {
+		next, step, err := state.escalate()
+		if err != nil {
+			return Escalation{}, err
+		}
+
+		if next == nil {
+			break
+		}
+
+		state = next
+		escalation.Steps = append(escalation.Steps, step)
+	}
+
+	escalation.Gained = gainedPermissions(initial, state.permissions)
+	for _, target := range sortedTargets(state.permissions) {
+		if state.permissions[target].Contains(CreateObligationOp) {
+			escalation.Unbounded = true
+			escalation.Steps = append(escalation.Steps,
+				fmt.Sprintf("%s creates an obligation (%s on %s)", user, CreateObligationOp, target))
+			break
+		}
+	}
+
+	return escalation, nil
+}
+
+func newEscalationState(fe ngac.FunctionalEntity, user string) (*escalationState, error) {
+	nodes, err := fe.Graph().GetNodes()
+	if err != nil {
+		return nil, err
+	}
+
+	permissions, err := pdp.NewDecider(fe.Graph(), fe.Prohibitions()).ListPermissionsBatch(user, sortedNames(nodes))
+	if err != nil {
+		return nil, err
+	}
+
+	return &escalationState{
+		user:        user,
+		fe:          fe,
+		permissions: permissions,
+		ancestors:   make(map[string]map[string]bool),
+		descendants: make(map[string]map[string]bool),
+	}, nil
+}
+
+// escalate returns the state after the first action that gives the user a new permission, or nil if there is none.
+func (s *escalationState) escalate() (*escalationState, string, error) {
+	actions, err := s.actions()
+	if err != nil {
+		return nil, "", err
+	}
+
+	decider := pdp.NewDecider(s.fe.Graph(), s.fe.Prohibitions())
+	for _, a := range actions {
+		if a.undo == nil {
+			next, err := s.trial(a)
+			if err != nil || next != nil {
+				return next, a.description, err
+			}
+
+			continue
+		}
+
+		targets, err := a.affected()
+		if err != nil {
+			return nil, "", err
+		}
+
+		// actions that fail, for example because they violate a constraint, cannot be taken
+		if err = a.apply(s.fe); err != nil {
+			continue
+		}
+
+		permissions, err := decider.ListPermissionsBatch(s.user, targets)
+		if err != nil {
+			return nil, "", err
+		}
+
+		if len(gainedPermissions(s.permissions, permissions)) > 0 {
+			// the action is kept, so compute the permissions on every node for the next step
+			next, err := newEscalationState(s.fe, s.user)
+			return next, a.description, err
+		}
+
+		if err = a.undo(s.fe); err != nil {
+			return nil, "", fmt.Errorf("error undoing %q: %w", a.description, err)
+		}
+	}
+
+	return nil, "", nil
+}
+
+// trial takes the action on a copy of the policy and returns the resulting state if the user gains a permission.
+func (s *escalationState) trial(a action) (*escalationState, error) {
+	trial, err := memory.Clone(s.fe)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = a.apply(trial); err != nil {
+		return nil, nil
+	}
+
+	next, err := newEscalationState(trial, s.user)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(gainedPermissions(s.permissions, next.permissions)) == 0 {
+		return nil, nil
+	}
+
+	return next, nil
+}
+
+// actions returns the administrative actions the user is allowed to take that could give them new permissions.
+func (s *escalationState) actions() ([]action, error) {
+	g := s.fe.Graph()
+	nodes, err := g.GetNodes()
+	if err != nil {
+		return nil, err
+	}
+
+	memberships, err := s.ancestorsOf(s.user)
+	if err != nil {
+		return nil, err
+	}
+
+	actions := make([]action, 0)
+	names := sortedNames(nodes)
+	for _, child := range names {
+		childNode := nodes[child]
+		isMember := child == s.user || memberships[child]
+
+		// assign the user, or an attribute the user is in, to another user attribute or assign an object or object
+		// attribute to an attribute the user has permissions on
+		isUserSide := childNode.Kind == graph.User || childNode.Kind == graph.UserAttribute
+		if s.can(child, AssignOp) && (isMember || !isUserSide) {
+			for _, parent := range names {
+				if !s.can(parent, AssignToOp) || graph.CheckAssignment(childNode.Kind, nodes[parent].Kind) != nil {
+					continue
+				}
+
+				contained, err := s.contained(child, parent)
+				if err != nil {
+					return nil, err
+				}
+
+				if contained {
+					continue
+				}
+
+				child, parent := child, parent
+				actions = append(actions, action{
+					description: fmt.Sprintf("%s assigns %s to %s", s.user, child, parent),
+					apply: func(fe ngac.FunctionalEntity) error {
+						return fe.Graph().Assign(child, parent)
+					},
+					undo: func(fe ngac.FunctionalEntity) error {
+						return fe.Graph().Deassign(child, parent)
+					},
+					affected: func() ([]string, error) {
+						return s.assignAffected(child, parent, isMember)
+					},
+				})
+			}
+		}
+
+		// associate an attribute the user is in with a target
+		if childNode.Kind == graph.UserAttribute && isMember && s.can(child, AssociateOp) {
+			for _, target := range names {
+				if !s.can(target, AssociateOp) || graph.CheckAssociation(childNode.Kind, nodes[target].Kind) != nil {
+					continue
+				}
+
+				a, err := s.associateAction(child, target)
+				if err != nil {
+					return nil, err
+				}
+
+				actions = append(actions, a)
+			}
+		}
+	}
+
+	obligationActions, err := s.obligationActions(names)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(actions, obligationActions...), nil
+}
+
+// associateAction returns the action of associating the user attribute with the target granting all operations.
+// Undoing the action restores the association the user attribute had with the target, if any.
+func (s *escalationState) associateAction(ua string, target string) (action, error) {
+	g := s.fe.Graph()
+	assocs, err := g.GetAssociationsForSubject(ua)
+	if err != nil {
+		return action{}, err
+	}
+
+	conditions, err := g.GetAssociationConditions(ua)
+	if err != nil {
+		return action{}, err
+	}
+
+	validity, err := g.GetAssociationValidity(ua)
+	if err != nil {
+		return action{}, err
+	}
+
+	ops, existed := assocs[target]
+	condition := conditions[target]
+	bounds := validity[target]
+
+	return action{
+		description: fmt.Sprintf("%s associates %s with %s granting %s", s.user, ua, target, graph.AllOps),
+		apply: func(fe ngac.FunctionalEntity) error {
+			return fe.Graph().Associate(ua, target, graph.ToOps(graph.AllOps))
+		},
+		undo: func(fe ngac.FunctionalEntity) error {
+			if !existed {
+				return fe.Graph().Dissociate(ua, target)
+			}
+
+			// an empty condition is not a valid condition, so unconditional associations are restored with Associate
+			restore := func() error { return fe.Graph().Associate(ua, target, ops) }
+			if condition != "" {
+				restore = func() error { return fe.Graph().AssociateWithCondition(ua, target, ops, condition) }
+			}
+
+			if err := restore(); err != nil {
+				return err
+			}
+
+			if bounds.IsZero() {
+				return nil
+			}
+
+			return fe.Graph().SetAssociationValidity(ua, target, bounds)
+		},
+		affected: func() ([]string, error) {
+			return s.descendantsOf(target)
+		},
+	}, nil
+}
+
+// assignAffected returns the targets the user's permissions can change on when the child is assigned to the parent:
+// the child and the nodes it contains and, if the user is the child or is contained in it, the targets of the
+// associations the user gains and the nodes they contain.
+func (s *escalationState) assignAffected(child string, parent string, isMember bool) ([]string, error) {
+	affected, err := s.descendantsOf(child)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isMember {
+		return affected, nil
+	}
+
+	ancestors, err := s.ancestorsOf(parent)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool)
+	for _, name := range affected {
+		seen[name] = true
+	}
+
+	for _, ua := range sets.Sorted(ancestors) {
+		assocs, err := s.fe.Graph().GetAssociationsForSubject(ua)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, target := range sortedTargets(assocs) {
+			descendants, err := s.descendantsOf(target)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, name := range descendants {
+				if !seen[name] {
+					seen[name] = true
+					affected = append(affected, name)
+				}
+			}
+		}
+	}
+
+	return affected, nil
+}
+
+// obligationActions returns the events the user can cause that trigger an obligation.
+func (s *escalationState) obligationActions(names []string) ([]action, error) {
+	obligations, err := s.fe.Obligations().All()
+	if err != nil {
+		return nil, err
+	}
+
+	actions := make([]action, 0)
+	for _, obligation := range obligations {
+		if obligation.Disabled {
+			continue
+		}
+
+		for _, op := range obligation.Event.Operations {
+			// the args of events with args are not known
+			if len(op.Args) > 0 {
+				continue
+			}
+
+			targets := obligation.Event.Containers
+			if len(targets) == 0 {
+				targets = names
+			}
+
+			for _, target := range targets {
+				eventCtx := epp.EventContext{User: s.user, Event: op.Operation, Target: target}
+				matches, err := eventCtx.Matches(obligation.Event)
+				if err != nil {
+					return nil, err
+				}
+
+				if !matches || !s.can(target, op.Operation) {
+					continue
+				}
+
+				label := obligation.Label
+				actions = append(actions, action{
+					description: fmt.Sprintf("%s performs %s on %s triggering obligation %s", s.user, op.Operation, target, label),
+					apply: func(fe ngac.FunctionalEntity) error {
+						return epp.NewEPP(fe).ProcessEvent(eventCtx)
+					},
+				})
+
+				// one target is enough to trigger the obligation
+				break
+			}
+		}
+	}
+
+	return actions, nil
+}
+
+func (s *escalationState) can(target string, op string) bool {
+	return s.permissions[target].Contains(op)
+}
+
+// ancestorsOf returns the node and the nodes it is contained in. The result is cached for the state, so it must only
+// be called while the graph is unchanged or has been restored.
+func (s *escalationState) ancestorsOf(name string) (map[string]bool, error) {
+	return s.closure(name, s.ancestors, s.fe.Graph().GetParents)
+}
+
+// descendantsOf returns the node and the nodes it contains, sorted by name. The result is cached like ancestorsOf.
+func (s *escalationState) descendantsOf(name string) ([]string, error) {
+	descendants, err := s.closure(name, s.descendants, s.fe.Graph().GetChildren)
+	if err != nil {
+		return nil, err
+	}
+
+	return sets.Sorted(descendants), nil
+}
+
+func (s *escalationState) closure(name string, cache map[string]map[string]bool,
+	next func(string) (map[string]graph.Node, error)) (map[string]bool, error) {
+	if nodes, ok := cache[name]; ok {
+		return nodes, nil
+	}
+
+	nodes, err := sets.Reachable(name, func(node string) (map[string]bool, error) {
+		adjacent, err := next(node)
+		if err != nil {
+			return nil, err
+		}
+
+		names := make(map[string]bool, len(adjacent))
+		for n := range adjacent {
+			names[n] = true
+		}
+
+		return names, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	nodes[name] = true
+
+	cache[name] = nodes
+	return nodes, nil
+}
+
+// contained returns true if the child is already in the parent or assigning it would create a cycle.
+func (s *escalationState) contained(child string, parent string) (bool, error) {
+	childAncestors, err := s.ancestorsOf(child)
+	if err != nil || childAncestors[parent] {
+		return childAncestors[parent], err
+	}
+
+	parentAncestors, err := s.ancestorsOf(parent)
+	if err != nil {
+		return false, err
+	}
+
+	return parentAncestors[child], nil
+}
+
+// gainedPermissions returns the operations in after that are not in before, keyed by target.
+func gainedPermissions(before map[string]graph.Operations, after map[string]graph.Operations) map[string]graph.Operations {
+	gained := make(map[string]graph.Operations)
+	for target, ops := range after {
+		for op := range ops {
+			if before[target][op] || (before[target][graph.AllOps] && op != graph.AllOps) {
+				continue
+			}
+
+			if _, ok := gained[target]; !ok {
+				gained[target] = make(graph.Operations)
+			}
+			gained[target].Add(op)
+		}
+	}
+
+	return gained
+}
+
+// String formats the escalation as the steps followed by the gained permissions.
+func (e Escalation) String() string {
+	b := strings.Builder{}
+	fmt.Fprintf(&b, "%s can escalate privileges:\n", e.User)
+	for i, step := range e.Steps {
+		fmt.Fprintf(&b, "  %d. %s\n", i+1, step)
+	}
+
+	if e.Unbounded {
+		fmt.Fprintf(&b, "  gains: any permission\n")
+	}
+
+	for _, target := range sortedTargets(e.Gained) {
+		fmt.Fprintf(&b, "  gains %s on %s\n", strings.Join(sortedOps(e.Gained[target]), ", "), target)
+	}
+
+	return b.String()
+}
+
+func sortedNames(nodes map[string]graph.Node) []string {
+	names := make([]string, 0, len(nodes))
+	for name := range nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func sortedTargets(permissions map[string]graph.Operations) []string {
+	targets := make([]string, 0, len(permissions))
+	for target := range permissions {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+
+	return targets
+}
+
+func sortedOps(ops graph.Operations) []string {
+	sorted := make([]string, 0, len(ops))
+	for op := range ops {
+		sorted = append(sorted, op)
+	}
+	sort.Strings(sorted)
+
+	return sorted
+}
@@ -0,0 +1,167 @@
+package analysis
+
+import (
+	"fmt"
+	"github.com/PM-Master/policy-machine-go/author"
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
+	"github.com/PM-Master/policy-machine-go/pip/memory"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func newPolicy(t *testing.T, pal string) ngac.FunctionalEntity {
+	stmts, _, err := author.Parse(pal)
+	require.NoError(t, err)
+
+	pip := memory.NewPIP()
+	for _, stmt := range stmts {
+		require.NoError(t, stmt.Apply(pip))
+	}
+
+	return pip
+}
+
+func TestEscalations(t *testing.T) {
+	pip := newPolicy(t, `
+create policy pc1;
+create user attribute Users in pc1;
+create user attribute Admins in pc1;
+create user attribute HelpDesk in pc1;
+create user attribute Devs in pc1;
+create object attribute data in pc1;
+create object attribute secret in pc1;
+create user attribute Self in pc1;
+create user u1 in Users, HelpDesk, Self;
+create user u2 in Users;
+create user u3 in Devs;
+create user u4 in Admins;
+grant Admins read, write on secret;
+grant HelpDesk assign on Self;
+grant HelpDesk assign_to on Admins;
+grant Users submit on data;
+grant Devs create_obligation on data;
+`)
+	escalations, err := Escalations(pip)
+	require.NoError(t, err)
+	require.Len(t, escalations, 2)
+
+	require.Equal(t, "u1", escalations[0].User)
+	require.Equal(t, map[string]graph.Operations{
+		"secret": graph.ToOps("read", "write"),
+		// Self is now in Admins so HelpDesk's assign_to on Admins applies to it
+		"Self": graph.ToOps("assign_to"),
+		"u1":   graph.ToOps("assign_to"),
+	}, escalations[0].Gained)
+	require.Equal(t, []string{"u1 assigns Self to Admins"}, escalations[0].Steps)
+	require.False(t, escalations[0].Unbounded)
+
+	require.Equal(t, "u3", escalations[1].User)
+	require.True(t, escalations[1].Unbounded)
+	require.Contains(t, escalations[1].String(), "gains: any permission")
+
+	// the analysis does not assign Self to Admins in the policy
+	parents, err := pip.Graph().GetParents("Self")
+	require.NoError(t, err)
+	require.Len(t, parents, 1)
+	require.Contains(t, parents, "pc1")
+}
+
+func TestEscalationThroughObligation(t *testing.T) {
+	pip := newPolicy(t, `
+create policy pc1;
+create user attribute Users in pc1;
+create user attribute Approvers in pc1;
+create object attribute requests in pc1;
+create object attribute approvals in pc1;
+create user u1 in Users;
+grant Users submit on requests;
+grant Approvers approve on approvals;
+obligation promote when any_user performs submit on requests do (
+	assign $user to Approvers;
+);
+`)
+
+	escalation, err := UserEscalation(pip, "u1")
+	require.NoError(t, err)
+	require.Equal(t, []string{"u1 performs submit on requests triggering obligation promote"}, escalation.Steps)
+	require.Equal(t, map[string]graph.Operations{"approvals": graph.ToOps("approve")}, escalation.Gained)
+}
+
+func TestEscalationRespectsConstraints(t *testing.T) {
+	pip := newPolicy(t, `
+create policy pc1;
+create user attribute Requester in pc1;
+create user attribute Approver in pc1;
+create object attribute approvals in pc1;
+create user u1 in Requester;
+grant Requester assign on Requester;
+grant Requester assign_to on Approver;
+grant Approver approve on approvals;
+constraint ssod Requester, Approver;
+`)
+
+	escalation, err := UserEscalation(pip, "u1")
+	require.NoError(t, err)
+	require.Empty(t, escalation.Steps)
+	require.Empty(t, escalation.Gained)
+}
+
+func TestEscalationLargePolicy(t *testing.T) {
+	b := strings.Builder{}
+	b.WriteString(`
+create policy pc1;
+create user attribute Editors in pc1;
+create user attribute Admins in pc1;
+create object attribute docs in pc1;
+create object attribute secret in pc1;
+create user u1 in Editors;
+grant Editors assign, assign_to, read on docs;
+grant Editors assign_to on Admins;
+grant Editors assign on Editors;
+grant Admins read on secret;
+`)
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&b, "create object attribute folder%d in docs;\n", i)
+		fmt.Fprintf(&b, "create object doc%d in folder%d;\n", i, i)
+	}
+	pip := newPolicy(t, b.String())
+
+	// every document can be moved into every folder, none of which gives u1 new permissions
+	escalation, err := UserEscalation(pip, "u1")
+	require.NoError(t, err)
+	require.Equal(t, []string{"u1 assigns Editors to Admins"}, escalation.Steps)
+	// the assignments tried and undone do not show up as gained permissions
+	require.Equal(t, map[string]graph.Operations{
+		"secret": graph.ToOps("read"),
+		// Editors is now in Admins so its assign_to on Admins applies to it
+		"Editors": graph.ToOps("assign_to"),
+		"u1":      graph.ToOps("assign_to"),
+	}, escalation.Gained)
+}
+
+func TestEscalationKeepsUnconditionalAssociations(t *testing.T) {
+	// Admins already has unconditional associations with the targets u1 can associate it with, so undoing a trial
+	// that gains nothing restores an association without a condition
+	pip := newPolicy(t, `
+create policy pc1;
+create user attribute Admins in pc1;
+create object attribute data in pc1;
+create user u1 in Admins;
+grant Admins * on data;
+grant Admins associate on Admins;
+`)
+	escalations, err := Escalations(pip)
+	require.NoError(t, err)
+	for _, escalation := range escalations {
+		require.NotContains(t, escalation.Steps, "u1 associates Admins with data granting *")
+	}
+
+	assocs, err := pip.Graph().GetAssociationsForSubject("Admins")
+	require.NoError(t, err)
+	require.Equal(t, graph.ToOps(graph.AllOps), assocs["data"])
+	conditions, err := pip.Graph().GetAssociationConditions("Admins")
+	require.NoError(t, err)
+	require.Empty(t, conditions)
+}
@@ -0,0 +1,547 @@
+package analysis
+
+import (
+	"fmt"
+	"github.com/PM-Master/policy-machine-go/internal/sets"
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
+	"github.com/PM-Master/policy-machine-go/pdp"
+	"github.com/PM-Master/policy-machine-go/pip/memory"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The kinds of findings reported by Redundancies.
+const (
+	RedundantAssociation = "redundant_association"
+	InactiveProhibition  = "inactive_prohibition"
+	CancelledOperations  = "cancelled_operations"
+	NegatedAssociation   = "negated_association"
+	UnusedAttribute      = "unused_attribute"
+	UnreachableNode      = "unreachable_node"
+)
+
+type (
+	// Finding is a part of a policy that can be removed or simplified without changing any decision, or that does not
+	// have the effect its author likely intended.
+	Finding struct {
+		Kind string `json:"kind"`
+		// Nodes holds the association subject and target, the prohibition name or the node the finding is about.
+		Nodes      []string `json:"nodes"`
+		Operations []string `json:"operations,omitempty"`
+		Message    string   `json:"message"`
+	}
+
+	// redundancyAnalysis caches the ancestors and descendants of nodes while the policy is analyzed.
+	redundancyAnalysis struct {
+		fe          ngac.FunctionalEntity
+		nodes       map[string]graph.Node
+		assocs      map[string]map[string]graph.Operations
+		conditions  map[string]map[string]string
+		validity    map[string]map[string]graph.Validity
+		parents     map[string]map[string]bool
+		children    map[string]map[string]bool
+		ancestors   map[string]map[string]bool
+		descendants map[string]map[string]bool
+		// decider makes decisions without prohibitions so the effect of policy classes alone is analyzed.
+		decider pdp.Decider
+		// prohibited makes decisions with the prohibitions of the policy.
+		prohibited pdp.Decider
+		now        time.Time
+	}
+)
+
+// Redundancies reports:
+//   - associations whose operations are granted to the same users on the same nodes by associations of the subject
+//     or a containing user attribute with the target or a containing attribute in the same policy classes
+//   - prohibitions that have expired, whose subject does not exist, that apply to no node or that deny no operation
+//     their subject is granted
+//   - operations of associations that no user in the subject is granted on any node contained in the target because
+//     another policy class the node is in does not grant them
+//   - associations whose operations are denied by prohibitions to every user in the subject on every node contained
+//     in the target
+//   - user and object attributes that are empty and not referenced by an association, prohibition or constraint
+//   - nodes that are not contained in any policy class
+//
+// Conditional and time bound associations never make another association redundant. Findings are ordered by kind
+// as above, then by the nodes they are about.
+func Redundancies(fe ngac.FunctionalEntity) ([]Finding, error) {
+	a, err := newRedundancyAnalysis(fe)
+	if err != nil {
+		return nil, err
+	}
+
+	findings := make([]Finding, 0)
+	for _, find := range []func() ([]Finding, error){
+		a.redundantAssociations,
+		a.inactiveProhibitions,
+		a.cancelledOperations,
+		a.negatedAssociations,
+		a.unusedAttributes,
+		a.unreachableNodes,
+	} {
+		found, err := find()
+		if err != nil {
+			return nil, err
+		}
+
+		findings = append(findings, found...)
+	}
+
+	return findings, nil
+}
+
+func newRedundancyAnalysis(fe ngac.FunctionalEntity) (*redundancyAnalysis, error) {
+	g := fe.Graph()
+	nodes, err := g.GetNodes()
+	if err != nil {
+		return nil, err
+	}
+
+	assocs, err := g.GetAssociations()
+	if err != nil {
+		return nil, err
+	}
+
+	parents, err := g.GetAssignments()
+	if err != nil {
+		return nil, err
+	}
+
+	children := make(map[string]map[string]bool)
+	for child, childParents := range parents {
+		for parent := range childParents {
+			if _, ok := children[parent]; !ok {
+				children[parent] = make(map[string]bool)
+			}
+			children[parent][child] = true
+		}
+	}
+
+	a := &redundancyAnalysis{
+		fe:          fe,
+		nodes:       nodes,
+		assocs:      assocs,
+		conditions:  make(map[string]map[string]string),
+		validity:    make(map[string]map[string]graph.Validity),
+		parents:     parents,
+		children:    children,
+		ancestors:   make(map[string]map[string]bool),
+		descendants: make(map[string]map[string]bool),
+		decider:     pdp.NewDecider(g, memory.NewProhibitions()),
+		prohibited:  pdp.NewDecider(g, fe.Prohibitions()),
+		now:         time.Now(),
+	}
+
+	for subject := range assocs {
+		if a.conditions[subject], err = g.GetAssociationConditions(subject); err != nil {
+			return nil, err
+		}
+
+		if a.validity[subject], err = g.GetAssociationValidity(subject); err != nil {
+			return nil, err
+		}
+	}
+
+	return a, nil
+}
+
+func (a *redundancyAnalysis) redundantAssociations() ([]Finding, error) {
+	findings := make([]Finding, 0)
+	for _, subject := range sortedSubjects(a.assocs) {
+		for _, target := range sortedTargets(a.assocs[subject]) {
+			ops := a.assocs[subject][target]
+			covered := make(graph.Operations)
+			covering := make([]string, 0)
+			for _, coveringSubject := range a.selfAndAncestors(subject) {
+				for _, coveringTarget := range a.selfAndAncestors(target) {
+					if coveringSubject == subject && coveringTarget == target {
+						continue
+					}
+
+					coveringOps, ok := a.assocs[coveringSubject][coveringTarget]
+					if !ok || !a.unconditional(coveringSubject, coveringTarget) ||
+						!samePolicyClasses(a.policyClasses(target), a.policyClasses(coveringTarget)) {
+						continue
+					}
+
+					covered.AddAll(coveringOps)
+					covering = append(covering, fmt.Sprintf("%s on %s", coveringSubject, coveringTarget))
+				}
+			}
+
+			if len(covering) == 0 || !containsOps(covered, ops) {
+				continue
+			}
+
+			findings = append(findings, Finding{
+				Kind:       RedundantAssociation,
+				Nodes:      []string{subject, target},
+				Operations: sortedOps(ops),
+				Message: fmt.Sprintf("association of %s with %s is redundant with the associations of %s",
+					subject, target, strings.Join(covering, ", ")),
+			})
+		}
+	}
+
+	return findings, nil
+}
+
+func (a *redundancyAnalysis) inactiveProhibitions() ([]Finding, error) {
+	prohibitions, err := a.fe.Prohibitions().All()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(prohibitions, func(i, j int) bool {
+		return prohibitions[i].Name < prohibitions[j].Name
+	})
+
+	findings := make([]Finding, 0)
+	for _, prohibition := range prohibitions {
+		reason, err := a.inactiveReason(prohibition)
+		if err != nil {
+			return nil, err
+		}
+
+		if reason == "" {
+			continue
+		}
+
+		findings = append(findings, Finding{
+			Kind:       InactiveProhibition,
+			Nodes:      []string{prohibition.Name},
+			Operations: sortedOps(prohibition.Operations),
+			Message:    fmt.Sprintf("prohibition %s never applies: %s", prohibition.Name, reason),
+		})
+	}
+
+	return findings, nil
+}
+
+// inactiveReason returns why the prohibition never denies an operation, or an empty string if it can.
+func (a *redundancyAnalysis) inactiveReason(prohibition ngac.Prohibition) (string, error) {
+	if prohibition.Expired(a.now) {
+		return "it has expired", nil
+	}
+
+	if _, ok := a.nodes[prohibition.Subject]; !ok && !prohibition.Process {
+		return fmt.Sprintf("subject %s does not exist", prohibition.Subject), nil
+	}
+
+	targets := make([]string, 0)
+	for _, name := range sortedNames(a.nodes) {
+		if pdp.ProhibitionApplies(prohibition, name, a.ancestorSet(name)) {
+			targets = append(targets, name)
+		}
+	}
+
+	if len(targets) == 0 {
+		return "it applies to no node", nil
+	}
+
+	// the users of a process are not known
+	if prohibition.Process {
+		return "", nil
+	}
+
+	granted, err := a.grantsAny(a.decider, a.users(prohibition.Subject), targets, prohibition.Operations)
+	if err != nil || granted {
+		return "", err
+	}
+
+	return "it denies no operation granted to its subject", nil
+}
+
+func (a *redundancyAnalysis) cancelledOperations() ([]Finding, error) {
+	findings := make([]Finding, 0)
+	for _, subject := range sortedSubjects(a.assocs) {
+		users := a.users(subject)
+		if len(users) == 0 {
+			continue
+		}
+
+		for _, target := range sortedTargets(a.assocs[subject]) {
+			if !a.unconditional(subject, target) {
+				continue
+			}
+
+			targets := a.selfAndDescendants(target)[1:]
+			if len(targets) == 0 {
+				continue
+			}
+
+			remaining := make(graph.Operations)
+			for op := range a.assocs[subject][target] {
+				if op != graph.AllOps {
+					remaining.Add(op)
+				}
+			}
+
+			for _, user := range users {
+				permissions, err := a.decider.ListPermissionsBatch(user, targets)
+				if err != nil {
+					return nil, err
+				}
+
+				for _, ops := range permissions {
+					for op := range remaining {
+						if ops.Contains(op) {
+							remaining.Remove(op)
+						}
+					}
+				}
+			}
+
+			if len(remaining) == 0 {
+				continue
+			}
+
+			findings = append(findings, Finding{
+				Kind:       CancelledOperations,
+				Nodes:      []string{subject, target},
+				Operations: sortedOps(remaining),
+				Message: fmt.Sprintf("%s granted to %s on %s are not granted on any node in %s by every policy class",
+					strings.Join(sortedOps(remaining), ", "), subject, target, target),
+			})
+		}
+	}
+
+	return findings, nil
+}
+
+func (a *redundancyAnalysis) negatedAssociations() ([]Finding, error) {
+	findings := make([]Finding, 0)
+	for _, subject := range sortedSubjects(a.assocs) {
+		users := a.users(subject)
+		if len(users) == 0 {
+			continue
+		}
+
+		for _, target := range sortedTargets(a.assocs[subject]) {
+			if !a.unconditional(subject, target) {
+				continue
+			}
+
+			// a prohibition does not apply to its containers, only to the nodes in them
+			targets := a.selfAndDescendants(target)[1:]
+			if len(targets) == 0 {
+				continue
+			}
+
+			ops := a.assocs[subject][target]
+			granted, err := a.grantsAny(a.decider, users, targets, ops)
+			if err != nil {
+				return nil, err
+			}
+
+			// operations that are not granted without prohibitions are reported as cancelled
+			if !granted {
+				continue
+			}
+
+			if granted, err = a.grantsAny(a.prohibited, users, targets, ops); err != nil {
+				return nil, err
+			} else if granted {
+				continue
+			}
+
+			findings = append(findings, Finding{
+				Kind:       NegatedAssociation,
+				Nodes:      []string{subject, target},
+				Operations: sortedOps(ops),
+				Message: fmt.Sprintf("association of %s with %s grants nothing because prohibitions deny %s to every user in %s",
+					subject, target, strings.Join(sortedOps(ops), ", "), subject),
+			})
+		}
+	}
+
+	return findings, nil
+}
+
+// grantsAny returns true if the decider grants any of the operations to any of the users on any of the targets.
+func (a *redundancyAnalysis) grantsAny(decider pdp.Decider, users []string, targets []string, ops graph.Operations) (bool, error) {
+	for _, user := range users {
+		permissions, err := decider.ListPermissionsBatch(user, targets)
+		if err != nil {
+			return false, err
+		}
+
+		for _, granted := range permissions {
+			for op := range ops {
+				if granted.Contains(op) || (op == graph.AllOps && len(granted) > 0) {
+					return true, nil
+				}
+			}
+		}
+	}
+
+	return false, nil
+}
+
+func (a *redundancyAnalysis) unusedAttributes() ([]Finding, error) {
+	referenced := make(map[string]bool)
+	for subject, targets := range a.assocs {
+		referenced[subject] = true
+		for target := range targets {
+			referenced[target] = true
+		}
+	}
+
+	prohibitions, err := a.fe.Prohibitions().All()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, prohibition := range prohibitions {
+		referenced[prohibition.Subject] = true
+		for container := range prohibition.Containers {
+			referenced[container] = true
+		}
+	}
+
+	ssod, err := a.fe.Constraints().GetSSoD()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, constraint := range ssod {
+		for _, attr := range constraint.Attributes {
+			referenced[attr] = true
+		}
+	}
+
+	findings := make([]Finding, 0)
+	for _, name := range sortedNames(a.nodes) {
+		kind := a.nodes[name].Kind
+		if (kind != graph.UserAttribute && kind != graph.ObjectAttribute) || referenced[name] {
+			continue
+		}
+
+		if len(a.children[name]) > 0 {
+			continue
+		}
+
+		findings = append(findings, Finding{
+			Kind:    UnusedAttribute,
+			Nodes:   []string{name},
+			Message: fmt.Sprintf("%s %s is empty and not referenced by the policy", kind, name),
+		})
+	}
+
+	return findings, nil
+}
+
+func (a *redundancyAnalysis) unreachableNodes() ([]Finding, error) {
+	findings := make([]Finding, 0)
+	for _, name := range sortedNames(a.nodes) {
+		kind := a.nodes[name].Kind
+		if kind == graph.PolicyClass || len(a.policyClasses(name)) > 0 {
+			continue
+		}
+
+		findings = append(findings, Finding{
+			Kind:    UnreachableNode,
+			Nodes:   []string{name},
+			Message: fmt.Sprintf("%s %s is not contained in any policy class", kind, name),
+		})
+	}
+
+	return findings, nil
+}
+
+// unconditional returns true if the association has no condition and no validity.
+func (a *redundancyAnalysis) unconditional(subject string, target string) bool {
+	_, conditional := a.conditions[subject][target]
+	return !conditional && a.validity[subject][target].IsZero()
+}
+
+func (a *redundancyAnalysis) ancestorSet(name string) map[string]bool {
+	return closure(name, a.parents, a.ancestors)
+}
+
+func (a *redundancyAnalysis) selfAndAncestors(name string) []string {
+	return append([]string{name}, sets.Sorted(a.ancestorSet(name))...)
+}
+
+func (a *redundancyAnalysis) selfAndDescendants(name string) []string {
+	return append([]string{name}, sets.Sorted(closure(name, a.children, a.descendants))...)
+}
+
+// closure returns the nodes reachable from the node through the edges, excluding the node itself.
+func closure(name string, edges map[string]map[string]bool, cache map[string]map[string]bool) map[string]bool {
+	if reachable, ok := cache[name]; ok {
+		return reachable
+	}
+
+	reachable, _ := sets.Reachable(name, func(node string) (map[string]bool, error) {
+		return edges[node], nil
+	})
+
+	cache[name] = reachable
+	return reachable
+}
+
+func (a *redundancyAnalysis) policyClasses(name string) map[string]bool {
+	pcs := make(map[string]bool)
+	for ancestor := range a.ancestorSet(name) {
+		if a.nodes[ancestor].Kind == graph.PolicyClass {
+			pcs[ancestor] = true
+		}
+	}
+
+	return pcs
+}
+
+// users returns the users contained in the node, or the node itself if it is a user.
+func (a *redundancyAnalysis) users(name string) []string {
+	users := make([]string, 0)
+	for _, node := range a.selfAndDescendants(name) {
+		if n, ok := a.nodes[node]; ok && n.Kind == graph.User {
+			users = append(users, node)
+		}
+	}
+
+	return users
+}
+
+func samePolicyClasses(a map[string]bool, b map[string]bool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for pc := range a {
+		if !b[pc] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// containsOps returns true if every operation in ops is granted by granted.
+func containsOps(granted graph.Operations, ops graph.Operations) bool {
+	for op := range ops {
+		if !granted.Contains(op) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// String formats the finding as its kind followed by its message.
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: %s", f.Kind, f.Message)
+}
+
+func sortedSubjects(assocs map[string]map[string]graph.Operations) []string {
+	subjects := make([]string, 0, len(assocs))
+	for subject := range assocs {
+		subjects = append(subjects, subject)
+	}
+	sort.Strings(subjects)
+
+	return subjects
+}
@@ -0,0 +1,151 @@
+package analysis
+
+import (
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestRedundancies(t *testing.T) {
+	pip := newPolicy(t, `
+create policy pc1;
+create policy pc2;
+create object attribute oa0 in pc1;
+create object attribute oa1 in oa0;
+create object attribute oa3 in pc2;
+create object attribute oa2 in pc1;
+create object attribute oa4 in pc1;
+create object attribute oa5 in pc1;
+create object attribute empty in pc1;
+create user attribute ua0 in pc1;
+create user attribute ua1 in ua0;
+create user attribute ua2 in pc1;
+create user u1 in ua1;
+create object o1 in oa1;
+create object o2 in oa2, oa3;
+create object o3 in oa5;
+grant ua1 read on oa1;
+grant ua1 read, write on oa0;
+grant ua1 read, delete on oa2;
+grant ua1 read on oa3;
+`)
+	g := pip.Graph()
+	require.NoError(t, g.Deassign("oa5", "pc1"))
+
+	past := time.Now().Add(-time.Hour)
+	for _, prohibition := range []ngac.Prohibition{
+		{Name: "denies-ungranted", Subject: "ua1", Containers: map[string]bool{"oa3": false}, Operations: graph.ToOps("write")},
+		{Name: "expired", Subject: "ua1", Containers: map[string]bool{"oa0": false}, Operations: graph.ToOps("read"),
+			Validity: graph.Validity{NotAfter: &past}},
+		{Name: "no-targets", Subject: "ua1", Containers: map[string]bool{"empty": false}, Operations: graph.ToOps("read")},
+		{Name: "used", Subject: "u1", Containers: map[string]bool{"oa0": false}, Operations: graph.ToOps("write")},
+	} {
+		require.NoError(t, pip.Prohibitions().Add(prohibition))
+	}
+
+	findings, err := Redundancies(pip)
+	require.NoError(t, err)
+	require.Equal(t, []Finding{
+		{
+			Kind:       RedundantAssociation,
+			Nodes:      []string{"ua1", "oa1"},
+			Operations: []string{"read"},
+			Message:    "association of ua1 with oa1 is redundant with the associations of ua1 on oa0",
+		},
+		{
+			Kind:       InactiveProhibition,
+			Nodes:      []string{"denies-ungranted"},
+			Operations: []string{"write"},
+			Message:    "prohibition denies-ungranted never applies: it denies no operation granted to its subject",
+		},
+		{
+			Kind:       InactiveProhibition,
+			Nodes:      []string{"expired"},
+			Operations: []string{"read"},
+			Message:    "prohibition expired never applies: it has expired",
+		},
+		{
+			Kind:       InactiveProhibition,
+			Nodes:      []string{"no-targets"},
+			Operations: []string{"read"},
+			Message:    "prohibition no-targets never applies: it applies to no node",
+		},
+		{
+			Kind:       CancelledOperations,
+			Nodes:      []string{"ua1", "oa2"},
+			Operations: []string{"delete"},
+			Message:    "delete granted to ua1 on oa2 are not granted on any node in oa2 by every policy class",
+		},
+		{
+			Kind:    UnusedAttribute,
+			Nodes:   []string{"oa4"},
+			Message: "OA oa4 is empty and not referenced by the policy",
+		},
+		{
+			Kind:    UnusedAttribute,
+			Nodes:   []string{"ua2"},
+			Message: "UA ua2 is empty and not referenced by the policy",
+		},
+		{
+			Kind:    UnreachableNode,
+			Nodes:   []string{"o3"},
+			Message: "O o3 is not contained in any policy class",
+		},
+		{
+			Kind:    UnreachableNode,
+			Nodes:   []string{"oa5"},
+			Message: "OA oa5 is not contained in any policy class",
+		},
+	}, findings)
+}
+
+func TestRedundanciesAcrossPolicyClasses(t *testing.T) {
+	// the association with oa1 grants read in pc2, which the association with oa0 does not
+	pip := newPolicy(t, `
+create policy pc1;
+create policy pc2;
+create object attribute oa0 in pc1;
+create object attribute oa2 in pc2;
+create object attribute oa1 in oa0, oa2;
+create user attribute ua1 in pc1;
+create user u1 in ua1;
+create object o1 in oa1;
+grant ua1 read on oa1;
+grant ua1 read on oa0;
+`)
+
+	findings, err := Redundancies(pip)
+	require.NoError(t, err)
+	require.Empty(t, findings)
+}
+
+func TestRedundanciesNegatedAssociation(t *testing.T) {
+	// every user in ua1 is denied the operations granted on oa1, but u2 can still read oa2
+	pip := newPolicy(t, `
+create policy pc1;
+create object attribute oa1 in pc1;
+create object attribute oa2 in pc1;
+create user attribute ua1 in pc1;
+create user u1 in ua1;
+create user u2 in ua1;
+create object o1 in oa1;
+create object o2 in oa2;
+grant ua1 read, write on oa1;
+grant ua1 read on oa2;
+deny d1 for ua1 read, write on oa1;
+deny d2 for u1 read on oa2;
+`)
+
+	findings, err := Redundancies(pip)
+	require.NoError(t, err)
+	require.Equal(t, []Finding{
+		{
+			Kind:       NegatedAssociation,
+			Nodes:      []string{"ua1", "oa1"},
+			Operations: []string{"read", "write"},
+			Message:    "association of ua1 with oa1 grants nothing because prohibitions deny read, write to every user in ua1",
+		},
+	}, findings)
+}
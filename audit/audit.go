@@ -0,0 +1,332 @@
+// Package audit records the administrative changes made to a policy and who made them.
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
+	"sort"
+	"time"
+)
+
+type (
+	// Auditor records the changes made to a functional entity through the functional entities returned by As.
+	Auditor struct {
+		fe   ngac.FunctionalEntity
+		sink Sink
+		now  func() time.Time
+	}
+
+	// Entry records an administrative operation. Statement is the JSON of the statement that is equivalent to the
+	// operation and Nodes are the nodes the operation refers to.
+	Entry struct {
+		Actor     string          `json:"actor"`
+		Time      time.Time       `json:"time"`
+		Operation string          `json:"operation"`
+		Nodes     []string        `json:"nodes,omitempty"`
+		Statement json.RawMessage `json:"statement,omitempty"`
+		Result    Result          `json:"result"`
+		Error     string          `json:"error,omitempty"`
+	}
+
+	Result string
+
+	// actor is a functional entity that records the changes made through it for an actor.
+	actor struct {
+		graph        ngac.Graph
+		prohibitions ngac.Prohibitions
+		obligations  ngac.Obligations
+		constraints  ngac.Constraints
+	}
+
+	recorder struct {
+		auditor *Auditor
+		actor   string
+	}
+
+	auditedGraph struct {
+		ngac.Graph
+		recorder
+	}
+
+	auditedProhibitions struct {
+		ngac.Prohibitions
+		recorder
+	}
+
+	auditedObligations struct {
+		ngac.Obligations
+		recorder
+	}
+
+	auditedConstraints struct {
+		ngac.Constraints
+		recorder
+	}
+)
+
+const (
+	Succeeded Result = "succeeded"
+	Failed    Result = "failed"
+)
+
+// New returns an auditor that writes the entries for the changes made to the functional entity to the sink.
+func New(fe ngac.FunctionalEntity, sink Sink) *Auditor {
+	return &Auditor{fe: fe, sink: sink, now: time.Now}
+}
+
+// As returns a functional entity that changes the audited functional entity on behalf of the actor. Every change is
+// recorded, whether or not it succeeds. An error is returned if an entry cannot be written, even if the change was
+// made.
+func (a *Auditor) As(name string) ngac.FunctionalEntity {
+	r := recorder{auditor: a, actor: name}
+	return actor{
+		graph:        auditedGraph{Graph: a.fe.Graph(), recorder: r},
+		prohibitions: auditedProhibitions{Prohibitions: a.fe.Prohibitions(), recorder: r},
+		obligations:  auditedObligations{Obligations: a.fe.Obligations(), recorder: r},
+		constraints:  auditedConstraints{Constraints: a.fe.Constraints(), recorder: r},
+	}
+}
+
+// Query returns the entries that match the filter.
+func (a *Auditor) Query(filter Filter) ([]Entry, error) {
+	return a.sink.Query(filter)
+}
+
+func (a actor) Graph() ngac.Graph {
+	return a.graph
+}
+
+func (a actor) Prohibitions() ngac.Prohibitions {
+	return a.prohibitions
+}
+
+func (a actor) Obligations() ngac.Obligations {
+	return a.obligations
+}
+
+func (a actor) Constraints() ngac.Constraints {
+	return a.constraints
+}
+
+// record makes the change and writes an entry for it. The statement can be nil for operations that have no equivalent
+// statement.
+func (r recorder) record(operation string, nodes []string, stmt ngac.Statement, change func() error) error {
+	err := change()
+
+	entry := Entry{
+		Actor:     r.actor,
+		Time:      r.auditor.now(),
+		Operation: operation,
+		Nodes:     nodes,
+		Result:    Succeeded,
+	}
+
+	if err != nil {
+		entry.Result = Failed
+		entry.Error = err.Error()
+	}
+
+	if stmt != nil {
+		bytes, jsonErr := stmt.MarshalJSON()
+		if jsonErr != nil {
+			return fmt.Errorf("error writing audit entry: %w", jsonErr)
+		}
+
+		entry.Statement = bytes
+	}
+
+	if writeErr := r.auditor.sink.Write(entry); writeErr != nil && err == nil {
+		return fmt.Errorf("error writing audit entry: %w", writeErr)
+	}
+
+	return err
+}
+
+func (g auditedGraph) CreatePolicyClass(name string) error {
+	return g.record("CreatePolicyClass", []string{name}, &ngac.CreatePolicyStatement{Name: name}, func() error {
+		return g.Graph.CreatePolicyClass(name)
+	})
+}
+
+func (g auditedGraph) CreateNode(name string, kind graph.Kind, properties map[string]string, parent string, parents ...string) (graph.Node, error) {
+	allParents := append([]string{parent}, parents...)
+	stmt := &ngac.CreateNodeStatement{Name: name, Kind: kind, Properties: properties, Parents: allParents}
+
+	var node graph.Node
+	err := g.record("CreateNode", append([]string{name}, allParents...), stmt, func() error {
+		var err error
+		node, err = g.Graph.CreateNode(name, kind, properties, parent, parents...)
+		return err
+	})
+
+	return node, err
+}
+
+func (g auditedGraph) UpdateNode(name string, properties map[string]string) error {
+	stmt := &ngac.UpdateNodeStatement{Name: name, Properties: properties}
+	return g.record("UpdateNode", []string{name}, stmt, func() error {
+		return g.Graph.UpdateNode(name, properties)
+	})
+}
+
+func (g auditedGraph) DeleteNode(name string) error {
+	return g.record("DeleteNode", []string{name}, &ngac.DeleteNodeStatement{Name: name}, func() error {
+		return g.Graph.DeleteNode(name)
+	})
+}
+
+func (g auditedGraph) Assign(child string, parent string) error {
+	stmt := &ngac.AssignStatement{Child: child, Parents: []string{parent}}
+	return g.record("Assign", []string{child, parent}, stmt, func() error {
+		return g.Graph.Assign(child, parent)
+	})
+}
+
+func (g auditedGraph) Deassign(child string, parent string) error {
+	stmt := &ngac.DeassignStatement{Child: child, Parents: []string{parent}}
+	return g.record("Deassign", []string{child, parent}, stmt, func() error {
+		return g.Graph.Deassign(child, parent)
+	})
+}
+
+func (g auditedGraph) Associate(subject string, target string, operations graph.Operations) error {
+	stmt := &ngac.GrantStatement{Uattr: subject, Target: target, Operations: operations}
+	return g.record("Associate", []string{subject, target}, stmt, func() error {
+		return g.Graph.Associate(subject, target, operations)
+	})
+}
+
+func (g auditedGraph) AssociateWithCondition(subject string, target string, operations graph.Operations, condition string) error {
+	stmt := &ngac.GrantStatement{Uattr: subject, Target: target, Operations: operations, Condition: condition}
+	return g.record("AssociateWithCondition", []string{subject, target}, stmt, func() error {
+		return g.Graph.AssociateWithCondition(subject, target, operations, condition)
+	})
+}
+
+func (g auditedGraph) SetAssociationValidity(subject string, target string, validity graph.Validity) error {
+	// grant the association's current operations and condition so applying the statement only changes the validity.
+	// If they cannot be read the change fails and is recorded as failed.
+	stmt := &ngac.GrantStatement{Uattr: subject, Target: target, Validity: validity}
+	if assocs, err := g.Graph.GetAssociationsForSubject(subject); err == nil {
+		stmt.Operations = assocs[target]
+	}
+	if conditions, err := g.Graph.GetAssociationConditions(subject); err == nil {
+		stmt.Condition = conditions[target]
+	}
+
+	return g.record("SetAssociationValidity", []string{subject, target}, stmt, func() error {
+		return g.Graph.SetAssociationValidity(subject, target, validity)
+	})
+}
+
+func (g auditedGraph) Dissociate(subject string, target string) error {
+	stmt := &ngac.DissociateStatement{Uattr: subject, Target: target}
+	return g.record("Dissociate", []string{subject, target}, stmt, func() error {
+		return g.Graph.Dissociate(subject, target)
+	})
+}
+
+func (g auditedGraph) UnmarshalJSON(bytes []byte) error {
+	return g.record("UnmarshalGraph", nil, nil, func() error {
+		return g.Graph.UnmarshalJSON(bytes)
+	})
+}
+
+func (p auditedProhibitions) Add(prohibition ngac.Prohibition) error {
+	return p.record("AddProhibition", prohibitionNodes(prohibition), ngac.DenyStatementFor(prohibition), func() error {
+		return p.Prohibitions.Add(prohibition)
+	})
+}
+
+func (p auditedProhibitions) Update(prohibition ngac.Prohibition) error {
+	return p.record("UpdateProhibition", prohibitionNodes(prohibition), ngac.DenyStatementFor(prohibition), func() error {
+		return p.Prohibitions.Update(prohibition)
+	})
+}
+
+func (p auditedProhibitions) Delete(subject string, prohibitionName string) error {
+	stmt := &ngac.DeleteProhibitionStatement{Name: prohibitionName}
+	return p.record("DeleteProhibition", []string{subject}, stmt, func() error {
+		return p.Prohibitions.Delete(subject, prohibitionName)
+	})
+}
+
+func (p auditedProhibitions) UnmarshalJSON(bytes []byte) error {
+	return p.record("UnmarshalProhibitions", nil, nil, func() error {
+		return p.Prohibitions.UnmarshalJSON(bytes)
+	})
+}
+
+func (o auditedObligations) Add(obligation ngac.Obligation) error {
+	return o.record("AddObligation", nil, &ngac.ObligationStatement{Obligation: obligation}, func() error {
+		return o.Obligations.Add(obligation)
+	})
+}
+
+func (o auditedObligations) Remove(label string) error {
+	return o.record("RemoveObligation", nil, &ngac.DeleteObligationStatement{Label: label}, func() error {
+		return o.Obligations.Remove(label)
+	})
+}
+
+func (o auditedObligations) Enable(label string) error {
+	return o.record("EnableObligation", nil, &ngac.EnableObligationStatement{Label: label}, func() error {
+		return o.Obligations.Enable(label)
+	})
+}
+
+func (o auditedObligations) Disable(label string) error {
+	return o.record("DisableObligation", nil, &ngac.DisableObligationStatement{Label: label}, func() error {
+		return o.Obligations.Disable(label)
+	})
+}
+
+func (o auditedObligations) UnmarshalJSON(bytes []byte) error {
+	return o.record("UnmarshalObligations", nil, nil, func() error {
+		return o.Obligations.UnmarshalJSON(bytes)
+	})
+}
+
+func (c auditedConstraints) AddSSoD(constraint ngac.SSoD) error {
+	stmt := &ngac.SSoDStatement{Name: constraint.Name, Attributes: constraint.Attributes, Max: constraint.Max}
+	return c.record("AddSSoD", constraint.Attributes, stmt, func() error {
+		return c.Constraints.AddSSoD(constraint)
+	})
+}
+
+func (c auditedConstraints) RemoveSSoD(name string) error {
+	return c.record("RemoveSSoD", nil, &ngac.DeleteConstraintStatement{Name: name}, func() error {
+		return c.Constraints.RemoveSSoD(name)
+	})
+}
+
+func (c auditedConstraints) AddDSoD(constraint ngac.DSoD) error {
+	stmt := &ngac.DSoDStatement{Name: constraint.Name, Operations: constraint.Operations}
+	return c.record("AddDSoD", nil, stmt, func() error {
+		return c.Constraints.AddDSoD(constraint)
+	})
+}
+
+func (c auditedConstraints) RemoveDSoD(name string) error {
+	return c.record("RemoveDSoD", nil, &ngac.DeleteConstraintStatement{Name: name, Dynamic: true}, func() error {
+		return c.Constraints.RemoveDSoD(name)
+	})
+}
+
+func (c auditedConstraints) UnmarshalJSON(bytes []byte) error {
+	return c.record("UnmarshalConstraints", nil, nil, func() error {
+		return c.Constraints.UnmarshalJSON(bytes)
+	})
+}
+
+func prohibitionNodes(prohibition ngac.Prohibition) []string {
+	nodes := []string{prohibition.Subject}
+	for container := range prohibition.Containers {
+		nodes = append(nodes, container)
+	}
+	sort.Strings(nodes[1:])
+
+	return nodes
+}
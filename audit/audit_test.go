@@ -0,0 +1,160 @@
+package audit
+
+import (
+	"encoding/json"
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
+	"github.com/PM-Master/policy-machine-go/pip/memory"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAudit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	sink, err := NewFileSink(filepath.Join(dir, "audit.log"))
+	require.NoError(t, err)
+	defer sink.Close()
+
+	start := time.Date(2021, 1, 5, 0, 0, 0, 0, time.UTC)
+	clock := start
+	auditor := New(memory.NewPIP(), sink)
+	auditor.now = func() time.Time {
+		clock = clock.Add(time.Minute)
+		return clock
+	}
+
+	admin := auditor.As("admin")
+	require.NoError(t, admin.Graph().CreatePolicyClass("pc1"))
+	_, err = admin.Graph().CreateNode("ua1", graph.UserAttribute, nil, "pc1")
+	require.NoError(t, err)
+	_, err = admin.Graph().CreateNode("oa1", graph.ObjectAttribute, nil, "pc1")
+	require.NoError(t, err)
+
+	bob := auditor.As("bob")
+	require.NoError(t, bob.Graph().Associate("ua1", "oa1", graph.ToOps("read")))
+	require.Error(t, bob.Graph().DeleteNode("pc1"))
+	require.NoError(t, bob.Prohibitions().Add(ngac.Prohibition{
+		Name:       "deny1",
+		Subject:    "ua1",
+		Containers: map[string]bool{"oa1": false},
+		Operations: graph.ToOps("write"),
+	}))
+
+	entries, err := auditor.Query(Filter{})
+	require.NoError(t, err)
+	require.Len(t, entries, 6)
+
+	entries, err = auditor.Query(Filter{Actor: "bob"})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	require.Equal(t, "Associate", entries[0].Operation)
+	require.Equal(t, Succeeded, entries[0].Result)
+	require.Equal(t, start.Add(4*time.Minute), entries[0].Time)
+
+	stmt := &ngac.GrantStatement{}
+	require.NoError(t, json.Unmarshal(entries[0].Statement, stmt))
+	require.Equal(t, &ngac.GrantStatement{Uattr: "ua1", Target: "oa1", Operations: graph.ToOps("read")}, stmt)
+
+	require.Equal(t, "DeleteNode", entries[1].Operation)
+	require.Equal(t, Failed, entries[1].Result)
+	require.NotEmpty(t, entries[1].Error)
+
+	entries, err = auditor.Query(Filter{Node: "oa1"})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	require.Equal(t, []string{"CreateNode", "Associate", "AddProhibition"},
+		[]string{entries[0].Operation, entries[1].Operation, entries[2].Operation})
+
+	entries, err = auditor.Query(Filter{From: start.Add(2 * time.Minute), To: start.Add(4 * time.Minute)})
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, "ua1", entries[0].Nodes[0])
+	require.Equal(t, "oa1", entries[1].Nodes[0])
+}
+
+// replay applies the statements of the succeeded entries to the functional entity.
+func replay(t *testing.T, entries []Entry, fe ngac.FunctionalEntity) {
+	statements := map[string]func() ngac.Statement{
+		"CreatePolicyClass":      func() ngac.Statement { return &ngac.CreatePolicyStatement{} },
+		"CreateNode":             func() ngac.Statement { return &ngac.CreateNodeStatement{} },
+		"Associate":              func() ngac.Statement { return &ngac.GrantStatement{} },
+		"AssociateWithCondition": func() ngac.Statement { return &ngac.GrantStatement{} },
+		"SetAssociationValidity": func() ngac.Statement { return &ngac.GrantStatement{} },
+		"AddSSoD":                func() ngac.Statement { return &ngac.SSoDStatement{} },
+		"AddDSoD":                func() ngac.Statement { return &ngac.DSoDStatement{} },
+		"RemoveSSoD":             func() ngac.Statement { return &ngac.DeleteConstraintStatement{} },
+		"RemoveDSoD":             func() ngac.Statement { return &ngac.DeleteConstraintStatement{} },
+	}
+
+	for _, entry := range entries {
+		if entry.Result != Succeeded {
+			continue
+		}
+
+		newStatement, ok := statements[entry.Operation]
+		require.True(t, ok, entry.Operation)
+		stmt := newStatement()
+		require.NoError(t, json.Unmarshal(entry.Statement, stmt))
+		require.NoError(t, stmt.Apply(fe), entry.Operation)
+	}
+}
+
+func TestAuditReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	sink, err := NewFileSink(filepath.Join(dir, "audit.log"))
+	require.NoError(t, err)
+	defer sink.Close()
+
+	pip := memory.NewPIP()
+	admin := New(pip, sink).As("admin")
+	g := admin.Graph()
+	require.NoError(t, g.CreatePolicyClass("pc1"))
+	for _, ua := range []string{"ua1", "ua2", "ua3"} {
+		_, err = g.CreateNode(ua, graph.UserAttribute, nil, "pc1")
+		require.NoError(t, err)
+	}
+	_, err = g.CreateNode("oa1", graph.ObjectAttribute, nil, "pc1")
+	require.NoError(t, err)
+
+	until := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, g.AssociateWithCondition("ua1", "oa1", graph.ToOps("read", "write"), `env.network == "internal"`))
+	require.NoError(t, g.SetAssociationValidity("ua1", "oa1", graph.Validity{NotAfter: &until}))
+
+	c := admin.Constraints()
+	require.NoError(t, c.AddSSoD(ngac.SSoD{Name: "s1", Attributes: []string{"ua1", "ua2"}, Max: 1}))
+	require.NoError(t, c.AddSSoD(ngac.SSoD{Name: "s2", Attributes: []string{"ua2", "ua3"}, Max: 1}))
+	require.NoError(t, c.AddDSoD(ngac.DSoD{Name: "d1", Operations: []string{"create", "approve"}}))
+	require.NoError(t, c.RemoveSSoD("s1"))
+	require.NoError(t, c.RemoveDSoD("d1"))
+
+	entries, err := sink.Query(Filter{})
+	require.NoError(t, err)
+
+	replayed := memory.NewPIP()
+	replay(t, entries, replayed)
+
+	expected, err := memory.Marshal(pip)
+	require.NoError(t, err)
+	actual, err := memory.Marshal(replayed)
+	require.NoError(t, err)
+	require.JSONEq(t, string(expected), string(actual))
+
+	ssod, err := replayed.Constraints().GetSSoD()
+	require.NoError(t, err)
+	require.Len(t, ssod, 1)
+	require.Equal(t, "s2", ssod[0].Name)
+
+	dsod, err := replayed.Constraints().GetDSoD()
+	require.NoError(t, err)
+	require.Empty(t, dsod)
+}
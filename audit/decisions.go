@@ -0,0 +1,267 @@
+package audit
+
+import (
+	"fmt"
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
+	"github.com/PM-Master/policy-machine-go/pdp"
+	"math/rand"
+	"sort"
+	"time"
+)
+
+type (
+	// Decision records an access decision. Requested is empty if the permissions of the user were listed and Granted
+	// is empty if the decider was only asked whether the requested permissions are granted.
+	Decision struct {
+		Time        time.Time         `json:"time"`
+		User        string            `json:"user"`
+		Process     string            `json:"process,omitempty"`
+		Target      string            `json:"target"`
+		Requested   []string          `json:"requested,omitempty"`
+		Granted     []string          `json:"granted,omitempty"`
+		Allowed     bool              `json:"allowed"`
+		Environment map[string]string `json:"environment,omitempty"`
+		Latency     time.Duration     `json:"latency"`
+		Revision    uint64            `json:"revision,omitempty"`
+		Error       string            `json:"error,omitempty"`
+	}
+
+	// DecisionSink stores access decisions.
+	DecisionSink interface {
+		WriteDecision(decision Decision) error
+	}
+
+	// DecisionOption configures a decision logger.
+	DecisionOption func(l *decisionLogger)
+
+	decisionLogger struct {
+		decider     pdp.Decider
+		sink        DecisionSink
+		environment map[string]string
+		sampleRate  float64
+		redact      func(decision *Decision)
+		revision    func() uint64
+		now         func() time.Time
+		random      func() float64
+	}
+)
+
+// NewDecisionLogger returns a decider that writes the decisions of the given decider to the sink. A decision that
+// cannot be written is treated as an error, so the decider fails closed. HasPermissions is decided by listing the
+// permissions of the user so the granted operations can be recorded.
+func NewDecisionLogger(decider pdp.Decider, sink DecisionSink, options ...DecisionOption) pdp.Decider {
+	l := decisionLogger{
+		decider:    decider,
+		sink:       sink,
+		sampleRate: 1,
+		now:        time.Now,
+		random:     rand.Float64,
+	}
+
+	for _, option := range options {
+		option(&l)
+	}
+
+	return l
+}
+
+// WithSampling only writes the given fraction of the decisions that allow access. Decisions that deny access or fail
+// are always written.
+func WithSampling(rate float64) DecisionOption {
+	return func(l *decisionLogger) {
+		l.sampleRate = rate
+	}
+}
+
+// WithRedaction calls redact with every decision before it is written, for example to remove or hash user names and
+// environment attributes.
+func WithRedaction(redact func(decision *Decision)) DecisionOption {
+	return func(l *decisionLogger) {
+		l.redact = redact
+	}
+}
+
+// WithRevision records the revision of the policy returned by revision with every decision.
+func WithRevision(revision func() uint64) DecisionOption {
+	return func(l *decisionLogger) {
+		l.revision = revision
+	}
+}
+
+func (l decisionLogger) HasPermissions(user string, target string, permissions ...string) (bool, error) {
+	start := l.now()
+	granted, err := l.decider.ListPermissions(user, target)
+	latency := l.now().Sub(start)
+	allowed := err == nil && containsAll(granted, permissions)
+
+	decision := l.decision(start, latency, user, target, permissions, err)
+	decision.Granted = sortedOps(granted)
+	decision.Allowed = allowed
+	if writeErr := l.write(decision); writeErr != nil {
+		return false, writeErr
+	}
+
+	return allowed, err
+}
+
+func (l decisionLogger) ListPermissions(user string, target string) (graph.Operations, error) {
+	start := l.now()
+	granted, err := l.decider.ListPermissions(user, target)
+	latency := l.now().Sub(start)
+
+	decision := l.decision(start, latency, user, target, nil, err)
+	decision.Granted = sortedOps(granted)
+	decision.Allowed = len(granted) > 0
+	if writeErr := l.write(decision); writeErr != nil {
+		return nil, writeErr
+	}
+
+	return granted, err
+}
+
+func (l decisionLogger) DecideFor(user string, process string, target string, permissions ...string) (bool, error) {
+	start := l.now()
+	allowed, err := l.decider.DecideFor(user, process, target, permissions...)
+	latency := l.now().Sub(start)
+
+	decision := l.decision(start, latency, user, target, permissions, err)
+	decision.Process = process
+	decision.Allowed = allowed && err == nil
+	if writeErr := l.write(decision); writeErr != nil {
+		return false, writeErr
+	}
+
+	return allowed, err
+}
+
+// ListPermissionsBatch writes a decision for every target. The latency of each decision is the latency of the batch.
+func (l decisionLogger) ListPermissionsBatch(user string, targets []string) (map[string]graph.Operations, error) {
+	start := l.now()
+	permissions, err := l.decider.ListPermissionsBatch(user, targets)
+	latency := l.now().Sub(start)
+	if err != nil {
+		for _, target := range targets {
+			if writeErr := l.write(l.decision(start, latency, user, target, nil, err)); writeErr != nil {
+				return nil, writeErr
+			}
+		}
+
+		return nil, err
+	}
+
+	for _, target := range targets {
+		decision := l.decision(start, latency, user, target, nil, nil)
+		decision.Granted = sortedOps(permissions[target])
+		decision.Allowed = len(permissions[target]) > 0
+		if err = l.write(decision); err != nil {
+			return nil, err
+		}
+	}
+
+	return permissions, nil
+}
+
+// FilterAccessible writes a decision for every target. The latency of each decision is the latency of the filter.
+func (l decisionLogger) FilterAccessible(user string, targets []string, permissions ...string) ([]string, error) {
+	start := l.now()
+	accessible, err := l.decider.FilterAccessible(user, targets, permissions...)
+	latency := l.now().Sub(start)
+	if err != nil {
+		for _, target := range targets {
+			if writeErr := l.write(l.decision(start, latency, user, target, permissions, err)); writeErr != nil {
+				return nil, writeErr
+			}
+		}
+
+		return nil, err
+	}
+
+	allowed := make(map[string]bool, len(accessible))
+	for _, target := range accessible {
+		allowed[target] = true
+	}
+
+	for _, target := range targets {
+		decision := l.decision(start, latency, user, target, permissions, nil)
+		decision.Allowed = allowed[target]
+		if err = l.write(decision); err != nil {
+			return nil, err
+		}
+	}
+
+	return accessible, nil
+}
+
+func (l decisionLogger) WithEnvironment(environment map[string]string) pdp.Decider {
+	l.decider = l.decider.WithEnvironment(environment)
+	l.environment = environment
+	return l
+}
+
+func (l decisionLogger) decision(start time.Time, latency time.Duration, user string, target string, requested []string, err error) Decision {
+	decision := Decision{
+		Time:      start,
+		User:      user,
+		Target:    target,
+		Requested: requested,
+		Latency:   latency,
+	}
+
+	if len(l.environment) > 0 {
+		decision.Environment = make(map[string]string, len(l.environment))
+		for key, value := range l.environment {
+			decision.Environment[key] = value
+		}
+	}
+
+	if l.revision != nil {
+		decision.Revision = l.revision()
+	}
+
+	if err != nil {
+		decision.Error = err.Error()
+	}
+
+	return decision
+}
+
+// write writes the decision unless it allows access and is not sampled.
+func (l decisionLogger) write(decision Decision) error {
+	if decision.Error == "" && decision.Allowed && l.sampleRate < 1 && l.random() >= l.sampleRate {
+		return nil
+	}
+
+	if l.redact != nil {
+		l.redact(&decision)
+	}
+
+	if writeErr := l.sink.WriteDecision(decision); writeErr != nil {
+		return fmt.Errorf("error writing decision: %w", writeErr)
+	}
+
+	return nil
+}
+
+func containsAll(ops graph.Operations, permissions []string) bool {
+	for _, permission := range permissions {
+		if !ops.Contains(permission) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func sortedOps(ops graph.Operations) []string {
+	if len(ops) == 0 {
+		return nil
+	}
+
+	sorted := make([]string, 0, len(ops))
+	for op := range ops {
+		sorted = append(sorted, op)
+	}
+	sort.Strings(sorted)
+
+	return sorted
+}
@@ -0,0 +1,166 @@
+package audit
+
+import (
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
+	"github.com/PM-Master/policy-machine-go/pdp"
+	"github.com/PM-Master/policy-machine-go/pip/memory"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type decisions []Decision
+
+func (d *decisions) WriteDecision(decision Decision) error {
+	*d = append(*d, decision)
+	return nil
+}
+
+func TestDecisionLogger(t *testing.T) {
+	pip := memory.NewPIP()
+	g := pip.Graph()
+	require.NoError(t, g.CreatePolicyClass("pc1"))
+	_, err := g.CreateNode("ua1", graph.UserAttribute, nil, "pc1")
+	require.NoError(t, err)
+	_, err = g.CreateNode("oa1", graph.ObjectAttribute, nil, "pc1")
+	require.NoError(t, err)
+	_, err = g.CreateNode("u1", graph.User, nil, "ua1")
+	require.NoError(t, err)
+	_, err = g.CreateNode("o1", graph.Object, nil, "oa1")
+	require.NoError(t, err)
+	_, err = g.CreateNode("o2", graph.Object, nil, "pc1")
+	require.NoError(t, err)
+	require.NoError(t, g.Associate("ua1", "oa1", graph.ToOps("read", "write")))
+
+	sink := &decisions{}
+	clock := time.Date(2021, 1, 5, 0, 0, 0, 0, time.UTC)
+	decider := NewDecisionLogger(pdp.NewDecider(g, pip.Prohibitions()), sink,
+		WithRevision(func() uint64 { return 7 }),
+		WithRedaction(func(decision *Decision) {
+			decision.User = "redacted"
+		}),
+	).(decisionLogger)
+	decider.now = func() time.Time {
+		clock = clock.Add(time.Millisecond)
+		return clock
+	}
+
+	ok, err := decider.HasPermissions("u1", "o1", "read")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = decider.HasPermissions("u1", "o2", "read")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	accessible, err := decider.FilterAccessible("u1", []string{"o1", "o2"}, "write")
+	require.NoError(t, err)
+	require.Equal(t, []string{"o1"}, accessible)
+
+	require.Equal(t, decisions{
+		{
+			Time:      time.Date(2021, 1, 5, 0, 0, 0, int(time.Millisecond), time.UTC),
+			User:      "redacted",
+			Target:    "o1",
+			Requested: []string{"read"},
+			Granted:   []string{"read", "write"},
+			Allowed:   true,
+			Latency:   time.Millisecond,
+			Revision:  7,
+		},
+		{
+			Time:      time.Date(2021, 1, 5, 0, 0, 0, int(3*time.Millisecond), time.UTC),
+			User:      "redacted",
+			Target:    "o2",
+			Requested: []string{"read"},
+			Latency:   time.Millisecond,
+			Revision:  7,
+		},
+		{
+			Time:      time.Date(2021, 1, 5, 0, 0, 0, int(5*time.Millisecond), time.UTC),
+			User:      "redacted",
+			Target:    "o1",
+			Requested: []string{"write"},
+			Allowed:   true,
+			Latency:   time.Millisecond,
+			Revision:  7,
+		},
+		{
+			Time:      time.Date(2021, 1, 5, 0, 0, 0, int(5*time.Millisecond), time.UTC),
+			User:      "redacted",
+			Target:    "o2",
+			Requested: []string{"write"},
+			Latency:   time.Millisecond,
+			Revision:  7,
+		},
+	}, *sink)
+}
+
+func TestDecisionSampling(t *testing.T) {
+	pip := memory.NewPIP()
+	g := pip.Graph()
+	require.NoError(t, g.CreatePolicyClass("pc1"))
+	_, err := g.CreateNode("ua1", graph.UserAttribute, nil, "pc1")
+	require.NoError(t, err)
+	_, err = g.CreateNode("u1", graph.User, nil, "ua1")
+	require.NoError(t, err)
+	_, err = g.CreateNode("oa1", graph.ObjectAttribute, nil, "pc1")
+	require.NoError(t, err)
+	_, err = g.CreateNode("o1", graph.Object, nil, "oa1")
+	require.NoError(t, err)
+	require.NoError(t, g.Associate("ua1", "oa1", graph.ToOps("read")))
+
+	sink := &decisions{}
+	decider := NewDecisionLogger(pdp.NewDecider(g, nil), sink, WithSampling(0.5)).(decisionLogger)
+	samples := []float64{0.7, 0.2}
+	decider.random = func() float64 {
+		sample := samples[0]
+		samples = samples[1:]
+		return sample
+	}
+
+	// the first allowed decision is not sampled, the deny is always written
+	for i := 0; i < 2; i++ {
+		ok, err := decider.HasPermissions("u1", "o1", "read")
+		require.NoError(t, err)
+		require.True(t, ok)
+	}
+
+	ok, err := decider.HasPermissions("u1", "o1", "write")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	require.Len(t, *sink, 2)
+	require.True(t, (*sink)[0].Allowed)
+	require.False(t, (*sink)[1].Allowed)
+}
+
+func TestDecisionFileSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	sink, err := NewDecisionFileSink(filepath.Join(dir, "decisions.log"))
+	require.NoError(t, err)
+	defer sink.Close()
+
+	start := time.Date(2021, 1, 5, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, sink.WriteDecision(Decision{Time: start, User: "u1", Target: "o1", Allowed: true}))
+	require.NoError(t, sink.WriteDecision(Decision{Time: start.Add(time.Minute), User: "u2", Target: "o1"}))
+	require.NoError(t, sink.WriteDecision(Decision{Time: start.Add(2 * time.Minute), User: "u1", Target: "o2"}))
+
+	decisions, err := sink.QueryDecisions(Filter{})
+	require.NoError(t, err)
+	require.Len(t, decisions, 3)
+
+	decisions, err = sink.QueryDecisions(Filter{Actor: "u1"})
+	require.NoError(t, err)
+	require.Len(t, decisions, 2)
+
+	decisions, err = sink.QueryDecisions(Filter{Node: "o1", From: start.Add(time.Minute)})
+	require.NoError(t, err)
+	require.Equal(t, []Decision{{Time: start.Add(time.Minute), User: "u2", Target: "o1"}}, decisions)
+}
@@ -0,0 +1,213 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+type (
+	// Sink stores audit entries.
+	Sink interface {
+		Write(entry Entry) error
+		// Query returns the entries that match the filter in the order they were written.
+		Query(filter Filter) ([]Entry, error)
+	}
+
+	// Filter selects audit entries. An entry matches if it matches every field that is set. From is inclusive and To
+	// is exclusive.
+	Filter struct {
+		Actor string
+		Node  string
+		From  time.Time
+		To    time.Time
+	}
+
+	// FileSink is the default sink. It appends entries to a file as JSON lines.
+	FileSink struct {
+		log *jsonLog
+	}
+
+	// DecisionFileSink is the default decision sink. It appends decisions to a file as JSON lines. Decisions are kept
+	// in a different file than entries so reading one kind of record never reads the other.
+	DecisionFileSink struct {
+		log *jsonLog
+	}
+
+	// jsonLog is a file of JSON lines that is appended to and read from the start.
+	jsonLog struct {
+		path string
+		mu   sync.Mutex
+		file *os.File
+	}
+)
+
+// NewFileSink returns a sink that appends to the file at the given path, creating it if it does not exist.
+func NewFileSink(path string) (*FileSink, error) {
+	log, err := openJSONLog(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileSink{log: log}, nil
+}
+
+func (s *FileSink) Write(entry Entry) error {
+	return s.log.append(entry)
+}
+
+func (s *FileSink) Query(filter Filter) ([]Entry, error) {
+	entries := make([]Entry, 0)
+	err := s.log.read(func(decoder *json.Decoder) error {
+		entry := Entry{}
+		if err := decoder.Decode(&entry); err != nil {
+			return err
+		}
+
+		if filter.Matches(entry) {
+			entries = append(entries, entry)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// Close closes the file.
+func (s *FileSink) Close() error {
+	return s.log.close()
+}
+
+// NewDecisionFileSink returns a decision sink that appends to the file at the given path, creating it if it does not
+// exist.
+func NewDecisionFileSink(path string) (*DecisionFileSink, error) {
+	log, err := openJSONLog(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DecisionFileSink{log: log}, nil
+}
+
+func (s *DecisionFileSink) WriteDecision(decision Decision) error {
+	return s.log.append(decision)
+}
+
+// QueryDecisions returns the decisions that match the filter in the order they were written. The actor of the filter
+// matches the user of a decision and the node matches its target.
+func (s *DecisionFileSink) QueryDecisions(filter Filter) ([]Decision, error) {
+	decisions := make([]Decision, 0)
+	err := s.log.read(func(decoder *json.Decoder) error {
+		decision := Decision{}
+		if err := decoder.Decode(&decision); err != nil {
+			return err
+		}
+
+		if filter.MatchesDecision(decision) {
+			decisions = append(decisions, decision)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return decisions, nil
+}
+
+// Close closes the file.
+func (s *DecisionFileSink) Close() error {
+	return s.log.close()
+}
+
+func openJSONLog(path string) (*jsonLog, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening audit log %q: %w", path, err)
+	}
+
+	return &jsonLog{path: path, file: file}, nil
+}
+
+func (l *jsonLog) append(v interface{}) error {
+	bytes, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	_, err = l.file.Write(append(bytes, '\n'))
+	return err
+}
+
+// read calls decode until every line of the file has been decoded.
+func (l *jsonLog) read(decode func(decoder *json.Decoder) error) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	file, err := os.Open(l.path)
+	if err != nil {
+		return fmt.Errorf("error opening audit log %q: %w", l.path, err)
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(file)
+	for decoder.More() {
+		if err = decode(decoder); err != nil {
+			return fmt.Errorf("error reading audit log %q: %w", l.path, err)
+		}
+	}
+
+	return nil
+}
+
+func (l *jsonLog) close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.file.Close()
+}
+
+// Matches returns true if the entry matches the filter.
+func (f Filter) Matches(entry Entry) bool {
+	if f.Actor != "" && entry.Actor != f.Actor {
+		return false
+	}
+
+	if !f.matchesTime(entry.Time) {
+		return false
+	}
+
+	if f.Node == "" {
+		return true
+	}
+
+	for _, node := range entry.Nodes {
+		if node == f.Node {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MatchesDecision returns true if the decision matches the filter. The actor matches the user of the decision and the
+// node matches its target.
+func (f Filter) MatchesDecision(decision Decision) bool {
+	return (f.Actor == "" || decision.User == f.Actor) &&
+		(f.Node == "" || decision.Target == f.Node) &&
+		f.matchesTime(decision.Time)
+}
+
+func (f Filter) matchesTime(t time.Time) bool {
+	return (f.From.IsZero() || !t.Before(f.From)) && (f.To.IsZero() || t.Before(f.To))
+}
@@ -0,0 +1,266 @@
+package author
+
+import (
+	"fmt"
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
+	"github.com/PM-Master/policy-machine-go/pdp"
+	"github.com/PM-Master/policy-machine-go/pip/memory"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+type (
+	// AssertionResult is the result of evaluating an assert statement.
+	AssertionResult struct {
+		File      string `json:"file"`
+		Line      int    `json:"line"`
+		Assertion string `json:"assertion"`
+		Passed    bool   `json:"passed"`
+		Message   string `json:"message,omitempty"`
+	}
+
+	// Association identifies an association by its subject and target.
+	Association struct {
+		Subject string `json:"subject"`
+		Target  string `json:"target"`
+	}
+
+	// TestReport holds the results of the assertions in a policy and the associations of the policy that granted or
+	// could have granted an operation checked by a permission assertion.
+	TestReport struct {
+		Results     []AssertionResult `json:"results"`
+		Exercised   []Association     `json:"exercised"`
+		Unexercised []Association     `json:"unexercised"`
+	}
+)
+
+// RunTests applies the policy at the given path to an empty policy and evaluates each assert statement against the
+// policy as it is when the statement is reached. An error is returned if the policy cannot be parsed or applied, not
+// if an assertion fails.
+func RunTests(path string) (TestReport, error) {
+	pal, err := ioutil.ReadFile(path)
+	if err != nil {
+		return TestReport{}, fmt.Errorf("error reading file %q: %w", path, err)
+	}
+
+	stmts, lines, _, err := parse(string(pal))
+	if err != nil {
+		return TestReport{}, fmt.Errorf("error parsing policy author language: %w", err)
+	}
+
+	fe := memory.NewPIP()
+	results := make([]AssertionResult, 0)
+	exercised := make(map[Association]bool)
+	for i, stmt := range stmts {
+		var result AssertionResult
+		switch assertion := stmt.(type) {
+		case *ngac.AssertPermissionStatement:
+			if result, err = assertPermissions(fe, assertion); err != nil {
+				return TestReport{}, err
+			}
+
+			if err = exerciseAssociations(fe.Graph(), assertion, exercised); err != nil {
+				return TestReport{}, err
+			}
+		case *ngac.AssertAssignmentStatement:
+			if result, err = assertAssignment(fe.Graph(), assertion); err != nil {
+				return TestReport{}, err
+			}
+		default:
+			if err = stmt.Apply(fe); err != nil {
+				return TestReport{}, fmt.Errorf("%s:%d: error applying statement: %w", path, lines[i], err)
+			}
+
+			continue
+		}
+
+		result.File = path
+		result.Line = lines[i]
+		results = append(results, result)
+	}
+
+	report := TestReport{
+		Results:     results,
+		Exercised:   make([]Association, 0),
+		Unexercised: make([]Association, 0),
+	}
+
+	assocs, err := fe.Graph().GetAssociations()
+	if err != nil {
+		return TestReport{}, err
+	}
+
+	for subject, targets := range assocs {
+		for target := range targets {
+			assoc := Association{Subject: subject, Target: target}
+			if exercised[assoc] {
+				report.Exercised = append(report.Exercised, assoc)
+			} else {
+				report.Unexercised = append(report.Unexercised, assoc)
+			}
+		}
+	}
+
+	sortAssociations(report.Exercised)
+	sortAssociations(report.Unexercised)
+
+	return report, nil
+}
+
+// Passed returns true if every assertion passed.
+func (r TestReport) Passed() bool {
+	for _, result := range r.Results {
+		if !result.Passed {
+			return false
+		}
+	}
+
+	return true
+}
+
+func assertPermissions(fe ngac.FunctionalEntity, assertion *ngac.AssertPermissionStatement) (AssertionResult, error) {
+	ops := sortedOps(assertion.Operations)
+	verb := "can"
+	if assertion.Negated {
+		verb = "cannot"
+	}
+
+	result := AssertionResult{
+		Assertion: fmt.Sprintf("%s %s %s on %s", assertion.User, verb, strings.Join(ops, ", "), assertion.Target),
+	}
+
+	for _, name := range []string{assertion.User, assertion.Target} {
+		if ok, err := fe.Graph().Exists(name); err != nil {
+			return AssertionResult{}, err
+		} else if !ok {
+			result.Message = fmt.Sprintf("node %s does not exist", name)
+			return result, nil
+		}
+	}
+
+	allowed, err := pdp.NewDecider(fe.Graph(), fe.Prohibitions()).ListPermissions(assertion.User, assertion.Target)
+	if err != nil {
+		return AssertionResult{}, err
+	}
+
+	unexpected := make([]string, 0)
+	for _, op := range ops {
+		if allowed.Contains(op) == assertion.Negated {
+			unexpected = append(unexpected, op)
+		}
+	}
+
+	result.Passed = len(unexpected) == 0
+	if result.Passed {
+		return result, nil
+	}
+
+	if assertion.Negated {
+		result.Message = fmt.Sprintf("%s has %s", assertion.User, strings.Join(unexpected, ", "))
+	} else {
+		result.Message = fmt.Sprintf("%s does not have %s", assertion.User, strings.Join(unexpected, ", "))
+	}
+
+	return result, nil
+}
+
+func assertAssignment(g ngac.Graph, assertion *ngac.AssertAssignmentStatement) (AssertionResult, error) {
+	verb := "in"
+	if assertion.Negated {
+		verb = "not in"
+	}
+
+	result := AssertionResult{Assertion: fmt.Sprintf("%s %s %s", assertion.Child, verb, assertion.Parent)}
+	contained, err := (&ngac.InCondition{Child: assertion.Child, Parent: assertion.Parent}).Evaluate(g)
+	if err != nil {
+		return AssertionResult{}, err
+	}
+
+	result.Passed = contained != assertion.Negated
+	if !result.Passed && assertion.Negated {
+		result.Message = fmt.Sprintf("%s is contained in %s", assertion.Child, assertion.Parent)
+	} else if !result.Passed {
+		result.Message = fmt.Sprintf("%s is not contained in %s", assertion.Child, assertion.Parent)
+	}
+
+	return result, nil
+}
+
+// exerciseAssociations adds the associations of the user and the attributes it is in with the target or an attribute
+// it is in that have an operation of the assertion.
+func exerciseAssociations(g ngac.Graph, assertion *ngac.AssertPermissionStatement, exercised map[Association]bool) error {
+	subjects, err := selfAndAncestors(g, assertion.User)
+	if err != nil {
+		return err
+	}
+
+	targets, err := selfAndAncestors(g, assertion.Target)
+	if err != nil {
+		return err
+	}
+
+	for subject := range subjects {
+		assocs, err := g.GetAssociationsForSubject(subject)
+		if err != nil {
+			return err
+		}
+
+		for target, ops := range assocs {
+			if !targets[target] {
+				continue
+			}
+
+			for op := range assertion.Operations {
+				if ops.Contains(op) || op == graph.AllOps {
+					exercised[Association{Subject: subject, Target: target}] = true
+					break
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func selfAndAncestors(g ngac.Graph, name string) (map[string]bool, error) {
+	nodes := map[string]bool{name: true}
+	queue := []string{name}
+	for len(queue) > 0 {
+		parents, err := g.GetParents(queue[0])
+		if err != nil {
+			return nil, err
+		}
+		queue = queue[1:]
+
+		for parent := range parents {
+			if !nodes[parent] {
+				nodes[parent] = true
+				queue = append(queue, parent)
+			}
+		}
+	}
+
+	return nodes, nil
+}
+
+func sortedOps(ops graph.Operations) []string {
+	sorted := make([]string, 0, len(ops))
+	for op := range ops {
+		sorted = append(sorted, op)
+	}
+	sort.Strings(sorted)
+
+	return sorted
+}
+
+func sortAssociations(assocs []Association) {
+	sort.Slice(assocs, func(i, j int) bool {
+		if assocs[i].Subject != assocs[j].Subject {
+			return assocs[i].Subject < assocs[j].Subject
+		}
+
+		return assocs[i].Target < assocs[j].Target
+	})
+}
@@ -0,0 +1,25 @@
+package author
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestRunTests(t *testing.T) {
+	report, err := RunTests("testdata/assert.ngac")
+	require.NoError(t, err)
+	require.Equal(t, []AssertionResult{
+		{File: "testdata/assert.ngac", Line: 14, Assertion: "u1 can read, write on o1", Passed: true},
+		{File: "testdata/assert.ngac", Line: 15, Assertion: "u2 cannot write on o1", Passed: true},
+		{File: "testdata/assert.ngac", Line: 16, Assertion: "u1 in ua1", Passed: true},
+		{File: "testdata/assert.ngac", Line: 19, Assertion: "u2 can write on o1", Message: "u2 does not have write"},
+		{File: "testdata/assert.ngac", Line: 20, Assertion: "u2 not in ua2", Message: "u2 is contained in ua2"},
+		{File: "testdata/assert.ngac", Line: 23, Assertion: "u1 cannot write on o1", Passed: true},
+	}, report.Results)
+	require.False(t, report.Passed())
+	require.Equal(t, []Association{{Subject: "ua1", Target: "oa1"}}, report.Exercised)
+	require.Equal(t, []Association{{Subject: "ua2", Target: "oa1"}, {Subject: "ua2", Target: "oa2"}}, report.Unexercised)
+
+	_, err = RunTests("testdata/missing.ngac")
+	require.Error(t, err)
+}
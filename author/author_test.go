@@ -2,6 +2,7 @@ package author
 
 import (
 	"fmt"
+	"github.com/PM-Master/policy-machine-go/ngac"
 	"github.com/PM-Master/policy-machine-go/pip/memory"
 	"github.com/stretchr/testify/require"
 	"os"
@@ -45,4 +46,13 @@ func TestGraphAndObligations(t *testing.T) {
 	author := New(pip)
 	err := author.ReadAndApply("testdata/test2.ngac")
 	require.NoError(t, err)
+
+	obligation, err := pip.Obligations().Get("set_account_active")
+	require.NoError(t, err)
+	require.Equal(t, 1, len(obligation.Response.Actions))
+	ifStmt, ok := obligation.Response.Actions[0].(*ngac.IfStatement)
+	require.True(t, ok)
+	require.Equal(t, &ngac.ExistsCondition{Name: "$account_UA"}, ifStmt.Condition)
+	require.Equal(t, 3, len(ifStmt.Then))
+	require.Equal(t, 1, len(ifStmt.Else))
 }
@@ -0,0 +1,272 @@
+package author
+
+import (
+	"fmt"
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Format returns the policy author language statements that parse to the given statements, one per line. Statements
+// in blocks are indented with tabs.
+func Format(stmts []ngac.Statement) (string, error) {
+	return formatStatements(stmts, "")
+}
+
+func formatStatements(stmts []ngac.Statement, indent string) (string, error) {
+	b := strings.Builder{}
+	for _, stmt := range stmts {
+		s, err := formatStatement(stmt, indent)
+		if err != nil {
+			return "", err
+		}
+
+		fmt.Fprintf(&b, "%s%s;\n", indent, s)
+	}
+
+	return b.String(), nil
+}
+
+func formatStatement(stmt ngac.Statement, indent string) (string, error) {
+	switch s := stmt.(type) {
+	case *ngac.CreatePolicyStatement:
+		return fmt.Sprintf("create policy %s", s.Name), nil
+	case *ngac.CreateNodeStatement:
+		if s.Kind == graph.PolicyClass {
+			return fmt.Sprintf("create policy %s", s.Name), nil
+		}
+
+		str := fmt.Sprintf("create %s %s", kindKeyword(s.Kind), s.Name)
+		if len(s.Properties) > 0 {
+			str += " with properties " + formatProperties(s.Properties)
+		}
+
+		return fmt.Sprintf("%s in %s", str, strings.Join(s.Parents, ", ")), nil
+	case *ngac.UpdateNodeStatement:
+		return strings.TrimSpace(fmt.Sprintf("update %s with properties %s", s.Name, formatProperties(s.Properties))), nil
+	case *ngac.AssignStatement:
+		return fmt.Sprintf("assign %s to %s", s.Child, strings.Join(s.Parents, ", ")), nil
+	case *ngac.DeassignStatement:
+		return fmt.Sprintf("deassign %s from %s", s.Child, strings.Join(s.Parents, ", ")), nil
+	case *ngac.DeleteNodeStatement:
+		return fmt.Sprintf("delete %s", s.Name), nil
+	case *ngac.GrantStatement:
+		str := fmt.Sprintf("grant %s %s on %s%s", s.Uattr, formatOps(s.Operations), s.Target, formatValidity(s.Validity))
+		if s.Condition != "" {
+			str += " when " + s.Condition
+		}
+
+		return str, nil
+	case *ngac.DissociateStatement:
+		return fmt.Sprintf("dissociate %s from %s", s.Uattr, s.Target), nil
+	case *ngac.DenyStatement:
+		str := "deny "
+		if s.Name != "" {
+			str += s.Name + " for "
+		}
+
+		if s.Process {
+			str += "process "
+		}
+
+		str += fmt.Sprintf("%s %s on ", s.Subject, formatOps(s.Operations))
+		if s.Intersection {
+			str += "intersection of "
+		}
+
+		return str + strings.Join(s.Containers, ", ") + formatValidity(s.Validity), nil
+	case *ngac.DeleteProhibitionStatement:
+		return fmt.Sprintf("delete deny %s", s.Name), nil
+	case *ngac.SSoDStatement:
+		return fmt.Sprintf("constraint %sssod %s max %d", formatName(s.Name), strings.Join(s.Attributes, ", "), s.Max), nil
+	case *ngac.DSoDStatement:
+		return fmt.Sprintf("constraint %sdsod %s", formatName(s.Name), strings.Join(s.Operations, ", ")), nil
+	case *ngac.DeleteConstraintStatement:
+		if s.Dynamic {
+			return fmt.Sprintf("delete dsod %s", s.Name), nil
+		}
+
+		return fmt.Sprintf("delete ssod %s", s.Name), nil
+	case *ngac.AssertPermissionStatement:
+		verb := "can"
+		if s.Negated {
+			verb = "cannot"
+		}
+
+		return fmt.Sprintf("assert %s %s %s on %s", s.User, verb, formatOps(s.Operations), s.Target), nil
+	case *ngac.AssertAssignmentStatement:
+		verb := "in"
+		if s.Negated {
+			verb = "not in"
+		}
+
+		return fmt.Sprintf("assert %s %s %s", s.Child, verb, s.Parent), nil
+	case *ngac.ObligationStatement:
+		return formatObligation(s.Obligation, indent)
+	case *ngac.DeleteObligationStatement:
+		return fmt.Sprintf("delete obligation %s", s.Label), nil
+	case *ngac.EnableObligationStatement:
+		return fmt.Sprintf("enable obligation %s", s.Label), nil
+	case *ngac.DisableObligationStatement:
+		return fmt.Sprintf("disable obligation %s", s.Label), nil
+	case *ngac.IfStatement:
+		condition, err := formatCondition(s.Condition)
+		if err != nil {
+			return "", err
+		}
+
+		then, err := formatBlock(s.Then, indent)
+		if err != nil {
+			return "", err
+		}
+
+		str := fmt.Sprintf("if %s then %s", condition, then)
+		if len(s.Else) == 0 {
+			return str, nil
+		}
+
+		if elseIf, ok := s.Else[0].(*ngac.IfStatement); ok && len(s.Else) == 1 {
+			elseStr, err := formatStatement(elseIf, indent)
+			if err != nil {
+				return "", err
+			}
+
+			return fmt.Sprintf("%s else %s", str, elseStr), nil
+		}
+
+		elseBlock, err := formatBlock(s.Else, indent)
+		if err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("%s else %s", str, elseBlock), nil
+	case *ngac.ForeachStatement:
+		body, err := formatBlock(s.Body, indent)
+		if err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("foreach $%s in %s of %s do %s", s.Variable, s.Relation, s.Node, body), nil
+	case *ngac.EmitStatement:
+		str := fmt.Sprintf("emit %s", s.Event)
+		if s.Target != "" {
+			str += " on " + s.Target
+		}
+
+		if len(s.Args) > 0 {
+			str += " with " + formatProperties(s.Args)
+		}
+
+		return str, nil
+	default:
+		return "", fmt.Errorf("cannot format statement of type %T", stmt)
+	}
+}
+
+func formatBlock(stmts []ngac.Statement, indent string) (string, error) {
+	body, err := formatStatements(stmts, indent+"\t")
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("(\n%s%s)", body, indent), nil
+}
+
+func formatObligation(obligation ngac.Obligation, indent string) (string, error) {
+	str := fmt.Sprintf("obligation %s", obligation.Label)
+	if obligation.Priority != 0 {
+		str += fmt.Sprintf(" priority %d", obligation.Priority)
+	}
+
+	ops := make([]string, 0, len(obligation.Event.Operations))
+	for _, op := range obligation.Event.Operations {
+		if len(op.Args) > 0 {
+			ops = append(ops, fmt.Sprintf("%s(%s)", op.Operation, strings.Join(op.Args, ", ")))
+		} else {
+			ops = append(ops, op.Operation)
+		}
+	}
+
+	str += fmt.Sprintf(" when %s performs %s", obligation.Event.Subject, strings.Join(ops, " "+Or+" "))
+	if len(obligation.Event.Containers) > 0 {
+		str += " on " + strings.Join(obligation.Event.Containers, ", ")
+	}
+
+	response, err := formatBlock(obligation.Response.Actions, indent)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%s do %s", str, response), nil
+}
+
+func formatCondition(condition ngac.Condition) (string, error) {
+	switch c := condition.(type) {
+	case *ngac.ExistsCondition:
+		return fmt.Sprintf("exists %s", c.Name), nil
+	case *ngac.InCondition:
+		return fmt.Sprintf("%s in %s", c.Child, c.Parent), nil
+	case *ngac.NotCondition:
+		str, err := formatCondition(c.Condition)
+		return "not " + str, err
+	default:
+		return "", fmt.Errorf("cannot format condition of type %T", condition)
+	}
+}
+
+func kindKeyword(kind graph.Kind) string {
+	switch kind {
+	case graph.UserAttribute:
+		return "user attribute"
+	case graph.ObjectAttribute:
+		return "object attribute"
+	case graph.Object:
+		return "object"
+	case graph.User:
+		return "user"
+	default:
+		return "policy"
+	}
+}
+
+func formatName(name string) string {
+	if name == "" {
+		return ""
+	}
+
+	return name + " for "
+}
+
+func formatOps(ops graph.Operations) string {
+	return strings.Join(sortedOps(ops), ", ")
+}
+
+func formatProperties(properties map[string]string) string {
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", key, properties[key]))
+	}
+
+	return strings.Join(pairs, ", ")
+}
+
+func formatValidity(validity graph.Validity) string {
+	str := ""
+	if validity.NotBefore != nil {
+		str += " from " + validity.NotBefore.Format(time.RFC3339)
+	}
+
+	if validity.NotAfter != nil {
+		str += " until " + validity.NotAfter.Format(time.RFC3339)
+	}
+
+	return str
+}
@@ -0,0 +1,62 @@
+package author
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	pal := `create policy pc1;
+create user attribute ua1 in pc1;
+create object attribute oa1 with properties a=1, b=2 in pc1, pc2;
+create object o1 in oa1;
+create user u1 in ua1;
+update o1 with properties owner=u1;
+update o1 with properties;
+assign u1 to ua1, ua2;
+deassign u1 from ua2;
+delete o1;
+grant ua1 read, write on oa1 from 2021-01-01T00:00:00Z until 2022-01-01T00:00:00Z when env.ip in "10.0.0.0/8";
+grant ua1 read on oa1;
+dissociate ua1 from oa1;
+deny d1 for process 123 read on intersection of oa1, !oa2 until 2022-01-01T00:00:00Z;
+deny ua1 write on oa1;
+delete deny d1;
+constraint c1 for ssod ua1, ua2 max 1;
+constraint dsod submit, approve;
+delete ssod c1;
+delete dsod dsod-submit-approve;
+assert u1 can read on o1;
+assert u1 cannot delete, write on o1;
+assert u1 not in ua2;
+obligation o1 priority 2 when any_user performs submit OR approve on oa1, oa2 do (
+	create object $target-copy in oa1;
+	if exists o1 then (
+		delete o1;
+	) else if not u1 in ua1 then (
+		assign u1 to ua1;
+	) else (
+		emit done on oa1 with a=1;
+	);
+	foreach $child in children of oa1 do (
+		deassign $child from oa1;
+	);
+);
+obligation o2 when any_user performs create_object(name) do (
+	emit created;
+);
+delete obligation o1;
+enable obligation o2;
+disable obligation o2;
+`
+	stmts, _, err := Parse(pal)
+	require.NoError(t, err)
+
+	formatted, err := Format(stmts)
+	require.NoError(t, err)
+	require.Equal(t, pal, formatted)
+
+	reparsed, _, err := Parse(formatted)
+	require.NoError(t, err)
+	require.Equal(t, stmts, reparsed)
+}
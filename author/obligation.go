@@ -3,6 +3,7 @@ package author
 import (
 	"fmt"
 	"github.com/PM-Master/policy-machine-go/ngac"
+	"strconv"
 	"strings"
 )
 
@@ -33,6 +34,7 @@ type (
 
 const (
 	Obligation = "OBLIGATION"
+	Priority   = "PRIORITY"
 	When       = "WHEN"
 	Performs   = "PERFORMS"
 	On         = "ON"
@@ -51,14 +53,31 @@ func (o obligationParser) Parse(obligation string) (ngac.Obligation, error) {
 	fields := strings.Fields(obligation)
 
 	label := fields[1]
-	index := 2
-	for index = range fields {
-		if strings.HasPrefix(strings.ToUpper(fields[index]), Do) {
+
+	// an optional priority follows the label
+	eventIndex := 2
+	priority := 0
+	if len(fields) > 3 && strings.ToUpper(fields[2]) == Priority {
+		var err error
+		if priority, err = strconv.Atoi(fields[3]); err != nil {
+			return ngac.Obligation{}, fmt.Errorf("invalid priority %q: %w", fields[3], err)
+		}
+
+		eventIndex = 4
+	}
+
+	index := -1
+	for i := eventIndex; i < len(fields); i++ {
+		if strings.HasPrefix(strings.ToUpper(fields[i]), Do) {
+			index = i
 			break
 		}
 	}
+	if index == -1 {
+		return ngac.Obligation{}, fmt.Errorf("obligation %q has no %s response", label, Do)
+	}
 
-	event := strings.Join(fields[2:index], " ")
+	event := strings.Join(fields[eventIndex:index], " ")
 	eventPattern, err := o.eventParser.Parse(event)
 	if err != nil {
 		return ngac.Obligation{}, fmt.Errorf("error parsing event: %w", err)
@@ -72,6 +91,7 @@ func (o obligationParser) Parse(obligation string) (ngac.Obligation, error) {
 
 	return ngac.Obligation{
 		Label:    label,
+		Priority: priority,
 		Event:    eventPattern,
 		Response: responsePattern,
 	}, nil
@@ -130,6 +150,7 @@ func (e eventParser) parsePerforms(performs string) ([]ngac.EventOperation, erro
 
 func (e eventParser) parseEventOperation(opStr string) ngac.EventOperation {
 	op := ngac.EventOperation{}
+	opStr = strings.TrimSpace(opStr)
 
 	// if the string contains a parenthesis the operation has arguments
 	if strings.Contains(opStr, "(") {
@@ -137,7 +158,7 @@ func (e eventParser) parseEventOperation(opStr string) ngac.EventOperation {
 			return r == '(' || r == ')'
 		})
 
-		op.Operation = split[0]
+		op.Operation = strings.TrimSpace(split[0])
 		op.Args = strings.Fields(strings.ReplaceAll(split[1], ",", " "))
 	} else {
 		op.Operation = opStr
@@ -74,3 +74,24 @@ func TestName(t *testing.T) {
 	strings := []string{"1", "2", "3"}
 	fmt.Println(strings[2:3])
 }
+
+func TestParserPriority(t *testing.T) {
+	obligationStr := `
+OBLIGATION myObl PRIORITY 5
+WHEN ANY_USER
+PERFORMS op1
+DO(
+    create user attribute ua1 in ua2;
+);
+`
+
+	obligation, err := NewObligationParser().Parse(obligationStr)
+	require.NoError(t, err)
+	require.Equal(t, "myObl", obligation.Label)
+	require.Equal(t, 5, obligation.Priority)
+	require.Equal(t, "ANY_USER", obligation.Event.Subject)
+	require.Equal(t, []ngac.EventOperation{{Operation: "op1"}}, obligation.Event.Operations)
+
+	_, err = NewObligationParser().Parse("OBLIGATION myObl PRIORITY high WHEN ANY_USER PERFORMS op1 DO(create user attribute ua1 in ua2;);")
+	require.Error(t, err)
+}
@@ -3,8 +3,20 @@ package author
 import (
 	"fmt"
 	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/ngac/expr"
 	"github.com/PM-Master/policy-machine-go/ngac/graph"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
+)
+
+var (
+	whenRegex = regexp.MustCompile(`(?i)\s+when\s+`)
+	// validityRegex matches the FROM and UNTIL clauses at the end of a grant or deny statement
+	validityRegex = regexp.MustCompile(`(?i)(\s+(from|until)\s+\S+)+\s*$`)
+
+	timeLayouts = []string{time.RFC3339, "2006-01-02T15:04Z07:00", "2006-01-02"}
 )
 
 type ParsedFunction struct {
@@ -14,11 +26,18 @@ type ParsedFunction struct {
 }
 
 func Parse(pal string) ([]ngac.Statement, map[string]ParsedFunction, error) {
+	stmts, _, functions, err := parse(pal)
+	return stmts, functions, err
+}
+
+// parse parses the statements of the policy and returns the line each statement starts on.
+func parse(pal string) ([]ngac.Statement, []int, map[string]ParsedFunction, error) {
 	split := strings.Split(pal, "\n")
 	lines := make([]string, 0)
 	for _, s := range split {
+		// keep an empty line in place of comments so statements keep their line numbers
 		if strings.HasPrefix(strings.TrimSpace(s), "#") {
-			continue
+			s = ""
 		}
 
 		lines = append(lines, s)
@@ -26,15 +45,16 @@ func Parse(pal string) ([]ngac.Statement, map[string]ParsedFunction, error) {
 
 	pal = strings.Join(lines, "\n")
 
-	statements := splitStatements(pal)
-	return parseStatements(statements)
+	statements, stmtLines := splitStatements(pal)
+	return parseStatements(statements, stmtLines)
 }
 
-func parseStatements(statements []string) ([]ngac.Statement, map[string]ParsedFunction, error) {
+func parseStatements(statements []string, lines []int) ([]ngac.Statement, []int, map[string]ParsedFunction, error) {
 	stmts := make([]ngac.Statement, 0)
+	parsedLines := make([]int, 0)
 	functions := make(map[string]ParsedFunction)
 	vars := make(map[string]string, 0)
-	for _, stmtStr := range statements {
+	for i, stmtStr := range statements {
 		stmtStr = strings.TrimSuffix(strings.TrimSpace(stmtStr), ";")
 		upperStmtStr := strings.ToUpper(stmtStr)
 
@@ -55,7 +75,7 @@ func parseStatements(statements []string) ([]ngac.Statement, map[string]ParsedFu
 			obligationParser := NewObligationParser()
 			o, err := obligationParser.Parse(stmtStr)
 			if err != nil {
-				return nil, nil, fmt.Errorf("error parsing obligation: %w", err)
+				return nil, nil, nil, fmt.Errorf("error parsing obligation: %w", err)
 			}
 
 			stmt = &ngac.ObligationStatement{Obligation: o}
@@ -63,16 +83,33 @@ func parseStatements(statements []string) ([]ngac.Statement, map[string]ParsedFu
 			stmt, err = parseAssign(stmtStr)
 		} else if strings.HasPrefix(upperStmtStr, "DEASSIGN") {
 			stmt, err = parseDeassign(stmtStr)
+		} else if strings.HasPrefix(upperStmtStr, "ENABLE OBLIGATION ") ||
+			strings.HasPrefix(upperStmtStr, "DISABLE OBLIGATION ") {
+			stmt, err = parseObligationLifecycle(stmtStr)
 		} else if strings.HasPrefix(upperStmtStr, "DELETE") {
 			stmt, err = parseDelete(stmtStr)
+		} else if strings.HasPrefix(upperStmtStr, "UPDATE ") {
+			stmt, err = parseUpdate(stmtStr)
+		} else if strings.HasPrefix(upperStmtStr, "DISSOCIATE ") {
+			stmt, err = parseDissociate(stmtStr)
 		} else if strings.HasPrefix(upperStmtStr, "GRANT") {
 			stmt, err = parseGrant(stmtStr)
 		} else if strings.HasPrefix(upperStmtStr, "DENY") {
 			stmt, err = parseDeny(stmtStr)
+		} else if strings.HasPrefix(upperStmtStr, "IF ") {
+			stmt, err = parseIf(stmtStr)
+		} else if strings.HasPrefix(upperStmtStr, "FOREACH ") {
+			stmt, err = parseForeach(stmtStr)
+		} else if strings.HasPrefix(upperStmtStr, "EMIT ") {
+			stmt, err = parseEmit(stmtStr)
+		} else if strings.HasPrefix(upperStmtStr, "CONSTRAINT ") {
+			stmt, err = parseConstraint(stmtStr)
+		} else if strings.HasPrefix(upperStmtStr, "ASSERT ") {
+			stmt, err = parseAssert(stmtStr)
 		} else if strings.HasPrefix(upperStmtStr, "FUNC") {
 			function, err := parseFunc(stmtStr)
 			if err != nil {
-				return nil, nil, err
+				return nil, nil, nil, err
 			}
 
 			functions[function.Name] = function
@@ -83,7 +120,7 @@ func parseStatements(statements []string) ([]ngac.Statement, map[string]ParsedFu
 		} else if strings.HasPrefix(upperStmtStr, "LET") {
 			varName, varValue, err := parseVar(stmtStr)
 			if err != nil {
-				return nil, nil, err
+				return nil, nil, nil, err
 			}
 
 			varName = fmt.Sprintf("$%s", varName)
@@ -95,13 +132,14 @@ func parseStatements(statements []string) ([]ngac.Statement, map[string]ParsedFu
 		}
 
 		if err != nil {
-			return nil, nil, fmt.Errorf("error parsing statement %q: %w", stmtStr, err)
+			return nil, nil, nil, fmt.Errorf("error parsing statement %q: %w", stmtStr, err)
 		}
 
 		stmts = append(stmts, stmt)
+		parsedLines = append(parsedLines, lines[i])
 	}
 
-	return stmts, functions, nil
+	return stmts, parsedLines, functions, nil
 }
 
 func resolveVars(stmt string, vars map[string]string) string {
@@ -145,9 +183,220 @@ func parseFunc(funcStr string) (ParsedFunction, error) {
 	}, nil
 }
 
+// `IF <condition> THEN (<statements>) [ELSE (<statements>) | ELSE IF ...];`
+func parseIf(stmtStr string) (ngac.Statement, error) {
+	stmtStr = strings.TrimSpace(stmtStr[2:])
+
+	index := strings.Index(stmtStr, "(")
+	if index < 0 {
+		return nil, fmt.Errorf("IF statement must have a THEN block")
+	}
+
+	fields := strings.Fields(stmtStr[:index])
+	if len(fields) < 2 || strings.ToUpper(fields[len(fields)-1]) != "THEN" {
+		return nil, fmt.Errorf("IF statement must have a THEN block")
+	}
+
+	condition, err := parseCondition(fields[:len(fields)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	thenStr, rest, err := parseBlock(stmtStr[index:])
+	if err != nil {
+		return nil, err
+	}
+
+	thenStmts, _, err := Parse(thenStr)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing THEN block: %w", err)
+	}
+
+	stmt := &ngac.IfStatement{
+		Condition: condition,
+		Then:      thenStmts,
+	}
+
+	if rest == "" {
+		return stmt, nil
+	}
+
+	if !strings.HasPrefix(strings.ToUpper(rest), "ELSE") {
+		return nil, fmt.Errorf("unexpected %q after THEN block", rest)
+	}
+
+	rest = strings.TrimSpace(rest[4:])
+	if strings.HasPrefix(strings.ToUpper(rest), "IF ") {
+		elseIf, err := parseIf(rest)
+		if err != nil {
+			return nil, err
+		}
+
+		stmt.Else = []ngac.Statement{elseIf}
+		return stmt, nil
+	}
+
+	elseStr, rest, err := parseBlock(rest)
+	if err != nil {
+		return nil, err
+	}
+
+	if rest != "" {
+		return nil, fmt.Errorf("unexpected %q after ELSE block", rest)
+	}
+
+	if stmt.Else, _, err = Parse(elseStr); err != nil {
+		return nil, fmt.Errorf("error parsing ELSE block: %w", err)
+	}
+
+	return stmt, nil
+}
+
+// `FOREACH <var> IN CHILDREN|PARENTS OF <node> DO (<statements>);`
+func parseForeach(stmtStr string) (ngac.Statement, error) {
+	index := strings.Index(stmtStr, "(")
+	if index < 0 {
+		return nil, fmt.Errorf("FOREACH statement must have a DO block")
+	}
+
+	fields := strings.Fields(stmtStr[:index])
+	if len(fields) != 7 ||
+		strings.ToUpper(fields[2]) != "IN" ||
+		strings.ToUpper(fields[4]) != "OF" ||
+		strings.ToUpper(fields[6]) != "DO" {
+		return nil, fmt.Errorf("expected FOREACH <var> IN CHILDREN|PARENTS OF <node> DO (<statements>)")
+	}
+
+	var relation ngac.Relation
+	switch strings.ToUpper(fields[3]) {
+	case "CHILDREN":
+		relation = ngac.Children
+	case "PARENTS":
+		relation = ngac.Parents
+	default:
+		return nil, fmt.Errorf("unknown relation %q, expected CHILDREN or PARENTS", fields[3])
+	}
+
+	bodyStr, rest, err := parseBlock(stmtStr[index:])
+	if err != nil {
+		return nil, err
+	}
+
+	if rest != "" {
+		return nil, fmt.Errorf("unexpected %q after DO block", rest)
+	}
+
+	body, _, err := Parse(bodyStr)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing DO block: %w", err)
+	}
+
+	return &ngac.ForeachStatement{
+		Variable: strings.TrimPrefix(fields[1], "$"),
+		Relation: relation,
+		Node:     fields[5],
+		Body:     body,
+	}, nil
+}
+
+// `EMIT <event> [ON <target>] [WITH <arg>=<value>, ...];`
+func parseEmit(stmtStr string) (ngac.Statement, error) {
+	fields := strings.Fields(stmtStr)
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("EMIT statement must have an event")
+	}
+
+	stmt := &ngac.EmitStatement{
+		Event: fields[1],
+		Args:  make(map[string]string),
+	}
+
+	index := 2
+	if index < len(fields) && strings.ToUpper(fields[index]) == "ON" {
+		if index+1 >= len(fields) {
+			return nil, fmt.Errorf("ON clause must have a target")
+		}
+
+		stmt.Target = fields[index+1]
+		index += 2
+	}
+
+	if index < len(fields) {
+		if strings.ToUpper(fields[index]) != "WITH" {
+			return nil, fmt.Errorf("unexpected %q in EMIT statement", fields[index])
+		}
+
+		argsStr := strings.Join(fields[index+1:], " ")
+		for _, arg := range strings.Split(argsStr, ",") {
+			kv := strings.SplitN(strings.TrimSpace(arg), "=", 2)
+			if len(kv) != 2 {
+				return nil, fmt.Errorf("invalid arg %q, expected <arg>=<value>", arg)
+			}
+
+			stmt.Args[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+		}
+	}
+
+	return stmt, nil
+}
+
+// `EXISTS <node>`, `<child> IN <parent>` or `NOT <condition>`
+func parseCondition(fields []string) (ngac.Condition, error) {
+	if len(fields) > 1 && strings.ToUpper(fields[0]) == "NOT" {
+		condition, err := parseCondition(fields[1:])
+		if err != nil {
+			return nil, err
+		}
+
+		return &ngac.NotCondition{Condition: condition}, nil
+	}
+
+	if len(fields) == 2 && strings.ToUpper(fields[0]) == "EXISTS" {
+		return &ngac.ExistsCondition{Name: fields[1]}, nil
+	}
+
+	if len(fields) == 3 && strings.ToUpper(fields[1]) == "IN" {
+		return &ngac.InCondition{Child: fields[0], Parent: fields[2]}, nil
+	}
+
+	return nil, fmt.Errorf("unknown condition %q", strings.Join(fields, " "))
+}
+
+// parseBlock returns the contents of the parenthesized block at the start of str and
+// whatever follows the closing parenthesis.
+func parseBlock(str string) (string, string, error) {
+	str = strings.TrimSpace(str)
+	if !strings.HasPrefix(str, "(") {
+		return "", "", fmt.Errorf("expected block to start with '('")
+	}
+
+	parenCounter := 0
+	for i, c := range str {
+		if c == '(' {
+			parenCounter++
+		} else if c == ')' {
+			parenCounter--
+		}
+
+		if parenCounter == 0 {
+			return str[1:i], strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(str[i+1:]), ";")), nil
+		}
+	}
+
+	return "", "", fmt.Errorf("block is missing a closing ')'")
+}
+
+// `DENY [<name> [FOR]] [PROCESS] <subject> {<permission>} ON [INTERSECTION OF] {[!]<container>} [FROM <time>] [UNTIL <time>];`
+// Prohibitions created without a name are given one generated from the rest of the statement. Permissions are
+// separated by commas, so the fields before the first permission tell a name apart from the subject:
+// `DENY <name> <subject> <permission>` has one more than `DENY <subject> <permission>`.
 func parseDeny(stmtStr string) (ngac.Statement, error) {
+	stmtStr, validity, err := parseValidity(stmtStr)
+	if err != nil {
+		return nil, err
+	}
+
 	fields := strings.Fields(stmtStr)
-	subject := fields[1]
 
 	index := 2
 	field := ""
@@ -158,19 +407,41 @@ func parseDeny(stmtStr string) (ngac.Statement, error) {
 		}
 	}
 
-	permStr := strings.Join(fields[2:index], " ")
-	split := strings.Split(permStr, ",")
-	ops := make(graph.Operations, 0)
-	for _, s := range split {
-		ops.Add(strings.TrimSpace(s))
+	if !strings.HasPrefix(strings.ToUpper(strings.Join(fields[index:], " ")), "ON") {
+		return nil, fmt.Errorf("DENY statement must have an ON clause")
 	}
 
-	stmtStr = strings.Join(fields[index:], " ")
+	// the fields up to the first comma are the name, PROCESS, the subject and the first permission
+	split := strings.Split(strings.Join(fields[1:index], " "), ",")
+	head := strings.Fields(split[0])
+
+	name := ""
+	if len(head) > 3 && strings.ToUpper(head[1]) == "FOR" {
+		name = head[0]
+		head = head[2:]
+	} else if len(head) == 4 || (len(head) == 3 && strings.ToUpper(head[0]) != "PROCESS") {
+		name = head[0]
+		head = head[1:]
+	}
 
-	if !strings.HasPrefix(strings.ToUpper(stmtStr), "ON") {
-		return nil, fmt.Errorf("DENY statement must have an ON clause")
+	process := false
+	if len(head) == 3 && strings.ToUpper(head[0]) == "PROCESS" {
+		process = true
+		head = head[1:]
 	}
 
+	if len(head) != 2 {
+		return nil, fmt.Errorf("DENY statement must be followed by an optional name, a subject and permissions")
+	}
+
+	subject := head[0]
+	ops := graph.ToOps(head[1])
+	for _, s := range split[1:] {
+		ops.Add(strings.TrimSpace(s))
+	}
+
+	stmtStr = strings.Join(fields[index:], " ")
+
 	inter := false
 	if strings.HasPrefix(strings.ToUpper(stmtStr), "ON INTERSECTION OF") {
 		inter = true
@@ -187,15 +458,33 @@ func parseDeny(stmtStr string) (ngac.Statement, error) {
 	}
 
 	return &ngac.DenyStatement{
+		Name:         name,
 		Subject:      subject,
+		Process:      process,
 		Operations:   ops,
 		Intersection: inter,
 		Containers:   containers,
+		Validity:     validity,
 	}, nil
 }
 
-//`GRANT <user_Attribute> {<permission>} ON <user_or_object_attribute>;`
+//`GRANT <user_Attribute> {<permission>} ON <user_or_object_attribute> [FROM <time>] [UNTIL <time>] [WHEN <condition>];`
 func parseGrant(stmtStr string) (ngac.Statement, error) {
+	condition := ""
+	if loc := whenRegex.FindStringIndex(stmtStr); loc != nil {
+		condition = strings.TrimSpace(stmtStr[loc[1]:])
+		stmtStr = stmtStr[:loc[0]]
+
+		if _, err := expr.Parse(condition); err != nil {
+			return nil, err
+		}
+	}
+
+	stmtStr, validity, err := parseValidity(stmtStr)
+	if err != nil {
+		return nil, err
+	}
+
 	fields := strings.Fields(stmtStr)
 	uattr := fields[1]
 
@@ -227,17 +516,257 @@ func parseGrant(stmtStr string) (ngac.Statement, error) {
 		Uattr:      uattr,
 		Target:     target,
 		Operations: ops,
+		Condition:  condition,
+		Validity:   validity,
 	}, nil
 }
 
+// parseValidity removes the FROM and UNTIL clauses from the end of the statement and returns the validity they
+// describe. Times are RFC 3339 timestamps, optionally without seconds, or dates.
+func parseValidity(stmtStr string) (string, graph.Validity, error) {
+	validity := graph.Validity{}
+	loc := validityRegex.FindStringIndex(stmtStr)
+	if loc == nil {
+		return stmtStr, validity, nil
+	}
+
+	fields := strings.Fields(stmtStr[loc[0]:])
+	for i := 0; i < len(fields); i += 2 {
+		t, err := parseTime(fields[i+1])
+		if err != nil {
+			return "", validity, err
+		}
+
+		keyword := strings.ToUpper(fields[i])
+		if keyword == "FROM" && validity.NotBefore == nil {
+			validity.NotBefore = &t
+		} else if keyword == "UNTIL" && validity.NotAfter == nil {
+			validity.NotAfter = &t
+		} else {
+			return "", validity, fmt.Errorf("duplicate %s clause", keyword)
+		}
+	}
+
+	if validity.NotBefore != nil && validity.NotAfter != nil && validity.NotAfter.Before(*validity.NotBefore) {
+		return "", validity, fmt.Errorf("UNTIL %s is before FROM %s", validity.NotAfter, validity.NotBefore)
+	}
+
+	return stmtStr[:loc[0]], validity, nil
+}
+
+func parseTime(str string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, str); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid time %q, expected a time such as 2026-12-31T00:00Z", str)
+}
+
+// `DELETE|ENABLE|DISABLE OBLIGATION <label>;`
+func parseObligationLifecycle(stmtStr string) (ngac.Statement, error) {
+	fields := strings.Fields(stmtStr)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("expected %s OBLIGATION <label>", strings.ToUpper(fields[0]))
+	}
+
+	label := fields[2]
+	switch strings.ToUpper(fields[0]) {
+	case "DELETE":
+		return &ngac.DeleteObligationStatement{Label: label}, nil
+	case "ENABLE":
+		return &ngac.EnableObligationStatement{Label: label}, nil
+	default:
+		return &ngac.DisableObligationStatement{Label: label}, nil
+	}
+}
+
+// `CONSTRAINT [<name> FOR] SSOD {<user_attribute>} [MAX <n>];`
+// `CONSTRAINT [<name> FOR] DSOD {<operation>};`
+// The max of an ssod constraint defaults to 1.
+func parseConstraint(stmtStr string) (ngac.Statement, error) {
+	fields := strings.Fields(stmtStr)
+
+	name := ""
+	if len(fields) > 3 && strings.ToUpper(fields[2]) == "FOR" {
+		name = fields[1]
+		fields = append([]string{fields[0]}, fields[3:]...)
+	}
+
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("expected CONSTRAINT [<name> FOR] SSOD|DSOD")
+	}
+
+	kind := strings.ToUpper(fields[1])
+	fields = fields[2:]
+
+	switch kind {
+	case "SSOD":
+		max := 1
+		if len(fields) > 2 && strings.ToUpper(fields[len(fields)-2]) == "MAX" {
+			var err error
+			if max, err = strconv.Atoi(fields[len(fields)-1]); err != nil {
+				return nil, fmt.Errorf("invalid max %q: %w", fields[len(fields)-1], err)
+			}
+
+			fields = fields[:len(fields)-2]
+		}
+
+		attrs := splitList(fields)
+		if err := (ngac.SSoD{Name: name, Attributes: attrs, Max: max}).Validate(); err != nil {
+			return nil, err
+		}
+
+		return &ngac.SSoDStatement{
+			Name:       name,
+			Attributes: attrs,
+			Max:        max,
+		}, nil
+	case "DSOD":
+		ops := splitList(fields)
+		if err := (ngac.DSoD{Name: name, Operations: ops}).Validate(); err != nil {
+			return nil, err
+		}
+
+		return &ngac.DSoDStatement{
+			Name:       name,
+			Operations: ops,
+		}, nil
+	default:
+		return nil, fmt.Errorf("expected CONSTRAINT [<name> FOR] SSOD|DSOD")
+	}
+}
+
+// `ASSERT <user> CAN|CANNOT {<operation>} ON <target>;`
+// `ASSERT <child> [NOT] IN <parent>;`
+func parseAssert(stmtStr string) (ngac.Statement, error) {
+	fields := strings.Fields(stmtStr)
+	if len(fields) < 4 {
+		return nil, fmt.Errorf("expected ASSERT <user> CAN|CANNOT {<operation>} ON <target> or ASSERT <child> [NOT] IN <parent>")
+	}
+
+	switch strings.ToUpper(fields[2]) {
+	case "CAN", "CANNOT":
+		if len(fields) < 6 || strings.ToUpper(fields[len(fields)-2]) != "ON" {
+			return nil, fmt.Errorf("expected ASSERT <user> CAN|CANNOT {<operation>} ON <target>")
+		}
+
+		return &ngac.AssertPermissionStatement{
+			User:       fields[1],
+			Operations: graph.ToOps(splitList(fields[3 : len(fields)-2])...),
+			Target:     fields[len(fields)-1],
+			Negated:    strings.ToUpper(fields[2]) == "CANNOT",
+		}, nil
+	case "IN", "NOT":
+		negated := strings.ToUpper(fields[2]) == "NOT"
+		if negated {
+			fields = append(fields[:2], fields[3:]...)
+		}
+
+		if len(fields) != 4 || strings.ToUpper(fields[2]) != "IN" {
+			return nil, fmt.Errorf("expected ASSERT <child> [NOT] IN <parent>")
+		}
+
+		return &ngac.AssertAssignmentStatement{
+			Child:   fields[1],
+			Parent:  fields[3],
+			Negated: negated,
+		}, nil
+	default:
+		return nil, fmt.Errorf("expected ASSERT <user> CAN|CANNOT {<operation>} ON <target> or ASSERT <child> [NOT] IN <parent>")
+	}
+}
+
+// splitList returns the comma separated items in the fields.
+func splitList(fields []string) []string {
+	items := make([]string, 0)
+	for _, item := range strings.Split(strings.Join(fields, " "), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// `DELETE DENY <name>;`
+func parseDeleteDeny(stmtStr string) (ngac.Statement, error) {
+	fields := strings.Fields(stmtStr)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("expected DELETE DENY <name>")
+	}
+
+	return &ngac.DeleteProhibitionStatement{Name: fields[2]}, nil
+}
+
+// `DELETE <node>;`
+// `DELETE OBLIGATION <label>;`
+// `DELETE DENY <name>;`
+// `DELETE SSOD <name>;`
+// `DELETE DSOD <name>;`
+// Only statements with exactly three fields delete an obligation, a prohibition or a constraint, so `DELETE <node>`
+// still deletes nodes named obligation, deny, ssod or dsod.
 func parseDelete(stmtStr string) (ngac.Statement, error) {
 	fields := strings.Fields(stmtStr)
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("expected DELETE <node>")
+	}
+
+	if len(fields) == 3 {
+		switch strings.ToUpper(fields[1]) {
+		case "OBLIGATION":
+			return parseObligationLifecycle(stmtStr)
+		case "DENY":
+			return parseDeleteDeny(stmtStr)
+		case "SSOD", "DSOD":
+			return &ngac.DeleteConstraintStatement{Name: fields[2], Dynamic: strings.ToUpper(fields[1]) == "DSOD"}, nil
+		}
+	}
+
 	target := fields[1]
 	return &ngac.DeleteNodeStatement{
 		Name: target,
 	}, nil
 }
 
+// `UPDATE <name> WITH PROPERTIES {<key>=<value>};`
+// The properties of the node are replaced, so a statement without properties removes all of them.
+func parseUpdate(stmtStr string) (ngac.Statement, error) {
+	fields := strings.Fields(stmtStr)
+	if len(fields) < 4 || strings.ToUpper(fields[2]) != "WITH" || strings.ToUpper(fields[3]) != "PROPERTIES" {
+		return nil, fmt.Errorf("expected UPDATE <name> WITH PROPERTIES {<key>=<value>}")
+	}
+
+	properties := make(map[string]string)
+	for _, prop := range splitList(fields[4:]) {
+		kv := strings.SplitN(prop, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid property %q, expected <key>=<value>", prop)
+		}
+
+		properties[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+	}
+
+	return &ngac.UpdateNodeStatement{
+		Name:       fields[1],
+		Properties: properties,
+	}, nil
+}
+
+// `DISSOCIATE <user_attribute> FROM <target>;`
+func parseDissociate(stmtStr string) (ngac.Statement, error) {
+	fields := strings.Fields(stmtStr)
+	if len(fields) != 4 || strings.ToUpper(fields[2]) != "FROM" {
+		return nil, fmt.Errorf("expected DISSOCIATE <user_attribute> FROM <target>")
+	}
+
+	return &ngac.DissociateStatement{
+		Uattr:  fields[1],
+		Target: fields[3],
+	}, nil
+}
+
 // `DEASSIGN <child> FROM {<parent>};`
 func parseDeassign(stmtStr string) (ngac.Statement, error) {
 	fields := strings.Fields(stmtStr)
@@ -367,12 +896,21 @@ func parseCreateNode(stmtStr string) (ngac.Statement, error) {
 	}, nil
 }
 
-func splitStatements(pal string) []string {
+// splitStatements splits the policy into statements and returns the line each statement starts on.
+func splitStatements(pal string) ([]string, []int) {
 	stmts := make([]string, 0)
+	lines := make([]int, 0)
 	split := strings.Split(pal, ";")
 	parenCounter := 0
 	stmt := ""
+	line, stmtLine := 1, 1
 	for _, s := range split {
+		start := line + strings.Count(s[:len(s)-len(strings.TrimLeft(s, " \t\r\n"))], "\n")
+		line += strings.Count(s, "\n")
+		if stmt == "" {
+			stmtLine = start
+		}
+
 		// add the semi colon back which will help with obligation sub statements
 		s = strings.TrimSpace(s) + ";"
 		if len(s) == 1 {
@@ -391,9 +929,10 @@ func splitStatements(pal string) []string {
 
 		if parenCounter == 0 {
 			stmts = append(stmts, stmt)
+			lines = append(lines, stmtLine)
 			stmt = ""
 		}
 	}
 
-	return stmts
+	return stmts, lines
 }
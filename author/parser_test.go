@@ -3,8 +3,11 @@ package author
 import (
 	"github.com/PM-Master/policy-machine-go/ngac"
 	"github.com/PM-Master/policy-machine-go/ngac/graph"
+	"github.com/PM-Master/policy-machine-go/pip/memory"
 	"github.com/stretchr/testify/require"
+	"strings"
 	"testing"
+	"time"
 )
 
 var testStr = `
@@ -115,6 +118,102 @@ func TestParseDeny(t *testing.T) {
 	require.Equal(t, graph.ToOps("read", "write"), denyStmt.Operations)
 	require.Equal(t, true, denyStmt.Intersection)
 	require.Equal(t, []string{"!oa1", "oa2"}, denyStmt.Containers)
+
+	// with a single field before the permissions the field is the subject, not a name
+	s = "deny no_writes read on oa2"
+	stmt, err = parseDeny(s)
+	require.NoError(t, err)
+	denyStmt = stmt.(*ngac.DenyStatement)
+	require.Equal(t, "", denyStmt.Name)
+	require.Equal(t, "no_writes", denyStmt.Subject)
+
+	s = "deny no_writes for ua1 write on oa2"
+	stmt, err = parseDeny(s)
+	require.NoError(t, err)
+	require.Equal(t, &ngac.DenyStatement{
+		Name:         "no_writes",
+		Subject:      "ua1",
+		Operations:   graph.ToOps("write"),
+		Intersection: false,
+		Containers:   []string{"oa2"},
+	}, stmt)
+
+	// FOR is optional after a name
+	s = "deny no_writes ua1 write, delete on oa2"
+	stmt, err = parseDeny(s)
+	require.NoError(t, err)
+	require.Equal(t, &ngac.DenyStatement{
+		Name:       "no_writes",
+		Subject:    "ua1",
+		Operations: graph.ToOps("write", "delete"),
+		Containers: []string{"oa2"},
+	}, stmt)
+
+	s = "deny session_lock process 1234 read on oa2"
+	stmt, err = parseDeny(s)
+	require.NoError(t, err)
+	require.Equal(t, &ngac.DenyStatement{
+		Name:       "session_lock",
+		Subject:    "1234",
+		Process:    true,
+		Operations: graph.ToOps("read"),
+		Containers: []string{"oa2"},
+	}, stmt)
+
+	_, err = parseDeny("deny a b c d read on oa2")
+	require.Error(t, err)
+
+	s = "deny process 1234 write on oa2"
+	stmt, err = parseDeny(s)
+	require.NoError(t, err)
+	require.Equal(t, &ngac.DenyStatement{
+		Subject:    "1234",
+		Process:    true,
+		Operations: graph.ToOps("write"),
+		Containers: []string{"oa2"},
+	}, stmt)
+
+	s = "deny session_lock for process 1234 read, write on intersection of oa1, !oa2"
+	stmt, err = parseDeny(s)
+	require.NoError(t, err)
+	require.Equal(t, &ngac.DenyStatement{
+		Name:         "session_lock",
+		Subject:      "1234",
+		Process:      true,
+		Operations:   graph.ToOps("read", "write"),
+		Intersection: true,
+		Containers:   []string{"oa1", "!oa2"},
+	}, stmt)
+}
+
+func TestParseDeleteDeny(t *testing.T) {
+	stmts, _, err := Parse("delete deny no_writes; delete oa1;")
+	require.NoError(t, err)
+	require.Equal(t, []ngac.Statement{
+		&ngac.DeleteProhibitionStatement{Name: "no_writes"},
+		&ngac.DeleteNodeStatement{Name: "oa1"},
+	}, stmts)
+
+	// nodes named deny or obligation can still be deleted
+	stmts, _, err = Parse("delete deny; delete obligation; delete deny_oa; delete ssod; delete obligation oa1;")
+	require.NoError(t, err)
+	require.Equal(t, []ngac.Statement{
+		&ngac.DeleteNodeStatement{Name: "deny"},
+		&ngac.DeleteNodeStatement{Name: "obligation"},
+		&ngac.DeleteNodeStatement{Name: "deny_oa"},
+		&ngac.DeleteNodeStatement{Name: "ssod"},
+		&ngac.DeleteObligationStatement{Label: "oa1"},
+	}, stmts)
+
+	stmts, _, err = Parse("delete ssod c1; delete dsod c2;")
+	require.NoError(t, err)
+	require.Equal(t, []ngac.Statement{
+		&ngac.DeleteConstraintStatement{Name: "c1"},
+		&ngac.DeleteConstraintStatement{Name: "c2", Dynamic: true},
+	}, stmts)
+
+	_, err = parseDeleteDeny("delete deny")
+	require.Error(t, err)
 }
 
 func TestParseGrant(t *testing.T) {
@@ -125,6 +224,52 @@ func TestParseGrant(t *testing.T) {
 	require.Equal(t, "ua1", grantStmt.Uattr)
 	require.Equal(t, "oa2", grantStmt.Target)
 	require.Equal(t, graph.ToOps("read", "write"), grantStmt.Operations)
+	require.Empty(t, grantStmt.Condition)
+
+	s = `grant ua1 read on oa2 when user.clearance >= object.level and env.ip in "10.0.0.0/8"`
+	stmt, err = parseGrant(s)
+	require.NoError(t, err)
+	require.Equal(t, &ngac.GrantStatement{
+		Uattr:      "ua1",
+		Target:     "oa2",
+		Operations: graph.ToOps("read"),
+		Condition:  `user.clearance >= object.level and env.ip in "10.0.0.0/8"`,
+	}, stmt)
+
+	_, err = parseGrant("grant ua1 read on oa2 when user.clearance >=")
+	require.Error(t, err)
+
+	s = `grant ua1 read on oa2 from 2026-01-01 until 2026-12-31T00:00Z when env.ip in "10.0.0.0/8"`
+	stmt, err = parseGrant(s)
+	require.NoError(t, err)
+	grantStmt = stmt.(*ngac.GrantStatement)
+	require.Equal(t, "oa2", grantStmt.Target)
+	require.Equal(t, `env.ip in "10.0.0.0/8"`, grantStmt.Condition)
+	require.Equal(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), *grantStmt.NotBefore)
+	require.Equal(t, time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC), *grantStmt.NotAfter)
+
+	_, err = parseGrant("grant ua1 read on oa2 until tomorrow")
+	require.Error(t, err)
+	_, err = parseGrant("grant ua1 read on oa2 from 2026-12-31 until 2026-01-01")
+	require.Error(t, err)
+	_, err = parseGrant("grant ua1 read on oa2 until 2026-01-01 until 2026-02-01")
+	require.Error(t, err)
+}
+
+func TestParseDenyUntil(t *testing.T) {
+	stmt, err := parseDeny("deny p1 for ua1 read on oa1, !oa2 until 2026-12-31T00:00:00Z")
+	require.NoError(t, err)
+	denyStmt := stmt.(*ngac.DenyStatement)
+	require.Equal(t, []string{"oa1", "!oa2"}, denyStmt.Containers)
+	require.Nil(t, denyStmt.NotBefore)
+	require.Equal(t, time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC), *denyStmt.NotAfter)
+
+	pip := memory.NewPIP()
+	require.NoError(t, pip.Graph().CreatePolicyClass("pc1"))
+	require.NoError(t, stmt.Apply(pip))
+	prohibition, err := pip.Prohibitions().GetByName("p1")
+	require.NoError(t, err)
+	require.Equal(t, denyStmt.Validity, prohibition.Validity)
 }
 
 func TestParseAssign(t *testing.T) {
@@ -212,3 +357,141 @@ create policy $x_test_policy;
 	require.Equal(t, 1, len(stmts))
 	require.Equal(t, expected, stmts[0])
 }
+
+func TestParseIf(t *testing.T) {
+	s := `
+if exists $ua then (
+    deassign $ua from pending;
+    assign $ua to active;
+) else if $user in Approvers then (
+    create user attribute $ua in active;
+) else (
+    foreach child in children of $target do (
+        assign $child to inactive;
+    );
+);
+`
+	stmts, _, err := Parse(s)
+	require.NoError(t, err)
+	require.Equal(t, 1, len(stmts))
+
+	ifStmt := stmts[0].(*ngac.IfStatement)
+	require.Equal(t, &ngac.ExistsCondition{Name: "$ua"}, ifStmt.Condition)
+	require.Equal(t, []ngac.Statement{
+		&ngac.DeassignStatement{Child: "$ua", Parents: []string{"pending"}},
+		&ngac.AssignStatement{Child: "$ua", Parents: []string{"active"}},
+	}, ifStmt.Then)
+	require.Equal(t, 1, len(ifStmt.Else))
+
+	elseIf := ifStmt.Else[0].(*ngac.IfStatement)
+	require.Equal(t, &ngac.InCondition{Child: "$user", Parent: "Approvers"}, elseIf.Condition)
+	require.Equal(t, 1, len(elseIf.Then))
+	require.Equal(t, []ngac.Statement{
+		&ngac.ForeachStatement{
+			Variable: "child",
+			Relation: ngac.Children,
+			Node:     "$target",
+			Body:     []ngac.Statement{&ngac.AssignStatement{Child: "$child", Parents: []string{"inactive"}}},
+		},
+	}, elseIf.Else)
+}
+
+func TestParseCondition(t *testing.T) {
+	condition, err := parseCondition(strings.Fields("not exists ua1"))
+	require.NoError(t, err)
+	require.Equal(t, &ngac.NotCondition{Condition: &ngac.ExistsCondition{Name: "ua1"}}, condition)
+
+	condition, err = parseCondition(strings.Fields("u1 IN ua1"))
+	require.NoError(t, err)
+	require.Equal(t, &ngac.InCondition{Child: "u1", Parent: "ua1"}, condition)
+
+	_, err = parseCondition(strings.Fields("u1 under ua1"))
+	require.Error(t, err)
+}
+
+func TestParseForeachErrors(t *testing.T) {
+	_, err := parseForeach("foreach child in siblings of oa1 do (assign $child to oa2;)")
+	require.Error(t, err)
+
+	_, err = parseForeach("foreach child in children of oa1 (assign $child to oa2;)")
+	require.Error(t, err)
+}
+
+func TestParseObligationLifecycle(t *testing.T) {
+	s := `
+disable obligation obl1;
+enable obligation obl1;
+delete obligation obl1;
+`
+	stmts, _, err := Parse(s)
+	require.NoError(t, err)
+	require.Equal(t, []ngac.Statement{
+		&ngac.DisableObligationStatement{Label: "obl1"},
+		&ngac.EnableObligationStatement{Label: "obl1"},
+		&ngac.DeleteObligationStatement{Label: "obl1"},
+	}, stmts)
+
+	_, err = parseObligationLifecycle("delete obligation")
+	require.Error(t, err)
+}
+
+func TestParseEmit(t *testing.T) {
+	stmt, err := parseEmit("emit account_created on $target with name=$name, owner = $user")
+	require.NoError(t, err)
+	require.Equal(t, &ngac.EmitStatement{
+		Event:  "account_created",
+		Target: "$target",
+		Args:   map[string]string{"name": "$name", "owner": "$user"},
+	}, stmt)
+
+	stmt, err = parseEmit("emit reindex")
+	require.NoError(t, err)
+	require.Equal(t, &ngac.EmitStatement{Event: "reindex", Args: map[string]string{}}, stmt)
+
+	_, err = parseEmit("emit reindex with name")
+	require.Error(t, err)
+	_, err = parseEmit("emit reindex to oa1")
+	require.Error(t, err)
+}
+
+func TestParseConstraint(t *testing.T) {
+	stmts, _, err := Parse(`constraint ssod Requester, Approver max 1;
+constraint c1 for ssod a, b, c max 2;
+constraint ssod x, y;
+constraint dsod create, approve;
+constraint d1 for dsod submit, review, sign;`)
+	require.NoError(t, err)
+	require.Equal(t, []ngac.Statement{
+		&ngac.SSoDStatement{Attributes: []string{"Requester", "Approver"}, Max: 1},
+		&ngac.SSoDStatement{Name: "c1", Attributes: []string{"a", "b", "c"}, Max: 2},
+		&ngac.SSoDStatement{Attributes: []string{"x", "y"}, Max: 1},
+		&ngac.DSoDStatement{Operations: []string{"create", "approve"}},
+		&ngac.DSoDStatement{Name: "d1", Operations: []string{"submit", "review", "sign"}},
+	}, stmts)
+
+	for _, s := range []string{"constraint ssod a max 1", "constraint ssod a, b max 2", "constraint ssod a, b max x", "constraint a, b", "constraint dsod create"} {
+		_, err = parseConstraint(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestParseAssert(t *testing.T) {
+	stmts, lines, _, err := parse(`# assertions
+assert u1 can read, write on o1;
+assert u2 cannot write on o1;
+
+assert u1 in ua1; assert u1 not in ua2;`)
+	require.NoError(t, err)
+	require.Equal(t, []ngac.Statement{
+		&ngac.AssertPermissionStatement{User: "u1", Operations: graph.ToOps("read", "write"), Target: "o1"},
+		&ngac.AssertPermissionStatement{User: "u2", Operations: graph.ToOps("write"), Target: "o1", Negated: true},
+		&ngac.AssertAssignmentStatement{Child: "u1", Parent: "ua1"},
+		&ngac.AssertAssignmentStatement{Child: "u1", Parent: "ua2", Negated: true},
+	}, stmts)
+	require.Equal(t, []int{2, 3, 5, 5}, lines)
+
+	for _, s := range []string{"assert u1 can read", "assert u1 may read on o1", "assert u1 in", "assert u1 not ua1", "assert u1 in ua1 ua2"} {
+		_, err = parseAssert(s)
+		require.Error(t, err, s)
+	}
+}
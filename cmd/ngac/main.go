@@ -0,0 +1,354 @@
+// Command ngac analyzes policies written in the policy author language.
+//
+//	ngac analyze escalation <policy.ngac>
+//	ngac analyze redundancy <policy.ngac>
+//	ngac simulate -policy <policy.ngac> <change.ngac>
+//	ngac test <path>...
+//	ngac diff [-pal] <old> <new>
+//	ngac reconcile [-dry-run] [-prune] <policy.json> <desired.ngac>
+//
+// simulate reports the permissions users gain and lose if the change is applied to the policy.
+//
+// test evaluates the assert statements of policy files. A path is a .ngac file, a directory of .ngac files or a
+// directory followed by /... to include its subdirectories.
+//
+// diff compares two policies, each a .json graph snapshot or a policy file, and prints the differences or, with -pal,
+// the statements that transform the old policy into the new one.
+//
+// reconcile converges the policy snapshot to the policy file, creating the snapshot if it does not exist, and prints
+// the statements it applied. With -dry-run the snapshot is not written and with -prune the nodes, prohibitions,
+// obligations and constraints that are not in the policy file are removed. If a statement fails the snapshot is not
+// changed.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/PM-Master/policy-machine-go/analysis"
+	"github.com/PM-Master/policy-machine-go/author"
+	"github.com/PM-Master/policy-machine-go/diff"
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/pip/memory"
+	"github.com/PM-Master/policy-machine-go/simulate"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const usage = `usage:
+  ngac analyze escalation <policy.ngac>
+  ngac analyze redundancy <policy.ngac>
+  ngac simulate -policy <policy.ngac> <change.ngac>
+  ngac test <path>...
+  ngac diff [-pal] <old> <new>
+  ngac reconcile [-dry-run] [-prune] <policy.json> <desired.ngac>`
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, out io.Writer) error {
+	if len(args) < 1 {
+		return fmt.Errorf(usage)
+	}
+
+	switch args[0] {
+	case "analyze":
+		return analyze(args[1:], out)
+	case "simulate":
+		return simulateChange(args[1:], out)
+	case "test":
+		return test(args[1:], out)
+	case "diff":
+		return compare(args[1:], out)
+	case "reconcile":
+		return reconcile(args[1:], out)
+	default:
+		return fmt.Errorf("unknown command %q\n%s", args[0], usage)
+	}
+}
+
+func analyze(args []string, out io.Writer) error {
+	if len(args) != 2 {
+		return fmt.Errorf(usage)
+	}
+
+	fe, err := load(args[1])
+	if err != nil {
+		return err
+	}
+
+	switch args[0] {
+	case "escalation":
+		escalations, err := analysis.Escalations(fe)
+		if err != nil {
+			return err
+		}
+
+		if len(escalations) == 0 {
+			fmt.Fprintln(out, "no user can escalate privileges")
+		}
+
+		for _, escalation := range escalations {
+			fmt.Fprint(out, escalation.String())
+		}
+
+		return nil
+	case "redundancy":
+		findings, err := analysis.Redundancies(fe)
+		if err != nil {
+			return err
+		}
+
+		if len(findings) == 0 {
+			fmt.Fprintln(out, "no redundancies found")
+		}
+
+		for _, finding := range findings {
+			fmt.Fprintln(out, finding.String())
+		}
+
+		return nil
+	default:
+		return fmt.Errorf("unknown analysis %q\n%s", args[0], usage)
+	}
+}
+
+func simulateChange(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("simulate", flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	policy := flags.String("policy", "", "the policy the change is applied to")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 1 || *policy == "" {
+		return fmt.Errorf(usage)
+	}
+
+	fe, err := load(*policy)
+	if err != nil {
+		return err
+	}
+
+	changes, err := simulate.SimulateFile(fe, flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if len(changes) == 0 {
+		fmt.Fprintln(out, "no permissions change")
+	}
+
+	for _, change := range changes {
+		fmt.Fprintln(out, change.String())
+	}
+
+	return nil
+}
+
+func test(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf(usage)
+	}
+
+	files, err := policyFiles(args)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, file := range files {
+		report, err := author.RunTests(file)
+		if err != nil {
+			return err
+		}
+
+		for _, result := range report.Results {
+			if !result.Passed {
+				failed++
+				fmt.Fprintf(out, "FAIL %s:%d: %s: %s\n", result.File, result.Line, result.Assertion, result.Message)
+			}
+		}
+
+		status := "ok  "
+		if !report.Passed() {
+			status = "FAIL"
+		}
+
+		fmt.Fprintf(out, "%s %s: %d assertions, %d of %d associations exercised\n", status, file, len(report.Results),
+			len(report.Exercised), len(report.Exercised)+len(report.Unexercised))
+		for _, assoc := range report.Unexercised {
+			fmt.Fprintf(out, "     not exercised: %s on %s\n", assoc.Subject, assoc.Target)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d assertions failed", failed)
+	}
+
+	return nil
+}
+
+// policyFiles returns the .ngac files of the paths in order.
+func policyFiles(paths []string) ([]string, error) {
+	files := make([]string, 0)
+	for _, path := range paths {
+		recursive := strings.HasSuffix(path, "/...")
+		if recursive {
+			path = strings.TrimSuffix(path, "/...")
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		err = filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			if info.IsDir() && file != path && !recursive {
+				return filepath.SkipDir
+			}
+
+			if !info.IsDir() && filepath.Ext(file) == ".ngac" {
+				files = append(files, file)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return files, nil
+}
+
+func compare(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	pal := flags.Bool("pal", false, "print the statements that transform the old policy into the new one")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 2 {
+		return fmt.Errorf(usage)
+	}
+
+	old, err := load(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	current, err := load(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	d, err := diff.Compare(old, current)
+	if err != nil {
+		return err
+	}
+
+	if !*pal {
+		fmt.Fprint(out, d.String())
+		return nil
+	}
+
+	script, err := d.PAL()
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprint(out, script)
+	return nil
+}
+
+func reconcile(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("reconcile", flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	dryRun := flags.Bool("dry-run", false, "print the statements without writing the snapshot")
+	prune := flags.Bool("prune", false, "remove the policy elements that are not in the policy file")
+	if err := flags.Parse(args); err != nil || flags.NArg() != 2 {
+		return fmt.Errorf(usage)
+	}
+
+	path := flags.Arg(0)
+	fe := memory.NewPIP()
+	if _, err := os.Stat(path); err == nil {
+		if fe, err = load(path); err != nil {
+			return err
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	plan, err := diff.Reconcile(fe, flags.Arg(1), diff.ReconcileOptions{DryRun: *dryRun, Prune: *prune})
+	if err != nil {
+		return err
+	}
+
+	script, err := author.Format(plan)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprint(out, script)
+	if *dryRun {
+		return nil
+	}
+
+	snapshot, err := memory.Marshal(fe)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(path, snapshot, 0644)
+}
+
+// load reads the policy at the given path into an in memory policy. Files with a .json extension are policy or graph
+// snapshots, other files are policy files.
+func load(path string) (ngac.FunctionalEntity, error) {
+	pip := memory.NewPIP()
+	if filepath.Ext(path) == ".json" {
+		snapshot, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if isPolicySnapshot(snapshot) {
+			return memory.Unmarshal(snapshot)
+		}
+
+		if err = pip.Graph().UnmarshalJSON(snapshot); err != nil {
+			return nil, fmt.Errorf("error reading snapshot %q: %w", path, err)
+		}
+
+		return pip, nil
+	}
+
+	a := author.New(pip)
+	if err := a.ReadAndApply(path); err != nil {
+		return nil, err
+	}
+
+	return pip, nil
+}
+
+// isPolicySnapshot returns true if the snapshot was written by memory.Marshal rather than by a graph.
+func isPolicySnapshot(snapshot []byte) bool {
+	fields := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(snapshot, &fields); err != nil {
+		return false
+	}
+
+	_, ok := fields["graph"]
+	return ok
+}
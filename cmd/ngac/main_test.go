@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writePolicy(t *testing.T, pal string) string {
+	dir, err := ioutil.TempDir("", "ngac")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "policy.ngac")
+	require.NoError(t, ioutil.WriteFile(path, []byte(pal), 0644))
+
+	return path
+}
+
+func TestAnalyze(t *testing.T) {
+	path := writePolicy(t, `
+create policy pc1;
+create user attribute Users in pc1;
+create user attribute Admins in pc1;
+create object attribute secret in pc1;
+create user u1 in Users;
+grant Users assign on Users;
+grant Users assign_to on Admins;
+grant Admins read on secret;
+`)
+
+	out := &bytes.Buffer{}
+	require.NoError(t, run([]string{"analyze", "escalation", path}, out))
+	require.Contains(t, out.String(), "u1 can escalate privileges:\n  1. u1 assigns Users to Admins\n")
+	require.Contains(t, out.String(), "gains read on secret\n")
+
+	out.Reset()
+	require.NoError(t, run([]string{"analyze", "redundancy", path}, out))
+	require.Equal(t, "no redundancies found\n", out.String())
+
+	require.Error(t, run([]string{"analyze", "unknown", path}, out))
+	require.Error(t, run([]string{"unknown"}, out))
+}
+
+func TestSimulate(t *testing.T) {
+	policy := writePolicy(t, `
+create policy pc1;
+create user attribute ua1 in pc1;
+create object attribute oa1 in pc1;
+create user u1 in ua1;
+create object o1 in oa1;
+`)
+	change := writePolicy(t, `grant ua1 read on oa1;`)
+
+	out := &bytes.Buffer{}
+	require.NoError(t, run([]string{"simulate", "-policy", policy, change}, out))
+	require.Equal(t, "u1 on o1: +read\nu1 on oa1: +read\n", out.String())
+
+	// the policy is required, even for a change that applies to an empty policy
+	standalone := writePolicy(t, `create policy pc2;`)
+	require.Error(t, run([]string{"simulate", standalone}, out))
+	require.Error(t, run([]string{"simulate"}, out))
+}
+
+func TestTest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ngac")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "a.ngac"), []byte(`
+create policy pc1;
+create user attribute ua1 in pc1;
+create user u1 in ua1;
+assert u1 in ua1;
+`), 0644))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "sub", "b.ngac"), []byte(`
+create policy pc1;
+create user attribute ua1 in pc1;
+create user u1 in ua1;
+assert u1 not in ua1;
+`), 0644))
+
+	out := &bytes.Buffer{}
+	require.NoError(t, run([]string{"test", dir}, out))
+	require.Equal(t, fmt.Sprintf("ok   %s: 1 assertions, 0 of 0 associations exercised\n", filepath.Join(dir, "a.ngac")), out.String())
+
+	out.Reset()
+	require.EqualError(t, run([]string{"test", dir + "/..."}, out), "1 assertions failed")
+	require.Contains(t, out.String(), fmt.Sprintf("FAIL %s:5: u1 not in ua1: u1 is contained in ua1\n", filepath.Join(dir, "sub", "b.ngac")))
+}
+
+func TestDiff(t *testing.T) {
+	old := writePolicy(t, `
+create policy pc1;
+create user attribute ua1 in pc1;
+create object attribute oa1 in pc1;
+`)
+	current := writePolicy(t, `
+create policy pc1;
+create user attribute ua1 in pc1;
+create object attribute oa1 in pc1;
+grant ua1 read on oa1;
+`)
+
+	out := &bytes.Buffer{}
+	require.NoError(t, run([]string{"diff", old, current}, out))
+	require.Equal(t, "+ association ua1 -> oa1 read\n", out.String())
+
+	out.Reset()
+	require.NoError(t, run([]string{"diff", "-pal", old, current}, out))
+	require.Equal(t, "grant ua1 read on oa1;\n", out.String())
+}
+
+func TestReconcile(t *testing.T) {
+	desired := writePolicy(t, `
+create policy pc1;
+create user attribute ua1 in pc1;
+create object attribute oa1 in pc1;
+grant ua1 read on oa1;
+deny d1 for ua1 write on oa1;
+`)
+	snapshot := filepath.Join(filepath.Dir(desired), "policy.json")
+
+	out := &bytes.Buffer{}
+	require.NoError(t, run([]string{"reconcile", "-dry-run", snapshot, desired}, out))
+	require.Contains(t, out.String(), "create policy pc1;\n")
+	_, err := os.Stat(snapshot)
+	require.True(t, os.IsNotExist(err))
+
+	out.Reset()
+	require.NoError(t, run([]string{"reconcile", snapshot, desired}, out))
+	require.Contains(t, out.String(), "deny d1 for ua1 write on oa1;\n")
+
+	out.Reset()
+	require.NoError(t, run([]string{"reconcile", snapshot, desired}, out))
+	require.Empty(t, out.String())
+}
@@ -0,0 +1,859 @@
+// Package diff compares two policies and produces the statements that transform one into the other.
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/PM-Master/policy-machine-go/author"
+	"github.com/PM-Master/policy-machine-go/internal/sets"
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
+	"sort"
+	"strings"
+	"time"
+)
+
+type (
+	// Diff holds the differences between an old and a new policy. Every slice is sorted by name, except that added
+	// and changed obligations are in the order the new policy applies them, which ObligationOrder records. A node
+	// whose kind changed is reported as removed and added, which the statements of the diff cannot apply. A changed
+	// constraint is also reported as removed and added.
+	Diff struct {
+		AddedNodes          []graph.Node        `json:"addedNodes,omitempty"`
+		RemovedNodes        []graph.Node        `json:"removedNodes,omitempty"`
+		ChangedNodes        []NodeChange        `json:"changedNodes,omitempty"`
+		AddedAssignments    []Assignment        `json:"addedAssignments,omitempty"`
+		RemovedAssignments  []Assignment        `json:"removedAssignments,omitempty"`
+		AddedAssociations   []Association       `json:"addedAssociations,omitempty"`
+		RemovedAssociations []Association       `json:"removedAssociations,omitempty"`
+		ChangedAssociations []AssociationChange `json:"changedAssociations,omitempty"`
+		AddedProhibitions   []ngac.Prohibition  `json:"addedProhibitions,omitempty"`
+		RemovedProhibitions []ngac.Prohibition  `json:"removedProhibitions,omitempty"`
+		ChangedProhibitions []ProhibitionChange `json:"changedProhibitions,omitempty"`
+		AddedObligations    []ngac.Obligation   `json:"addedObligations,omitempty"`
+		RemovedObligations  []ngac.Obligation   `json:"removedObligations,omitempty"`
+		ChangedObligations  []ObligationChange  `json:"changedObligations,omitempty"`
+		// ObligationOrder holds the labels of the obligations of the new policy in the order they are applied. It is
+		// only set if obligations were added or changed.
+		ObligationOrder []string    `json:"obligationOrder,omitempty"`
+		AddedSSoD       []ngac.SSoD `json:"addedSSoD,omitempty"`
+		RemovedSSoD     []ngac.SSoD `json:"removedSSoD,omitempty"`
+		AddedDSoD       []ngac.DSoD `json:"addedDSoD,omitempty"`
+		RemovedDSoD     []ngac.DSoD `json:"removedDSoD,omitempty"`
+	}
+
+	// NodeChange is a change to the properties of a node.
+	NodeChange struct {
+		Name   string            `json:"name"`
+		Before map[string]string `json:"before"`
+		After  map[string]string `json:"after"`
+	}
+
+	Assignment struct {
+		Child  string `json:"child"`
+		Parent string `json:"parent"`
+	}
+
+	Association struct {
+		Subject    string           `json:"subject"`
+		Target     string           `json:"target"`
+		Operations graph.Operations `json:"operations"`
+		Condition  string           `json:"condition,omitempty"`
+		graph.Validity
+	}
+
+	// AssociationChange is a change to the operations, condition or validity of an association.
+	AssociationChange struct {
+		Before     Association `json:"before"`
+		After      Association `json:"after"`
+		AddedOps   []string    `json:"addedOps,omitempty"`
+		RemovedOps []string    `json:"removedOps,omitempty"`
+	}
+
+	ProhibitionChange struct {
+		Before ngac.Prohibition `json:"before"`
+		After  ngac.Prohibition `json:"after"`
+	}
+
+	// ObligationChange is a change to an obligation. Moved is true if the obligation is applied in a different order
+	// relative to the obligations with the same priority, which is changed by removing and adding it again.
+	ObligationChange struct {
+		Before ngac.Obligation `json:"before"`
+		After  ngac.Obligation `json:"after"`
+		Moved  bool            `json:"moved,omitempty"`
+	}
+
+	// state holds the parts of a policy that are compared.
+	state struct {
+		nodes        map[string]graph.Node
+		assignments  map[Assignment]bool
+		associations map[string]Association
+		prohibitions map[string]ngac.Prohibition
+		obligations  map[string]ngac.Obligation
+		// order holds the labels of the obligations in the order they are applied
+		order []string
+		ssod  map[string]ngac.SSoD
+		dsod  map[string]ngac.DSoD
+	}
+)
+
+// Compare returns the differences between the old policy, from, and the new policy, to.
+func Compare(from ngac.FunctionalEntity, to ngac.FunctionalEntity) (Diff, error) {
+	before, err := readState(from)
+	if err != nil {
+		return Diff{}, fmt.Errorf("error reading old policy: %w", err)
+	}
+
+	after, err := readState(to)
+	if err != nil {
+		return Diff{}, fmt.Errorf("error reading new policy: %w", err)
+	}
+
+	d := Diff{}
+	names := make(map[string]bool)
+	for name := range before.nodes {
+		names[name] = true
+	}
+	for name := range after.nodes {
+		names[name] = true
+	}
+
+	for _, name := range sets.Sorted(names) {
+		b, inBefore := before.nodes[name]
+		a, inAfter := after.nodes[name]
+		switch {
+		case !inBefore:
+			d.AddedNodes = append(d.AddedNodes, a)
+		case !inAfter:
+			d.RemovedNodes = append(d.RemovedNodes, b)
+		case a.Kind != b.Kind:
+			d.RemovedNodes = append(d.RemovedNodes, b)
+			d.AddedNodes = append(d.AddedNodes, a)
+		case !equalProperties(a.Properties, b.Properties):
+			d.ChangedNodes = append(d.ChangedNodes, NodeChange{Name: name, Before: b.Properties, After: a.Properties})
+		}
+	}
+
+	for assignment := range after.assignments {
+		if !before.assignments[assignment] {
+			d.AddedAssignments = append(d.AddedAssignments, assignment)
+		}
+	}
+
+	for assignment := range before.assignments {
+		if !after.assignments[assignment] {
+			d.RemovedAssignments = append(d.RemovedAssignments, assignment)
+		}
+	}
+
+	sortAssignments(d.AddedAssignments)
+	sortAssignments(d.RemovedAssignments)
+
+	keys := make(map[string]bool)
+	for key := range before.associations {
+		keys[key] = true
+	}
+	for key := range after.associations {
+		keys[key] = true
+	}
+
+	for _, key := range sets.Sorted(keys) {
+		b, inBefore := before.associations[key]
+		a, inAfter := after.associations[key]
+		switch {
+		case !inBefore:
+			d.AddedAssociations = append(d.AddedAssociations, a)
+		case !inAfter:
+			d.RemovedAssociations = append(d.RemovedAssociations, b)
+		case !equalAssociations(a, b):
+			d.ChangedAssociations = append(d.ChangedAssociations, AssociationChange{
+				Before:     b,
+				After:      a,
+				AddedOps:   missingOps(a.Operations, b.Operations),
+				RemovedOps: missingOps(b.Operations, a.Operations),
+			})
+		}
+	}
+
+	names = make(map[string]bool)
+	for name := range before.prohibitions {
+		names[name] = true
+	}
+	for name := range after.prohibitions {
+		names[name] = true
+	}
+
+	for _, name := range sets.Sorted(names) {
+		b, inBefore := before.prohibitions[name]
+		a, inAfter := after.prohibitions[name]
+		switch {
+		case !inBefore:
+			d.AddedProhibitions = append(d.AddedProhibitions, a)
+		case !inAfter:
+			d.RemovedProhibitions = append(d.RemovedProhibitions, b)
+		default:
+			equal, err := equalJSON(&a, &b)
+			if err != nil {
+				return Diff{}, err
+			}
+
+			if !equal {
+				d.ChangedProhibitions = append(d.ChangedProhibitions, ProhibitionChange{Before: b, After: a})
+			}
+		}
+	}
+
+	if err = compareObligations(&d, before, after); err != nil {
+		return Diff{}, err
+	}
+
+	names = make(map[string]bool)
+	for name := range before.ssod {
+		names[name] = true
+	}
+	for name := range after.ssod {
+		names[name] = true
+	}
+
+	for _, name := range sets.Sorted(names) {
+		b, inBefore := before.ssod[name]
+		a, inAfter := after.ssod[name]
+		equal, err := equalJSON(&a, &b)
+		if err != nil {
+			return Diff{}, err
+		}
+
+		if inBefore && (!inAfter || !equal) {
+			d.RemovedSSoD = append(d.RemovedSSoD, b)
+		}
+		if inAfter && (!inBefore || !equal) {
+			d.AddedSSoD = append(d.AddedSSoD, a)
+		}
+	}
+
+	names = make(map[string]bool)
+	for name := range before.dsod {
+		names[name] = true
+	}
+	for name := range after.dsod {
+		names[name] = true
+	}
+
+	for _, name := range sets.Sorted(names) {
+		b, inBefore := before.dsod[name]
+		a, inAfter := after.dsod[name]
+		equal, err := equalJSON(&a, &b)
+		if err != nil {
+			return Diff{}, err
+		}
+
+		if inBefore && (!inAfter || !equal) {
+			d.RemovedDSoD = append(d.RemovedDSoD, b)
+		}
+		if inAfter && (!inBefore || !equal) {
+			d.AddedDSoD = append(d.AddedDSoD, a)
+		}
+	}
+
+	return d, nil
+}
+
+func readState(fe ngac.FunctionalEntity) (state, error) {
+	g := fe.Graph()
+	nodes, err := g.GetNodes()
+	if err != nil {
+		return state{}, err
+	}
+
+	s := state{
+		nodes:        nodes,
+		assignments:  make(map[Assignment]bool),
+		associations: make(map[string]Association),
+		prohibitions: make(map[string]ngac.Prohibition),
+		obligations:  make(map[string]ngac.Obligation),
+		ssod:         make(map[string]ngac.SSoD),
+		dsod:         make(map[string]ngac.DSoD),
+	}
+
+	assignments, err := g.GetAssignments()
+	if err != nil {
+		return state{}, err
+	}
+
+	for child, parents := range assignments {
+		for parent := range parents {
+			s.assignments[Assignment{Child: child, Parent: parent}] = true
+		}
+	}
+
+	assocs, err := g.GetAssociations()
+	if err != nil {
+		return state{}, err
+	}
+
+	for subject, targets := range assocs {
+		conditions, err := g.GetAssociationConditions(subject)
+		if err != nil {
+			return state{}, err
+		}
+
+		validity, err := g.GetAssociationValidity(subject)
+		if err != nil {
+			return state{}, err
+		}
+
+		for target, ops := range targets {
+			s.associations[subject+"\x00"+target] = Association{
+				Subject:    subject,
+				Target:     target,
+				Operations: ops,
+				Condition:  conditions[target],
+				Validity:   validity[target],
+			}
+		}
+	}
+
+	prohibitions, err := fe.Prohibitions().All()
+	if err != nil {
+		return state{}, err
+	}
+
+	for _, prohibition := range prohibitions {
+		s.prohibitions[prohibition.Name] = prohibition
+	}
+
+	obligations, err := fe.Obligations().All()
+	if err != nil {
+		return state{}, err
+	}
+
+	for _, obligation := range obligations {
+		s.obligations[obligation.Label] = obligation
+		s.order = append(s.order, obligation.Label)
+	}
+
+	ssod, err := fe.Constraints().GetSSoD()
+	if err != nil {
+		return state{}, err
+	}
+
+	for _, constraint := range ssod {
+		s.ssod[constraint.Name] = constraint
+	}
+
+	dsod, err := fe.Constraints().GetDSoD()
+	if err != nil {
+		return state{}, err
+	}
+
+	for _, constraint := range dsod {
+		s.dsod[constraint.Name] = constraint
+	}
+
+	return s, nil
+}
+
+// compareObligations adds the obligations that were added, removed and changed to the diff. The obligations of the new
+// policy are added again in order, after the obligations that are kept, so an obligation that is kept must be in the
+// same order relative to the other kept obligations with the same priority and before every obligation with the same
+// priority that is added again. An obligation that does not meet this is reported as moved.
+func compareObligations(d *Diff, before state, after state) error {
+	removed := make(map[string]bool)
+	for label := range before.obligations {
+		if _, ok := after.obligations[label]; !ok {
+			removed[label] = true
+		}
+	}
+
+	for _, label := range sets.Sorted(removed) {
+		d.RemovedObligations = append(d.RemovedObligations, before.obligations[label])
+	}
+
+	position := make(map[string]int, len(before.order))
+	for i, label := range before.order {
+		position[label] = i
+	}
+
+	// last is the position in the old policy of the last obligation that is kept with the current priority and
+	// readding is true once an obligation with the current priority is added again
+	last, readding := -1, false
+	for i, label := range after.order {
+		a := after.obligations[label]
+		if i == 0 || a.Priority != after.obligations[after.order[i-1]].Priority {
+			last, readding = -1, false
+		}
+
+		b, ok := before.obligations[label]
+		if !ok {
+			d.AddedObligations = append(d.AddedObligations, a)
+			readding = true
+			continue
+		}
+
+		equal, err := equalJSON(&a, &b)
+		if err != nil {
+			return err
+		}
+
+		// an obligation that is only enabled or disabled is changed in place
+		enabled := b
+		enabled.Disabled = a.Disabled
+		kept, err := equalJSON(&a, &enabled)
+		if err != nil {
+			return err
+		}
+
+		moved := kept && (readding || position[label] < last)
+		switch {
+		case moved:
+			d.ChangedObligations = append(d.ChangedObligations, ObligationChange{Before: b, After: a, Moved: true})
+		case !equal:
+			d.ChangedObligations = append(d.ChangedObligations, ObligationChange{Before: b, After: a})
+		}
+
+		if kept && !moved {
+			last = position[label]
+		} else {
+			readding = true
+		}
+	}
+
+	if len(d.AddedObligations)+len(d.ChangedObligations) > 0 {
+		d.ObligationOrder = append([]string{}, after.order...)
+	}
+
+	return nil
+}
+
+// Empty returns true if the policies are the same.
+func (d Diff) Empty() bool {
+	return len(d.AddedNodes)+len(d.RemovedNodes)+len(d.ChangedNodes)+
+		len(d.AddedAssignments)+len(d.RemovedAssignments)+
+		len(d.AddedAssociations)+len(d.RemovedAssociations)+len(d.ChangedAssociations)+
+		len(d.AddedProhibitions)+len(d.RemovedProhibitions)+len(d.ChangedProhibitions)+
+		len(d.AddedObligations)+len(d.RemovedObligations)+len(d.ChangedObligations)+
+		len(d.AddedSSoD)+len(d.RemovedSSoD)+len(d.AddedDSoD)+len(d.RemovedDSoD) == 0
+}
+
+// Statements returns the statements that transform the old policy into the new one. Prohibitions and obligations
+// are removed first and added last so they never refer to nodes that do not exist. Nodes are created before the
+// assignments and associations that refer to them and deleted after them, parents before children when created and
+// children before parents when deleted. Changed prohibitions and obligations are deleted and added again. Constraints
+// are removed before and added after everything else so they do not reject the assignments in between.
+func (d Diff) Statements() []ngac.Statement {
+	stmts := make([]ngac.Statement, 0)
+	for _, constraint := range d.RemovedSSoD {
+		stmts = append(stmts, &ngac.DeleteConstraintStatement{Name: constraint.Name})
+	}
+
+	for _, constraint := range d.RemovedDSoD {
+		stmts = append(stmts, &ngac.DeleteConstraintStatement{Name: constraint.Name, Dynamic: true})
+	}
+
+	for _, prohibition := range d.RemovedProhibitions {
+		stmts = append(stmts, &ngac.DeleteProhibitionStatement{Name: prohibition.Name})
+	}
+
+	for _, change := range d.ChangedProhibitions {
+		stmts = append(stmts, &ngac.DeleteProhibitionStatement{Name: change.Before.Name})
+	}
+
+	for _, obligation := range d.RemovedObligations {
+		stmts = append(stmts, &ngac.DeleteObligationStatement{Label: obligation.Label})
+	}
+
+	changedObligations := make([]ngac.Obligation, 0)
+	for _, change := range d.ChangedObligations {
+		before, after := change.Before, change.After
+		before.Disabled = after.Disabled
+		if equal, _ := equalJSON(&before, &after); equal && !change.Moved {
+			continue
+		}
+
+		stmts = append(stmts, &ngac.DeleteObligationStatement{Label: change.Before.Label})
+		changedObligations = append(changedObligations, change.After)
+	}
+
+	added := make(map[string]bool)
+	for _, node := range d.AddedNodes {
+		added[node.Name] = true
+	}
+
+	removed := make(map[string]bool)
+	for _, node := range d.RemovedNodes {
+		removed[node.Name] = true
+	}
+
+	// create the added nodes with the parents they are assigned to in the new policy
+	addedParents := parentsOf(d.AddedAssignments)
+	for _, node := range order(d.AddedNodes, addedParents) {
+		if node.Kind == graph.PolicyClass {
+			stmts = append(stmts, &ngac.CreatePolicyStatement{Name: node.Name})
+			continue
+		}
+
+		stmts = append(stmts, &ngac.CreateNodeStatement{
+			Name:       node.Name,
+			Kind:       node.Kind,
+			Properties: node.Properties,
+			Parents:    addedParents[node.Name],
+		})
+	}
+
+	for _, change := range d.ChangedNodes {
+		stmts = append(stmts, &ngac.UpdateNodeStatement{Name: change.Name, Properties: change.After})
+	}
+
+	for _, child := range sortedChildren(addedParents) {
+		if !added[child] {
+			stmts = append(stmts, &ngac.AssignStatement{Child: child, Parents: addedParents[child]})
+		}
+	}
+
+	grants := append([]Association{}, d.AddedAssociations...)
+	for _, change := range d.ChangedAssociations {
+		grants = append(grants, change.After)
+	}
+	sortAssociations(grants)
+
+	for _, assoc := range grants {
+		stmts = append(stmts, &ngac.GrantStatement{
+			Uattr:      assoc.Subject,
+			Target:     assoc.Target,
+			Operations: assoc.Operations,
+			Condition:  assoc.Condition,
+			Validity:   assoc.Validity,
+		})
+	}
+
+	// associations and assignments of deleted nodes are removed when the nodes are deleted
+	for _, assoc := range d.RemovedAssociations {
+		if !removed[assoc.Subject] && !removed[assoc.Target] {
+			stmts = append(stmts, &ngac.DissociateStatement{Uattr: assoc.Subject, Target: assoc.Target})
+		}
+	}
+
+	removedParents := parentsOf(d.RemovedAssignments)
+	for _, child := range sortedChildren(removedParents) {
+		if !removed[child] {
+			stmts = append(stmts, &ngac.DeassignStatement{Child: child, Parents: removedParents[child]})
+		}
+	}
+
+	deleted := order(d.RemovedNodes, removedParents)
+	for i := len(deleted) - 1; i >= 0; i-- {
+		stmts = append(stmts, &ngac.DeleteNodeStatement{Name: deleted[i].Name})
+	}
+
+	prohibitions := append([]ngac.Prohibition{}, d.AddedProhibitions...)
+	for _, change := range d.ChangedProhibitions {
+		prohibitions = append(prohibitions, change.After)
+	}
+	sort.Slice(prohibitions, func(i, j int) bool {
+		return prohibitions[i].Name < prohibitions[j].Name
+	})
+
+	for _, prohibition := range prohibitions {
+		stmts = append(stmts, ngac.DenyStatementFor(prohibition))
+	}
+
+	// obligations are added in the order of the new policy so obligations with the same priority are applied in the
+	// same order
+	obligations := append(append([]ngac.Obligation{}, d.AddedObligations...), changedObligations...)
+	order := make(map[string]int, len(d.ObligationOrder))
+	for i, label := range d.ObligationOrder {
+		order[label] = i
+	}
+	sort.SliceStable(obligations, func(i, j int) bool {
+		return order[obligations[i].Label] < order[obligations[j].Label]
+	})
+
+	for _, obligation := range obligations {
+		stmts = append(stmts, &ngac.ObligationStatement{Obligation: obligation})
+		if obligation.Disabled {
+			stmts = append(stmts, &ngac.DisableObligationStatement{Label: obligation.Label})
+		}
+	}
+
+	// obligations that were not added again only changed whether they are enabled
+	readded := make(map[string]bool)
+	for _, obligation := range changedObligations {
+		readded[obligation.Label] = true
+	}
+
+	for _, change := range d.ChangedObligations {
+		if readded[change.After.Label] {
+			continue
+		}
+
+		if change.After.Disabled {
+			stmts = append(stmts, &ngac.DisableObligationStatement{Label: change.After.Label})
+		} else {
+			stmts = append(stmts, &ngac.EnableObligationStatement{Label: change.After.Label})
+		}
+	}
+
+	for _, constraint := range d.AddedSSoD {
+		stmts = append(stmts, &ngac.SSoDStatement{Name: constraint.Name, Attributes: constraint.Attributes, Max: constraint.Max})
+	}
+
+	for _, constraint := range d.AddedDSoD {
+		stmts = append(stmts, &ngac.DSoDStatement{Name: constraint.Name, Operations: constraint.Operations})
+	}
+
+	return stmts
+}
+
+// PAL returns the statements of the diff in the policy author language.
+func (d Diff) PAL() (string, error) {
+	return author.Format(d.Statements())
+}
+
+// String formats the diff with a line for each difference prefixed by +, - or ~ for added, removed and changed.
+func (d Diff) String() string {
+	b := strings.Builder{}
+	for _, node := range d.AddedNodes {
+		fmt.Fprintf(&b, "+ node %s %s%s\n", node.Kind, node.Name, formatProperties(node.Properties))
+	}
+	for _, node := range d.RemovedNodes {
+		fmt.Fprintf(&b, "- node %s %s\n", node.Kind, node.Name)
+	}
+	for _, change := range d.ChangedNodes {
+		fmt.Fprintf(&b, "~ node %s%s ->%s\n", change.Name, formatProperties(change.Before), formatProperties(change.After))
+	}
+	for _, assignment := range d.AddedAssignments {
+		fmt.Fprintf(&b, "+ assignment %s -> %s\n", assignment.Child, assignment.Parent)
+	}
+	for _, assignment := range d.RemovedAssignments {
+		fmt.Fprintf(&b, "- assignment %s -> %s\n", assignment.Child, assignment.Parent)
+	}
+	for _, assoc := range d.AddedAssociations {
+		fmt.Fprintf(&b, "+ association %s -> %s %s\n", assoc.Subject, assoc.Target, formatOps(assoc.Operations))
+	}
+	for _, assoc := range d.RemovedAssociations {
+		fmt.Fprintf(&b, "- association %s -> %s %s\n", assoc.Subject, assoc.Target, formatOps(assoc.Operations))
+	}
+	for _, change := range d.ChangedAssociations {
+		fmt.Fprintf(&b, "~ association %s -> %s", change.After.Subject, change.After.Target)
+		for _, op := range change.AddedOps {
+			fmt.Fprintf(&b, " +%s", op)
+		}
+		for _, op := range change.RemovedOps {
+			fmt.Fprintf(&b, " -%s", op)
+		}
+		if change.Before.Condition != change.After.Condition {
+			fmt.Fprintf(&b, " condition %q -> %q", change.Before.Condition, change.After.Condition)
+		}
+		if !equalValidity(change.Before.Validity, change.After.Validity) {
+			fmt.Fprint(&b, " validity changed")
+		}
+		fmt.Fprintln(&b)
+	}
+	for _, prohibition := range d.AddedProhibitions {
+		fmt.Fprintf(&b, "+ prohibition %s\n", prohibition.Name)
+	}
+	for _, prohibition := range d.RemovedProhibitions {
+		fmt.Fprintf(&b, "- prohibition %s\n", prohibition.Name)
+	}
+	for _, change := range d.ChangedProhibitions {
+		fmt.Fprintf(&b, "~ prohibition %s\n", change.After.Name)
+	}
+	for _, obligation := range d.AddedObligations {
+		fmt.Fprintf(&b, "+ obligation %s\n", obligation.Label)
+	}
+	for _, obligation := range d.RemovedObligations {
+		fmt.Fprintf(&b, "- obligation %s\n", obligation.Label)
+	}
+	for _, change := range d.ChangedObligations {
+		if change.Moved {
+			fmt.Fprintf(&b, "~ obligation %s (moved)\n", change.After.Label)
+		} else {
+			fmt.Fprintf(&b, "~ obligation %s\n", change.After.Label)
+		}
+	}
+	for _, constraint := range d.AddedSSoD {
+		fmt.Fprintf(&b, "+ ssod %s %s max %d\n", constraint.Name, strings.Join(constraint.Attributes, ", "), constraint.Max)
+	}
+	for _, constraint := range d.RemovedSSoD {
+		fmt.Fprintf(&b, "- ssod %s\n", constraint.Name)
+	}
+	for _, constraint := range d.AddedDSoD {
+		fmt.Fprintf(&b, "+ dsod %s %s\n", constraint.Name, strings.Join(constraint.Operations, ", "))
+	}
+	for _, constraint := range d.RemovedDSoD {
+		fmt.Fprintf(&b, "- dsod %s\n", constraint.Name)
+	}
+
+	return b.String()
+}
+
+// parentsOf groups the parents of the assignments by child.
+func parentsOf(assignments []Assignment) map[string][]string {
+	parents := make(map[string][]string)
+	for _, assignment := range assignments {
+		parents[assignment.Child] = append(parents[assignment.Child], assignment.Parent)
+	}
+
+	return parents
+}
+
+// order sorts the nodes so that each node comes after those of its parents that are in nodes.
+func order(nodes []graph.Node, parents map[string][]string) []graph.Node {
+	pending := make(map[string]bool)
+	for _, node := range nodes {
+		pending[node.Name] = true
+	}
+
+	ordered := make([]graph.Node, 0, len(nodes))
+	for len(ordered) < len(nodes) {
+		progress := false
+		for _, node := range nodes {
+			if !pending[node.Name] {
+				continue
+			}
+
+			ready := true
+			for _, parent := range parents[node.Name] {
+				ready = ready && !pending[parent]
+			}
+
+			if ready {
+				ordered = append(ordered, node)
+				delete(pending, node.Name)
+				progress = true
+			}
+		}
+
+		// the graph has a cycle, append the remaining nodes as they are
+		if !progress {
+			for _, node := range nodes {
+				if pending[node.Name] {
+					ordered = append(ordered, node)
+				}
+			}
+			break
+		}
+	}
+
+	return ordered
+}
+
+func equalProperties(a map[string]string, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for key, value := range a {
+		if other, ok := b[key]; !ok || other != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+func equalAssociations(a Association, b Association) bool {
+	return len(missingOps(a.Operations, b.Operations)) == 0 &&
+		len(missingOps(b.Operations, a.Operations)) == 0 &&
+		a.Condition == b.Condition &&
+		equalValidity(a.Validity, b.Validity)
+}
+
+func equalValidity(a graph.Validity, b graph.Validity) bool {
+	return equalTime(a.NotBefore, b.NotBefore) && equalTime(a.NotAfter, b.NotAfter)
+}
+
+func equalTime(a *time.Time, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Equal(*b)
+}
+
+func equalJSON(a interface{}, b interface{}) (bool, error) {
+	aBytes, err := json.Marshal(a)
+	if err != nil {
+		return false, err
+	}
+
+	bBytes, err := json.Marshal(b)
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(aBytes, bBytes), nil
+}
+
+// missingOps returns the operations in ops that are not in other, sorted.
+func missingOps(ops graph.Operations, other graph.Operations) []string {
+	missing := make([]string, 0)
+	for op := range ops {
+		if !other[op] {
+			missing = append(missing, op)
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	sort.Strings(missing)
+	return missing
+}
+
+func formatOps(ops graph.Operations) string {
+	sorted := make([]string, 0, len(ops))
+	for op := range ops {
+		sorted = append(sorted, op)
+	}
+	sort.Strings(sorted)
+
+	return strings.Join(sorted, ", ")
+}
+
+func formatProperties(properties map[string]string) string {
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	str := ""
+	for _, key := range keys {
+		str += fmt.Sprintf(" %s=%s", key, properties[key])
+	}
+
+	return str
+}
+
+func sortedChildren(parents map[string][]string) []string {
+	children := make([]string, 0, len(parents))
+	for child := range parents {
+		children = append(children, child)
+	}
+	sort.Strings(children)
+
+	return children
+}
+
+func sortAssignments(assignments []Assignment) {
+	sort.Slice(assignments, func(i, j int) bool {
+		if assignments[i].Child != assignments[j].Child {
+			return assignments[i].Child < assignments[j].Child
+		}
+
+		return assignments[i].Parent < assignments[j].Parent
+	})
+}
+
+func sortAssociations(assocs []Association) {
+	sort.Slice(assocs, func(i, j int) bool {
+		if assocs[i].Subject != assocs[j].Subject {
+			return assocs[i].Subject < assocs[j].Subject
+		}
+
+		return assocs[i].Target < assocs[j].Target
+	})
+}
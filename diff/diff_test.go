@@ -0,0 +1,209 @@
+package diff
+
+import (
+	"github.com/PM-Master/policy-machine-go/author"
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/pip/memory"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func newPolicy(t *testing.T, pal string) ngac.FunctionalEntity {
+	stmts, _, err := author.Parse(pal)
+	require.NoError(t, err)
+
+	pip := memory.NewPIP()
+	for _, stmt := range stmts {
+		require.NoError(t, stmt.Apply(pip))
+	}
+
+	return pip
+}
+
+func TestCompare(t *testing.T) {
+	old := newPolicy(t, `
+create policy pc1;
+create user attribute ua1 in pc1;
+create user attribute ua2 in pc1;
+create object attribute oa1 in pc1;
+create object attribute oa2 in pc1;
+create object attribute removed in pc1;
+create object o1 with properties owner=u1 in oa1;
+create object o2 in removed;
+create user u1 in ua1, ua2;
+grant ua1 read on oa1;
+grant ua2 read on oa2;
+grant ua2 write on removed;
+deny d1 for ua1 write on oa1;
+deny d2 for ua2 write on oa2;
+obligation o1 when any_user performs read on oa1 do (
+	create object copy in oa2;
+);
+obligation o2 when any_user performs write do (
+	create object copy2 in oa2;
+);
+`)
+	current := newPolicy(t, `
+create policy pc1;
+create policy pc2;
+create user attribute ua1 in pc1;
+create user attribute ua2 in pc1;
+create object attribute oa1 in pc1;
+create object attribute oa2 in pc1;
+create object attribute oa3 in oa2, pc2;
+create object o1 with properties owner=u2 in oa1, oa3;
+create object o3 in oa3;
+create user u1 in ua1;
+grant ua1 read, write on oa1;
+grant ua1 read on oa3 when env.network == "internal";
+deny d1 for ua1 delete on oa1;
+deny d3 for ua1 write on oa3;
+obligation o1 when any_user performs read on oa1 do (
+	create object copy in oa3;
+);
+obligation o2 when any_user performs write do (
+	create object copy2 in oa2;
+);
+disable obligation o2;
+`)
+
+	d, err := Compare(old, current)
+	require.NoError(t, err)
+	require.Equal(t, `+ node O o3
++ node OA oa3
++ node PC pc2
+- node O o2
+- node OA removed
+~ node o1 owner=u1 -> owner=u2
++ assignment o1 -> oa3
++ assignment o3 -> oa3
++ assignment oa3 -> oa2
++ assignment oa3 -> pc2
+- assignment o2 -> removed
+- assignment removed -> pc1
+- assignment u1 -> ua2
++ association ua1 -> oa3 read
+- association ua2 -> oa2 read
+- association ua2 -> removed write
+~ association ua1 -> oa1 +write
++ prohibition d3
+- prohibition d2
+~ prohibition d1
+~ obligation o1
+~ obligation o2 (moved)
+`, d.String())
+
+	pal, err := d.PAL()
+	require.NoError(t, err)
+	require.Equal(t, `delete deny d2;
+delete deny d1;
+delete obligation o1;
+delete obligation o2;
+create policy pc2;
+create object attribute oa3 in oa2, pc2;
+create object o3 in oa3;
+update o1 with properties owner=u2;
+assign o1 to oa3;
+grant ua1 read, write on oa1;
+grant ua1 read on oa3 when env.network == "internal";
+dissociate ua2 from oa2;
+deassign u1 from ua2;
+delete o2;
+delete removed;
+deny d1 for ua1 delete on oa1;
+deny d3 for ua1 write on oa3;
+obligation o1 when any_user performs read on oa1 do (
+	create object copy in oa3;
+);
+obligation o2 when any_user performs write do (
+	create object copy2 in oa2;
+);
+disable obligation o2;
+`, pal)
+
+	// applying the statements to the old policy results in the new policy
+	for _, stmt := range d.Statements() {
+		require.NoError(t, stmt.Apply(old))
+	}
+
+	d, err = Compare(old, current)
+	require.NoError(t, err)
+	require.True(t, d.Empty(), d.String())
+}
+
+func TestCompareObligationOrder(t *testing.T) {
+	old := newPolicy(t, `
+create policy pc1;
+create object attribute oa1 in pc1;
+obligation a when any_user performs read do (
+	create object a in oa1;
+);
+obligation b when any_user performs read do (
+	create object b in oa1;
+);
+obligation c when any_user performs read do (
+	create object c in oa1;
+);
+obligation urgent priority 1 when any_user performs read do (
+	create object urgent in oa1;
+);
+`)
+	// only the order of the obligations with the same priority changed
+	current := newPolicy(t, `
+create policy pc1;
+create object attribute oa1 in pc1;
+obligation urgent priority 1 when any_user performs read do (
+	create object urgent in oa1;
+);
+obligation a when any_user performs read do (
+	create object a in oa1;
+);
+obligation c when any_user performs read do (
+	create object c in oa1;
+);
+obligation b when any_user performs read do (
+	create object b in oa1;
+);
+`)
+
+	d, err := Compare(old, current)
+	require.NoError(t, err)
+	require.Equal(t, "~ obligation b (moved)\n", d.String())
+	require.Equal(t, []string{"urgent", "a", "c", "b"}, d.ObligationOrder)
+
+	for _, stmt := range d.Statements() {
+		require.NoError(t, stmt.Apply(old))
+	}
+
+	obligations, err := old.Obligations().All()
+	require.NoError(t, err)
+	labels := make([]string, 0)
+	for _, obligation := range obligations {
+		labels = append(labels, obligation.Label)
+	}
+	require.Equal(t, []string{"urgent", "a", "c", "b"}, labels)
+
+	d, err = Compare(old, current)
+	require.NoError(t, err)
+	require.True(t, d.Empty(), d.String())
+}
+
+func TestCompareSnapshots(t *testing.T) {
+	policy := newPolicy(t, `
+create policy pc1;
+create user attribute ua1 in pc1;
+create object attribute oa1 in pc1;
+grant ua1 read on oa1;
+`)
+
+	snapshot, err := policy.Graph().MarshalJSON()
+	require.NoError(t, err)
+
+	restored := memory.NewPIP()
+	require.NoError(t, restored.Graph().UnmarshalJSON(snapshot))
+
+	d, err := Compare(policy, restored)
+	require.NoError(t, err)
+	require.True(t, d.Empty())
+	require.Empty(t, d.Statements())
+}
@@ -0,0 +1,148 @@
+package diff
+
+import (
+	"fmt"
+	"github.com/PM-Master/policy-machine-go/author"
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/pip/memory"
+)
+
+// ReconcileOptions configures how a policy is reconciled with a desired policy.
+type ReconcileOptions struct {
+	// DryRun returns the plan without applying it.
+	DryRun bool
+	// Prune removes the nodes, assignments, associations, prohibitions, obligations and constraints that are not in
+	// the desired policy. Without it only the relations between nodes of the desired policy are removed.
+	Prune bool
+}
+
+// Reconcile converges the policy to the state described by the policy file at the given path and returns the
+// statements it applied, or would apply for a dry run. Reconciling a policy that is already in the desired state
+// applies nothing. The plan is applied to a copy of the policy first, so if any statement fails the policy is left
+// unchanged.
+func Reconcile(fe ngac.FunctionalEntity, path string, options ReconcileOptions) ([]ngac.Statement, error) {
+	desired := memory.NewPIP()
+	a := author.New(desired)
+	if err := a.ReadAndApply(path); err != nil {
+		return nil, err
+	}
+
+	return ReconcilePolicy(fe, desired, options)
+}
+
+// ReconcilePolicy converges the policy to the desired policy. See Reconcile.
+func ReconcilePolicy(fe ngac.FunctionalEntity, desired ngac.FunctionalEntity, options ReconcileOptions) ([]ngac.Statement, error) {
+	d, err := Compare(fe, desired)
+	if err != nil {
+		return nil, err
+	}
+
+	removed := make(map[string]bool)
+	for _, node := range d.RemovedNodes {
+		removed[node.Name] = true
+	}
+
+	for _, node := range d.AddedNodes {
+		if removed[node.Name] {
+			return nil, fmt.Errorf("node %q cannot be reconciled because its type changed", node.Name)
+		}
+	}
+
+	if !options.Prune {
+		d = managed(d, removed)
+	}
+
+	plan := d.Statements()
+	if options.DryRun {
+		return plan, nil
+	}
+
+	before, err := memory.Marshal(fe)
+	if err != nil {
+		return nil, err
+	}
+
+	trial, err := memory.Unmarshal(before)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = apply(trial, plan); err != nil {
+		return nil, err
+	}
+
+	// the plan applies cleanly so apply it to the policy, restoring the policy should it still fail part way
+	if err = apply(fe, plan); err != nil {
+		if restoreErr := memory.Restore(fe, before); restoreErr != nil {
+			return nil, fmt.Errorf("%v; error restoring policy: %w", err, restoreErr)
+		}
+
+		return nil, err
+	}
+
+	return plan, nil
+}
+
+func apply(fe ngac.FunctionalEntity, plan []ngac.Statement) error {
+	for _, stmt := range plan {
+		if err := stmt.Apply(fe); err != nil {
+			pal, _ := author.Format([]ngac.Statement{stmt})
+			return fmt.Errorf("error applying %q: %w", pal, err)
+		}
+	}
+
+	return nil
+}
+
+// managed removes the differences that only concern the nodes, prohibitions, obligations and constraints that are not
+// in the desired policy.
+func managed(d Diff, unmanaged map[string]bool) Diff {
+	d.RemovedNodes = nil
+	d.RemovedProhibitions = nil
+	d.RemovedObligations = nil
+
+	// constraints that changed are removed and added again
+	added := make(map[string]bool)
+	for _, constraint := range d.AddedSSoD {
+		added[constraint.Name] = true
+	}
+
+	ssod := make([]ngac.SSoD, 0)
+	for _, constraint := range d.RemovedSSoD {
+		if added[constraint.Name] {
+			ssod = append(ssod, constraint)
+		}
+	}
+	d.RemovedSSoD = ssod
+
+	added = make(map[string]bool)
+	for _, constraint := range d.AddedDSoD {
+		added[constraint.Name] = true
+	}
+
+	dsod := make([]ngac.DSoD, 0)
+	for _, constraint := range d.RemovedDSoD {
+		if added[constraint.Name] {
+			dsod = append(dsod, constraint)
+		}
+	}
+	d.RemovedDSoD = dsod
+
+	assignments := make([]Assignment, 0)
+	for _, assignment := range d.RemovedAssignments {
+		if !unmanaged[assignment.Child] && !unmanaged[assignment.Parent] {
+			assignments = append(assignments, assignment)
+		}
+	}
+	d.RemovedAssignments = assignments
+
+	assocs := make([]Association, 0)
+	for _, assoc := range d.RemovedAssociations {
+		if !unmanaged[assoc.Subject] && !unmanaged[assoc.Target] {
+			assocs = append(assocs, assoc)
+		}
+	}
+	d.RemovedAssociations = assocs
+
+	return d
+}
@@ -0,0 +1,202 @@
+package diff
+
+import (
+	"github.com/PM-Master/policy-machine-go/author"
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
+	"github.com/PM-Master/policy-machine-go/pip/memory"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReconcile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reconcile")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "desired.ngac")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`
+create policy pc1;
+create user attribute ua1 in pc1;
+create user attribute ua2 in pc1;
+create object attribute oa1 in pc1;
+create user u1 in ua1;
+grant ua1 read, write on oa1;
+deny d1 for ua2 write on oa1;
+obligation zeta when any_user performs write on oa1 do (
+	create object zeta in oa1;
+);
+obligation alpha when any_user performs write on oa1 do (
+	create object alpha in oa1;
+);
+`), 0644))
+
+	fe := newPolicy(t, `
+create policy pc1;
+create user attribute ua1 in pc1;
+create user attribute ua2 in pc1;
+create object attribute oa1 in pc1;
+create object attribute unmanaged in pc1;
+create user u1 in ua1, ua2;
+grant ua1 read on oa1;
+grant ua2 read on oa1;
+grant ua1 read on unmanaged;
+deny d2 for ua1 read on oa1;
+obligation alpha when any_user performs write on oa1 do (
+	create object alpha in oa1;
+);
+obligation zeta when any_user performs write on oa1 do (
+	create object zeta in oa1;
+);
+`)
+
+	plan, err := Reconcile(fe, path, ReconcileOptions{DryRun: true})
+	require.NoError(t, err)
+	pal, err := author.Format(plan)
+	require.NoError(t, err)
+	require.Equal(t, `delete obligation alpha;
+grant ua1 read, write on oa1;
+dissociate ua2 from oa1;
+deassign u1 from ua2;
+deny d1 for ua2 write on oa1;
+obligation alpha when any_user performs write on oa1 do (
+	create object alpha in oa1;
+);
+`, pal)
+
+	// a dry run does not change the policy
+	_, err = fe.Prohibitions().GetByName("d1")
+	require.Error(t, err)
+
+	applied, err := Reconcile(fe, path, ReconcileOptions{})
+	require.NoError(t, err)
+	require.Equal(t, plan, applied)
+
+	// obligations with the same priority are applied in the order of the desired policy
+	obligations, err := fe.Obligations().All()
+	require.NoError(t, err)
+	require.Len(t, obligations, 2)
+	require.Equal(t, "zeta", obligations[0].Label)
+	require.Equal(t, "alpha", obligations[1].Label)
+
+	// reconciling again applies nothing
+	plan, err = Reconcile(fe, path, ReconcileOptions{})
+	require.NoError(t, err)
+	require.Empty(t, plan)
+
+	// unmanaged nodes, prohibitions and the associations with them are only removed when pruning
+	ok, err := fe.Graph().Exists("unmanaged")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	plan, err = Reconcile(fe, path, ReconcileOptions{Prune: true})
+	require.NoError(t, err)
+	pal, err = author.Format(plan)
+	require.NoError(t, err)
+	require.Equal(t, `delete deny d2;
+delete unmanaged;
+`, pal)
+
+	desired := newPolicy(t, string(mustRead(t, path)))
+	d, err := Compare(fe, desired)
+	require.NoError(t, err)
+	require.True(t, d.Empty(), d.String())
+}
+
+func TestReconcileChangedKind(t *testing.T) {
+	fe := newPolicy(t, `
+create policy pc1;
+create object attribute a1 in pc1;
+`)
+	desired := newPolicy(t, `
+create policy pc1;
+create user attribute a1 in pc1;
+`)
+
+	_, err := ReconcilePolicy(fe, desired, ReconcileOptions{Prune: true})
+	require.Error(t, err)
+
+	node, err := fe.Graph().GetNode("a1")
+	require.NoError(t, err)
+	require.Equal(t, graph.ObjectAttribute, node.Kind)
+}
+
+func mustRead(t *testing.T, path string) []byte {
+	bytes, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	return bytes
+}
+
+func TestReconcileConstraints(t *testing.T) {
+	fe := newPolicy(t, `
+create policy pc1;
+create user attribute ua1 in pc1;
+create user attribute ua2 in pc1;
+create user attribute ua3 in pc1;
+constraint c1 for dsod create, approve;
+constraint c2 for dsod submit, review;
+`)
+	desired := newPolicy(t, `
+create policy pc1;
+create user attribute ua1 in pc1;
+create user attribute ua2 in pc1;
+create user attribute ua3 in pc1;
+constraint s1 for ssod ua1, ua2 max 1;
+constraint c1 for dsod create, approve, delete;
+`)
+
+	plan, err := ReconcilePolicy(fe, desired, ReconcileOptions{})
+	require.NoError(t, err)
+	pal, err := author.Format(plan)
+	require.NoError(t, err)
+	require.Equal(t, `delete dsod c1;
+constraint s1 for ssod ua1, ua2 max 1;
+constraint c1 for dsod create, approve, delete;
+`, pal)
+
+	// the constraint that is not in the desired policy is only removed when pruning
+	plan, err = ReconcilePolicy(fe, desired, ReconcileOptions{Prune: true})
+	require.NoError(t, err)
+	pal, err = author.Format(plan)
+	require.NoError(t, err)
+	require.Equal(t, "delete dsod c2;\n", pal)
+
+	d, err := Compare(fe, desired)
+	require.NoError(t, err)
+	require.True(t, d.Empty(), d.String())
+}
+
+func TestReconcileFailureLeavesPolicyUnchanged(t *testing.T) {
+	fe := newPolicy(t, `
+create policy pc1;
+create user attribute ua1 in pc1;
+create user attribute ua2 in pc1;
+constraint c1 for ssod ua1, ua2 max 1;
+obligation o1 when any_user performs submit do (
+	create object attribute submitted in pc1;
+);
+`)
+	// the existing constraint is not managed by the desired policy so it rejects u1
+	desired := newPolicy(t, `
+create policy pc1;
+create user attribute ua1 in pc1;
+create user attribute ua2 in pc1;
+create user u1 in ua1, ua2;
+obligation o1 when any_user performs approve do (
+	create object attribute approved in pc1;
+);
+`)
+
+	before, err := memory.Marshal(fe)
+	require.NoError(t, err)
+
+	_, err = ReconcilePolicy(fe, desired, ReconcileOptions{})
+	require.Error(t, err)
+
+	after, err := memory.Marshal(fe)
+	require.NoError(t, err)
+	require.JSONEq(t, string(before), string(after))
+}
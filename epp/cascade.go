@@ -0,0 +1,268 @@
+package epp
+
+import (
+	"errors"
+	"fmt"
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"reflect"
+	"sort"
+	"time"
+)
+
+const (
+	DefaultMaxCascadeDepth = 10
+	DefaultMaxActions      = 1000
+)
+
+var (
+	// ErrMaxCascadeDepth is returned when events emitted by responses are nested deeper than Options.MaxCascadeDepth.
+	ErrMaxCascadeDepth = errors.New("maximum event cascade depth exceeded")
+	// ErrActionBudgetExceeded is returned when processing an event applies more than Options.MaxActions actions.
+	ErrActionBudgetExceeded = errors.New("response action budget exceeded")
+	// ErrEventLoop is returned when a response emits an event that is already being processed.
+	ErrEventLoop = errors.New("event emitted while it is already being processed")
+	// ErrObligationLoop is returned when a response creates an obligation that would create itself again.
+	ErrObligationLoop = errors.New("obligation creates itself")
+)
+
+type (
+	// Options limit the work done by the EPP for a single event.
+	Options struct {
+		// MaxCascadeDepth is the maximum depth of events emitted by responses. The event passed
+		// to ProcessEvent has a depth of 0. If 0, DefaultMaxCascadeDepth is used.
+		MaxCascadeDepth int
+		// MaxActions is the maximum number of response actions applied for an event, including
+		// the actions of cascading events. If 0, DefaultMaxActions is used.
+		MaxActions int
+		// History, if not nil, records the user, operation and target of each event passed to
+		// ProcessEvent. Events emitted by responses are not recorded.
+		History ngac.History
+		// Clock returns the time recorded for each event in History. If nil, the current time is used.
+		Clock func() time.Time
+	}
+
+	// cascade holds the state of processing an event and the events emitted by its responses.
+	cascade struct {
+		pap     ngac.FunctionalEntity
+		options Options
+		actions int
+		// events and obligations are the stacks of events and obligations currently being processed
+		events      []EventContext
+		obligations []ngac.Obligation
+	}
+)
+
+func DefaultOptions() Options {
+	return Options{
+		MaxCascadeDepth: DefaultMaxCascadeDepth,
+		MaxActions:      DefaultMaxActions,
+	}
+}
+
+func (c *cascade) processEvent(eventCtx EventContext) error {
+	if len(c.events) > c.options.MaxCascadeDepth {
+		return fmt.Errorf("error processing event %q: %w (%d)", eventCtx.Event, ErrMaxCascadeDepth, c.options.MaxCascadeDepth)
+	}
+
+	for _, evt := range c.events {
+		if evt.Event == eventCtx.Event && reflect.DeepEqual(evt.args(), eventCtx.args()) {
+			return fmt.Errorf("error processing event %q on %q: %w", eventCtx.Event, eventCtx.Target, ErrEventLoop)
+		}
+	}
+
+	c.events = append(c.events, eventCtx)
+	defer func() {
+		c.events = c.events[:len(c.events)-1]
+	}()
+
+	obligations, err := c.pap.Obligations().All()
+	if err != nil {
+		return fmt.Errorf("error getting obligations from PAP: %w", err)
+	}
+
+	for _, obligation := range obligations {
+		if obligation.Disabled {
+			continue
+		}
+
+		var matches bool
+		matches, err = eventCtx.Matches(obligation.Event)
+		if err != nil {
+			return fmt.Errorf("error matching event pattern: %w", err)
+		}
+
+		if !matches {
+			continue
+		}
+
+		c.obligations = append(c.obligations, obligation)
+		err = c.applyActions(obligation.Response.Actions, eventCtx.args())
+		c.obligations = c.obligations[:len(c.obligations)-1]
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// applyActions resolves the args in a copy of each action and applies it to the PAP.
+// Conditional and foreach statements are evaluated against the current state of the graph.
+func (c *cascade) applyActions(actions []ngac.Statement, args map[string]string) error {
+	actions, err := ngac.CopyStatements(actions)
+	if err != nil {
+		return fmt.Errorf("error copying response actions: %w", err)
+	}
+
+	for _, action := range actions {
+		action, err = resolveHead(action, args)
+		if err != nil {
+			return fmt.Errorf("error resolving args: %w", err)
+		}
+
+		switch stmt := action.(type) {
+		case *ngac.IfStatement:
+			var ok bool
+			if ok, err = stmt.Condition.Evaluate(c.pap.Graph()); err != nil {
+				return fmt.Errorf("error evaluating condition: %w", err)
+			}
+
+			branch := stmt.Else
+			if ok {
+				branch = stmt.Then
+			}
+
+			err = c.applyActions(branch, args)
+		case *ngac.ForeachStatement:
+			err = c.applyForeach(stmt, args)
+		case *ngac.EmitStatement:
+			err = c.emit(stmt)
+		case *ngac.ObligationStatement:
+			if err = c.checkObligation(stmt.Obligation); err == nil {
+				err = c.apply(action)
+			}
+		default:
+			err = c.apply(action)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resolveHead resolves the args of a response action except for the statements in the body of an if or foreach
+// statement. Those are resolved when they are applied, so a value that contains '$' is not resolved again.
+func resolveHead(action ngac.Statement, args map[string]string) (ngac.Statement, error) {
+	switch stmt := action.(type) {
+	case *ngac.IfStatement:
+		var err error
+		if stmt.Condition, err = resolveCondition(stmt.Condition, args); err != nil {
+			return nil, fmt.Errorf("error resolving args for if statement: %w", err)
+		}
+
+		return stmt, nil
+	case *ngac.ForeachStatement:
+		stmt.Node = replaceArgs(stmt.Node, args)
+		return stmt, nil
+	default:
+		return resolveArgs(action, args)
+	}
+}
+
+func (c *cascade) apply(action ngac.Statement) error {
+	if err := c.spend(); err != nil {
+		return err
+	}
+
+	if err := action.Apply(c.pap); err != nil {
+		return fmt.Errorf("error applying response action: %w", err)
+	}
+
+	return nil
+}
+
+func (c *cascade) spend() error {
+	c.actions++
+	if c.actions > c.options.MaxActions {
+		return fmt.Errorf("%w (%d)", ErrActionBudgetExceeded, c.options.MaxActions)
+	}
+
+	return nil
+}
+
+func (c *cascade) applyForeach(stmt *ngac.ForeachStatement, args map[string]string) error {
+	nodes, err := stmt.Nodes(c.pap.Graph())
+	if err != nil {
+		return fmt.Errorf("error getting %s of %q: %w", stmt.Relation, stmt.Node, err)
+	}
+
+	names := make([]string, 0, len(nodes))
+	for name := range nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		iterArgs := copyArgs(args)
+		iterArgs[stmt.Variable] = name
+
+		if err = c.applyActions(stmt.Body, iterArgs); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// emit processes an event emitted by a response on behalf of the user of the event that triggered it.
+func (c *cascade) emit(stmt *ngac.EmitStatement) error {
+	if err := c.spend(); err != nil {
+		return err
+	}
+
+	return c.processEvent(EventContext{
+		User:   c.events[len(c.events)-1].User,
+		Event:  stmt.Event,
+		Target: stmt.Target,
+		Args:   stmt.Args,
+	})
+}
+
+// checkObligation returns an error if the obligation would recreate itself each time it is
+// triggered. This is the case when it has the same event pattern as an obligation currently being
+// processed and its response creates another obligation with that same event pattern.
+func (c *cascade) checkObligation(created ngac.Obligation) error {
+	for _, obligation := range c.obligations {
+		if reflect.DeepEqual(obligation.Event, created.Event) &&
+			createsObligationFor(created.Response.Actions, created.Event) {
+			return fmt.Errorf("error creating obligation %q from %q: %w", created.Label, obligation.Label, ErrObligationLoop)
+		}
+	}
+
+	return nil
+}
+
+func createsObligationFor(actions []ngac.Statement, event ngac.EventPattern) bool {
+	for _, action := range actions {
+		switch stmt := action.(type) {
+		case *ngac.ObligationStatement:
+			if reflect.DeepEqual(stmt.Obligation.Event, event) {
+				return true
+			}
+		case *ngac.IfStatement:
+			if createsObligationFor(stmt.Then, event) || createsObligationFor(stmt.Else, event) {
+				return true
+			}
+		case *ngac.ForeachStatement:
+			if createsObligationFor(stmt.Body, event) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,176 @@
+package epp
+
+import (
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
+	"github.com/PM-Master/policy-machine-go/pip/memory"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func newCascadePIP(t *testing.T, obligations ...ngac.Obligation) ngac.FunctionalEntity {
+	pip := memory.NewPIP()
+	require.NoError(t, pip.Graph().CreatePolicyClass("pc1"))
+	_, err := pip.Graph().CreateNode("oa1", graph.ObjectAttribute, nil, "pc1")
+	require.NoError(t, err)
+
+	for _, o := range obligations {
+		require.NoError(t, pip.Obligations().Add(o))
+	}
+
+	return pip
+}
+
+func obligationOn(label string, event string, actions ...ngac.Statement) ngac.Obligation {
+	return ngac.Obligation{
+		Label: label,
+		Event: ngac.EventPattern{
+			Subject:    "ANY_USER",
+			Operations: []ngac.EventOperation{{Operation: event}},
+		},
+		Response: ngac.ResponsePattern{Actions: actions},
+	}
+}
+
+func TestCascade(t *testing.T) {
+	pip := newCascadePIP(t,
+		obligationOn("first", "op1",
+			&ngac.EmitStatement{Event: "op2", Target: "oa1", Args: map[string]string{"name": "$name_2"}},
+		),
+		obligationOn("second", "op2",
+			&ngac.CreateNodeStatement{Name: "$name", Kind: graph.Object, Parents: []string{"$target"}},
+			&ngac.CreateNodeStatement{Name: "$user_$name", Kind: graph.Object, Parents: []string{"$target"}},
+		),
+	)
+
+	err := NewEPP(pip).ProcessEvent(EventContext{User: "u1", Event: "op1", Args: map[string]string{"name": "o"}})
+	require.NoError(t, err)
+
+	children, err := pip.Graph().GetChildren("oa1")
+	require.NoError(t, err)
+	require.Contains(t, children, "o_2")
+	require.Contains(t, children, "u1_o_2")
+}
+
+func TestCascadeLimits(t *testing.T) {
+	t.Run("max depth", func(t *testing.T) {
+		pip := newCascadePIP(t,
+			obligationOn("recurse", "op",
+				&ngac.CreateNodeStatement{Name: "$name", Kind: graph.Object, Parents: []string{"oa1"}},
+				&ngac.EmitStatement{Event: "op", Args: map[string]string{"name": "$name_x"}},
+			),
+		)
+
+		e := NewEPPWithOptions(pip, Options{MaxCascadeDepth: 3, MaxActions: 100})
+		err := e.ProcessEvent(EventContext{User: "u1", Event: "op", Args: map[string]string{"name": "o"}})
+		require.ErrorIs(t, err, ErrMaxCascadeDepth)
+
+		children, err := pip.Graph().GetChildren("oa1")
+		require.NoError(t, err)
+		require.Equal(t, 4, len(children))
+	})
+
+	t.Run("action budget", func(t *testing.T) {
+		pip := newCascadePIP(t,
+			obligationOn("create", "op",
+				&ngac.CreateNodeStatement{Name: "o1", Kind: graph.Object, Parents: []string{"oa1"}},
+				&ngac.CreateNodeStatement{Name: "o2", Kind: graph.Object, Parents: []string{"oa1"}},
+				&ngac.CreateNodeStatement{Name: "o3", Kind: graph.Object, Parents: []string{"oa1"}},
+			),
+		)
+
+		e := NewEPPWithOptions(pip, Options{MaxCascadeDepth: 3, MaxActions: 2})
+		err := e.ProcessEvent(EventContext{User: "u1", Event: "op"})
+		require.ErrorIs(t, err, ErrActionBudgetExceeded)
+
+		exists, _ := pip.Graph().Exists("o3")
+		require.False(t, exists)
+	})
+
+	t.Run("zero options use the defaults", func(t *testing.T) {
+		pip := newCascadePIP(t,
+			obligationOn("create", "op",
+				&ngac.CreateNodeStatement{Name: "o1", Kind: graph.Object, Parents: []string{"oa1"}},
+				&ngac.EmitStatement{Event: "created", Target: "o1"},
+			),
+			obligationOn("created", "created",
+				&ngac.CreateNodeStatement{Name: "o2", Kind: graph.Object, Parents: []string{"oa1"}},
+			),
+		)
+
+		e := NewEPPWithOptions(pip, Options{})
+		require.NoError(t, e.ProcessEvent(EventContext{User: "u1", Event: "op"}))
+
+		exists, _ := pip.Graph().Exists("o2")
+		require.True(t, exists)
+	})
+
+	t.Run("event loop", func(t *testing.T) {
+		pip := newCascadePIP(t,
+			obligationOn("ping", "ping", &ngac.EmitStatement{Event: "pong", Target: "$target"}),
+			obligationOn("pong", "pong", &ngac.EmitStatement{Event: "ping", Target: "$target"}),
+		)
+
+		err := NewEPP(pip).ProcessEvent(EventContext{User: "u1", Event: "ping", Target: "oa1"})
+		require.ErrorIs(t, err, ErrEventLoop)
+	})
+
+	t.Run("obligation creates itself", func(t *testing.T) {
+		event := ngac.EventPattern{
+			Subject:    "ANY_USER",
+			Operations: []ngac.EventOperation{{Operation: "op", Args: []string{"n"}}},
+		}
+		replicate := func(label string) ngac.Statement {
+			return &ngac.ObligationStatement{Obligation: ngac.Obligation{
+				Label: label,
+				Event: event,
+				Response: ngac.ResponsePattern{Actions: []ngac.Statement{
+					&ngac.ObligationStatement{Obligation: ngac.Obligation{Label: label + "_$n", Event: event}},
+				}},
+			}}
+		}
+
+		pip := newCascadePIP(t, ngac.Obligation{
+			Label:    "replicate",
+			Event:    event,
+			Response: ngac.ResponsePattern{Actions: []ngac.Statement{replicate("replicate_$n")}},
+		})
+
+		err := NewEPP(pip).ProcessEvent(EventContext{User: "u1", Event: "op", Args: map[string]string{"n": "1"}})
+		require.ErrorIs(t, err, ErrObligationLoop)
+
+		_, err = pip.Obligations().Get("replicate_1")
+		require.Error(t, err)
+	})
+}
+
+func TestNestedBodiesResolveArgsOnce(t *testing.T) {
+	pip := newCascadePIP(t,
+		obligationOn("nested", "op",
+			&ngac.IfStatement{
+				Condition: &ngac.ExistsCondition{Name: "oa1"},
+				Then: []ngac.Statement{
+					&ngac.CreateNodeStatement{Name: "$name", Kind: graph.Object, Parents: []string{"oa1"}},
+				},
+			},
+			&ngac.ForeachStatement{
+				Variable: "pc",
+				Relation: ngac.Parents,
+				Node:     "oa1",
+				Body: []ngac.Statement{
+					&ngac.CreateNodeStatement{Name: "$name-$pc", Kind: graph.Object, Parents: []string{"oa1"}},
+				},
+			},
+		),
+	)
+
+	// the value of name is not resolved again when the bodies are applied
+	err := NewEPP(pip).ProcessEvent(EventContext{User: "u1", Event: "op", Args: map[string]string{"name": "o-$user"}})
+	require.NoError(t, err)
+
+	children, err := pip.Graph().GetChildren("oa1")
+	require.NoError(t, err)
+	require.Len(t, children, 2)
+	require.Contains(t, children, "o-$user")
+	require.Contains(t, children, "o-$user-pc1")
+}
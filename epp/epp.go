@@ -3,11 +3,27 @@ package epp
 import (
 	"fmt"
 	"github.com/PM-Master/policy-machine-go/ngac"
+	"sort"
 	"strings"
+	"time"
 )
 
 type (
 	EventProcessor interface {
+		// ProcessEvent applies the responses of the enabled obligations that match the event.
+		//
+		// Matching obligations are applied one at a time in the order returned by
+		// ngac.Obligations.All: highest priority first and, for equal priorities, in the order
+		// the obligations were defined. Each response sees the changes made by the responses
+		// applied before it, so when two obligations conflict (e.g. one assigns a node that
+		// another deletes) the lower priority obligation is applied last. Obligations created
+		// while processing an event are not matched against that event. If a response fails,
+		// processing stops and the error is returned; changes made by earlier responses are
+		// not rolled back.
+		//
+		// Events emitted by responses are processed when the emit statement is applied, before
+		// the rest of the response. The depth of these cascades and the number of actions applied
+		// are limited by the Options the EPP was created with.
 		ProcessEvent(eventCtx EventContext) error
 	}
 
@@ -19,45 +35,75 @@ type (
 	}
 
 	epp struct {
-		pap ngac.FunctionalEntity
+		pap     ngac.FunctionalEntity
+		options Options
 	}
 )
 
 func NewEPP(pap ngac.FunctionalEntity) EventProcessor {
-	return epp{pap: pap}
+	return NewEPPWithOptions(pap, DefaultOptions())
 }
 
-func (e epp) ProcessEvent(eventCtx EventContext) error {
-	obligations, err := e.pap.Obligations().All()
-	if err != nil {
-		return fmt.Errorf("error getting obligations from PAP")
+func NewEPPWithOptions(pap ngac.FunctionalEntity, options Options) EventProcessor {
+	if options.MaxCascadeDepth <= 0 {
+		options.MaxCascadeDepth = DefaultMaxCascadeDepth
 	}
 
-	for _, obligation := range obligations {
-		var matches bool
-		matches, err = eventCtx.Matches(obligation.Event)
-		if err != nil {
-			return fmt.Errorf("error matching event pattern")
+	if options.MaxActions <= 0 {
+		options.MaxActions = DefaultMaxActions
+	}
+
+	return epp{pap: pap, options: options}
+}
+
+func (e epp) ProcessEvent(eventCtx EventContext) error {
+	if e.options.History != nil {
+		clock := e.options.Clock
+		if clock == nil {
+			clock = time.Now
 		}
 
-		if !matches {
-			continue
+		err := e.options.History.Record(ngac.HistoryEntry{
+			User:      eventCtx.User,
+			Operation: eventCtx.Event,
+			Target:    eventCtx.Target,
+			Time:      clock(),
+		})
+		if err != nil {
+			return fmt.Errorf("error recording event %q: %w", eventCtx.Event, err)
 		}
+	}
 
-		for _, action := range obligation.Response.Actions {
-			action, err = resolveArgs(action, eventCtx.Args)
-			if err != nil {
-				return fmt.Errorf("error resolving args: %w", err)
-			}
+	c := &cascade{
+		pap:     e.pap,
+		options: e.options,
+	}
 
-			err = action.Apply(e.pap)
-			if err != nil {
-				return fmt.Errorf("error applying response action: %w", err)
-			}
-		}
+	return c.processEvent(eventCtx)
+}
+
+// args returns the event args along with the event's user and target, which are
+// available to responses as $user and $target unless overridden by an arg of the same name.
+func (e EventContext) args() map[string]string {
+	args := map[string]string{
+		"user":   e.User,
+		"target": e.Target,
+	}
+
+	for k, v := range e.Args {
+		args[k] = v
 	}
 
-	return nil
+	return args
+}
+
+func copyArgs(args map[string]string) map[string]string {
+	copied := make(map[string]string, len(args))
+	for k, v := range args {
+		copied[k] = v
+	}
+
+	return copied
 }
 
 func resolveArgs(stmt ngac.Statement, args map[string]string) (ngac.Statement, error) {
@@ -92,16 +138,58 @@ func resolveArgs(stmt ngac.Statement, args map[string]string) (ngac.Statement, e
 		deleteNodeStmt.Name = replaceArgs(deleteNodeStmt.Name, args)
 
 		return deleteNodeStmt, nil
+	} else if updateNodeStmt, ok := stmt.(*ngac.UpdateNodeStatement); ok {
+		updateNodeStmt.Name = replaceArgs(updateNodeStmt.Name, args)
+		for key, value := range updateNodeStmt.Properties {
+			updateNodeStmt.Properties[key] = replaceArgs(value, args)
+		}
+
+		return updateNodeStmt, nil
 	} else if grantStmt, ok := stmt.(*ngac.GrantStatement); ok {
 		grantStmt.Uattr = replaceArgs(grantStmt.Uattr, args)
 		grantStmt.Target = replaceArgs(grantStmt.Target, args)
+		grantStmt.Condition = replaceArgs(grantStmt.Condition, args)
 
 		return grantStmt, nil
+	} else if dissociateStmt, ok := stmt.(*ngac.DissociateStatement); ok {
+		dissociateStmt.Uattr = replaceArgs(dissociateStmt.Uattr, args)
+		dissociateStmt.Target = replaceArgs(dissociateStmt.Target, args)
+
+		return dissociateStmt, nil
 	} else if denyStmt, ok := stmt.(*ngac.DenyStatement); ok {
+		denyStmt.Name = replaceArgs(denyStmt.Name, args)
 		denyStmt.Subject = replaceArgs(denyStmt.Subject, args)
 		denyStmt.Containers = resolveSlice(denyStmt.Containers, args)
 
 		return denyStmt, nil
+	} else if deleteProStmt, ok := stmt.(*ngac.DeleteProhibitionStatement); ok {
+		deleteProStmt.Name = replaceArgs(deleteProStmt.Name, args)
+
+		return deleteProStmt, nil
+	} else if ssodStmt, ok := stmt.(*ngac.SSoDStatement); ok {
+		ssodStmt.Name = replaceArgs(ssodStmt.Name, args)
+		ssodStmt.Attributes = resolveSlice(ssodStmt.Attributes, args)
+
+		return ssodStmt, nil
+	} else if dsodStmt, ok := stmt.(*ngac.DSoDStatement); ok {
+		dsodStmt.Name = replaceArgs(dsodStmt.Name, args)
+		dsodStmt.Operations = resolveSlice(dsodStmt.Operations, args)
+
+		return dsodStmt, nil
+	} else if deleteConstraintStmt, ok := stmt.(*ngac.DeleteConstraintStatement); ok {
+		deleteConstraintStmt.Name = replaceArgs(deleteConstraintStmt.Name, args)
+
+		return deleteConstraintStmt, nil
+	} else if assertStmt, ok := stmt.(*ngac.AssertPermissionStatement); ok {
+		assertStmt.User = replaceArgs(assertStmt.User, args)
+		assertStmt.Target = replaceArgs(assertStmt.Target, args)
+
+		return assertStmt, nil
+	} else if assertStmt, ok := stmt.(*ngac.AssertAssignmentStatement); ok {
+		assertStmt.Child = replaceArgs(assertStmt.Child, args)
+		assertStmt.Parent = replaceArgs(assertStmt.Parent, args)
+
+		return assertStmt, nil
 	} else if oblStmt, ok := stmt.(*ngac.ObligationStatement); ok {
 		oblStmt.Obligation.Label = replaceArgs(oblStmt.Obligation.Label, args)
 		oblStmt.Obligation.Response.Actions, err = resolveStatements(oblStmt.Obligation.Response.Actions, args)
@@ -110,11 +198,72 @@ func resolveArgs(stmt ngac.Statement, args map[string]string) (ngac.Statement, e
 		}
 
 		return oblStmt, nil
+	} else if deleteOblStmt, ok := stmt.(*ngac.DeleteObligationStatement); ok {
+		deleteOblStmt.Label = replaceArgs(deleteOblStmt.Label, args)
+
+		return deleteOblStmt, nil
+	} else if enableOblStmt, ok := stmt.(*ngac.EnableObligationStatement); ok {
+		enableOblStmt.Label = replaceArgs(enableOblStmt.Label, args)
+
+		return enableOblStmt, nil
+	} else if disableOblStmt, ok := stmt.(*ngac.DisableObligationStatement); ok {
+		disableOblStmt.Label = replaceArgs(disableOblStmt.Label, args)
+
+		return disableOblStmt, nil
+	} else if emitStmt, ok := stmt.(*ngac.EmitStatement); ok {
+		emitStmt.Event = replaceArgs(emitStmt.Event, args)
+		emitStmt.Target = replaceArgs(emitStmt.Target, args)
+		for k, v := range emitStmt.Args {
+			emitStmt.Args[k] = replaceArgs(v, args)
+		}
+
+		return emitStmt, nil
+	} else if ifStmt, ok := stmt.(*ngac.IfStatement); ok {
+		if ifStmt.Condition, err = resolveCondition(ifStmt.Condition, args); err != nil {
+			return nil, fmt.Errorf("error resolving args for if statement: %w", err)
+		}
+		if ifStmt.Then, err = resolveStatements(ifStmt.Then, args); err != nil {
+			return nil, fmt.Errorf("error resolving args for if statement: %w", err)
+		}
+		if ifStmt.Else, err = resolveStatements(ifStmt.Else, args); err != nil {
+			return nil, fmt.Errorf("error resolving args for if statement: %w", err)
+		}
+
+		return ifStmt, nil
+	} else if foreachStmt, ok := stmt.(*ngac.ForeachStatement); ok {
+		foreachStmt.Node = replaceArgs(foreachStmt.Node, args)
+
+		// the loop variable is bound on each iteration so it should not be resolved here
+		bodyArgs := copyArgs(args)
+		delete(bodyArgs, foreachStmt.Variable)
+		if foreachStmt.Body, err = resolveStatements(foreachStmt.Body, bodyArgs); err != nil {
+			return nil, fmt.Errorf("error resolving args for foreach statement: %w", err)
+		}
+
+		return foreachStmt, nil
 	} else {
 		return nil, fmt.Errorf("unknown statement: %v", stmt)
 	}
 }
 
+func resolveCondition(condition ngac.Condition, args map[string]string) (ngac.Condition, error) {
+	switch c := condition.(type) {
+	case *ngac.ExistsCondition:
+		c.Name = replaceArgs(c.Name, args)
+		return c, nil
+	case *ngac.InCondition:
+		c.Child = replaceArgs(c.Child, args)
+		c.Parent = replaceArgs(c.Parent, args)
+		return c, nil
+	case *ngac.NotCondition:
+		var err error
+		c.Condition, err = resolveCondition(c.Condition, args)
+		return c, err
+	default:
+		return nil, fmt.Errorf("unknown condition: %v", condition)
+	}
+}
+
 func resolveStatements(statements []ngac.Statement, args map[string]string) ([]ngac.Statement, error) {
 	resolved := make([]ngac.Statement, 0)
 	for _, s := range statements {
@@ -137,14 +286,48 @@ func resolveSlice(slice []string, args map[string]string) []string {
 	return resolved
 }
 
+// replaceArgs replaces each $name in str with the value of the arg called name in a single pass, so values are
+// never substituted again. When several arg names follow a $, the longest is used: with args target and target_oa,
+// $target_oa is the value of target_oa and $target_ua is the value of target followed by _ua. References to unknown
+// args are left as they are.
 func replaceArgs(str string, args map[string]string) string {
-	for arg, value := range args {
-		if strings.Contains(str, arg) {
-			str = strings.ReplaceAll(str, fmt.Sprintf("$%s", arg), value)
+	if !strings.Contains(str, "$") {
+		return str
+	}
+
+	names := make([]string, 0, len(args))
+	for name := range args {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return len(names[i]) > len(names[j])
+	})
+
+	var b strings.Builder
+	for i := 0; i < len(str); i++ {
+		if str[i] != '$' {
+			b.WriteByte(str[i])
+			continue
+		}
+
+		replaced := false
+		for _, name := range names {
+			if strings.HasPrefix(str[i+1:], name) {
+				b.WriteString(args[name])
+				i += len(name)
+				replaced = true
+				break
+			}
+		}
+
+		if !replaced {
+			b.WriteByte('$')
 		}
 	}
 
-	return str
+	return b.String()
 }
 
 func (e EventContext) Matches(eventPattern ngac.EventPattern) (bool, error) {
@@ -3,8 +3,10 @@ package epp
 import (
 	"github.com/PM-Master/policy-machine-go/ngac"
 	"github.com/PM-Master/policy-machine-go/ngac/graph"
+	"github.com/PM-Master/policy-machine-go/pip/memory"
 	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 )
 
 func TestMatches(t *testing.T) {
@@ -138,7 +140,7 @@ func TestResolveArgs(t *testing.T) {
 				Label: "myObl_$arg1",
 				Event: ngac.EventPattern{
 					Subject:    "ANY_USER",
-					Operations: []ngac.EventOperation{{"op1", nil}},
+					Operations: []ngac.EventOperation{{Operation: "op1"}},
 					Containers: []string{"oa1"},
 				},
 				Response: ngac.ResponsePattern{
@@ -164,4 +166,247 @@ func TestResolveArgs(t *testing.T) {
 		require.Equal(t, "test3", createNodeStmt.Properties["k"])
 		require.Equal(t, []string{"test1", "test4"}, createNodeStmt.Parents)
 	})
+
+	t.Run("test overlapping arg names", func(t *testing.T) {
+		args := EventContext{
+			User:   "u1",
+			Target: "o1",
+			Args:   map[string]string{"target_oa": "oa1", "targets": "all"},
+		}.args()
+
+		// resolve repeatedly since map iteration order could otherwise hide an order dependent substitution
+		for i := 0; i < 20; i++ {
+			stmt := ngac.CreateNodeStatement{
+				Name:       "$target-$target_oa",
+				Kind:       graph.Object,
+				Properties: map[string]string{"k": "$targets/$user", "price": "$5", "suffix": "$target_ua"},
+				Parents:    []string{"$target_oa", "$targets"},
+			}
+
+			resolved, err := resolveArgs(&stmt, args)
+			require.NoError(t, err)
+			actual := resolved.(*ngac.CreateNodeStatement)
+			require.Equal(t, "o1-oa1", actual.Name)
+			require.Equal(t, map[string]string{"k": "all/u1", "price": "$5", "suffix": "o1_ua"}, actual.Properties)
+			require.Equal(t, []string{"oa1", "all"}, actual.Parents)
+		}
+	})
+}
+
+func TestProcessEventConditionals(t *testing.T) {
+	pip := memory.NewPIP()
+	require.NoError(t, pip.Graph().CreatePolicyClass("pc1"))
+	_, err := pip.Graph().CreateNode("Approvers", graph.UserAttribute, nil, "pc1")
+	require.NoError(t, err)
+	_, err = pip.Graph().CreateNode("u1", graph.User, nil, "Approvers")
+	require.NoError(t, err)
+	_, err = pip.Graph().CreateNode("u2", graph.User, nil, "pc1")
+	require.NoError(t, err)
+
+	err = pip.Obligations().Add(ngac.Obligation{
+		Label: "approve",
+		Event: ngac.EventPattern{
+			Subject:    "ANY_USER",
+			Operations: []ngac.EventOperation{{Operation: "approve", Args: []string{"name"}}},
+		},
+		Response: ngac.ResponsePattern{
+			Actions: []ngac.Statement{
+				&ngac.IfStatement{
+					Condition: &ngac.InCondition{Child: "$user", Parent: "Approvers"},
+					Then: []ngac.Statement{
+						&ngac.CreateNodeStatement{Name: "$name_approved", Kind: graph.ObjectAttribute, Parents: []string{"pc1"}},
+					},
+					Else: []ngac.Statement{
+						&ngac.CreateNodeStatement{Name: "$name_rejected", Kind: graph.ObjectAttribute, Parents: []string{"pc1"}},
+					},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	e := NewEPP(pip)
+	err = e.ProcessEvent(EventContext{User: "u1", Event: "approve", Args: map[string]string{"name": "req1"}})
+	require.NoError(t, err)
+	err = e.ProcessEvent(EventContext{User: "u2", Event: "approve", Args: map[string]string{"name": "req2"}})
+	require.NoError(t, err)
+
+	exists, _ := pip.Graph().Exists("req1_approved")
+	require.True(t, exists)
+	exists, _ = pip.Graph().Exists("req2_rejected")
+	require.True(t, exists)
+	exists, _ = pip.Graph().Exists("req2_approved")
+	require.False(t, exists)
+}
+
+func TestProcessEventForeach(t *testing.T) {
+	pip := memory.NewPIP()
+	require.NoError(t, pip.Graph().CreatePolicyClass("pc1"))
+	_, err := pip.Graph().CreateNode("folder", graph.ObjectAttribute, nil, "pc1")
+	require.NoError(t, err)
+	_, err = pip.Graph().CreateNode("archive", graph.ObjectAttribute, nil, "pc1")
+	require.NoError(t, err)
+	_, err = pip.Graph().CreateNode("o1", graph.Object, nil, "folder")
+	require.NoError(t, err)
+	_, err = pip.Graph().CreateNode("o2", graph.Object, nil, "folder")
+	require.NoError(t, err)
+
+	err = pip.Obligations().Add(ngac.Obligation{
+		Label: "archive",
+		Event: ngac.EventPattern{
+			Subject:    "ANY_USER",
+			Operations: []ngac.EventOperation{{Operation: "archive"}},
+		},
+		Response: ngac.ResponsePattern{
+			Actions: []ngac.Statement{
+				&ngac.ForeachStatement{
+					Variable: "child",
+					Relation: ngac.Children,
+					Node:     "$target",
+					Body: []ngac.Statement{
+						&ngac.AssignStatement{Child: "$child", Parents: []string{"archive"}},
+					},
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	err = NewEPP(pip).ProcessEvent(EventContext{User: "u1", Event: "archive", Target: "folder"})
+	require.NoError(t, err)
+
+	children, err := pip.Graph().GetChildren("archive")
+	require.NoError(t, err)
+	require.Contains(t, children, "o1")
+	require.Contains(t, children, "o2")
+
+	// the stored obligation should not be modified by resolving args
+	obligation, err := pip.Obligations().Get("archive")
+	require.NoError(t, err)
+	require.Equal(t, "$target", obligation.Response.Actions[0].(*ngac.ForeachStatement).Node)
+}
+
+func TestProcessEventObligationLifecycle(t *testing.T) {
+	pip := memory.NewPIP()
+	require.NoError(t, pip.Graph().CreatePolicyClass("pc1"))
+
+	err := pip.Obligations().Add(ngac.Obligation{
+		Label: "once",
+		Event: ngac.EventPattern{
+			Subject:    "ANY_USER",
+			Operations: []ngac.EventOperation{{Operation: "create", Args: []string{"name"}}},
+		},
+		Response: ngac.ResponsePattern{
+			Actions: []ngac.Statement{
+				&ngac.CreateNodeStatement{Name: "$name", Kind: graph.ObjectAttribute, Parents: []string{"pc1"}},
+				&ngac.DisableObligationStatement{Label: "once"},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	e := NewEPP(pip)
+	require.NoError(t, e.ProcessEvent(EventContext{User: "u1", Event: "create", Args: map[string]string{"name": "oa1"}}))
+	require.NoError(t, e.ProcessEvent(EventContext{User: "u1", Event: "create", Args: map[string]string{"name": "oa2"}}))
+
+	exists, _ := pip.Graph().Exists("oa1")
+	require.True(t, exists)
+	exists, _ = pip.Graph().Exists("oa2")
+	require.False(t, exists)
+
+	require.NoError(t, pip.Obligations().Enable("once"))
+	require.NoError(t, e.ProcessEvent(EventContext{User: "u1", Event: "create", Args: map[string]string{"name": "oa2"}}))
+	exists, _ = pip.Graph().Exists("oa2")
+	require.True(t, exists)
+}
+
+func TestProcessEventOrder(t *testing.T) {
+	marker := ngac.Obligation{
+		Label: "marker",
+		Event: ngac.EventPattern{
+			Subject:    "ANY_USER",
+			Operations: []ngac.EventOperation{{Operation: "op"}},
+		},
+		Response: ngac.ResponsePattern{
+			Actions: []ngac.Statement{
+				&ngac.CreateNodeStatement{Name: "marker", Kind: graph.ObjectAttribute, Parents: []string{"pc1"}},
+			},
+		},
+	}
+	check := ngac.Obligation{
+		Label: "check",
+		Event: ngac.EventPattern{
+			Subject:    "ANY_USER",
+			Operations: []ngac.EventOperation{{Operation: "op"}},
+		},
+		Response: ngac.ResponsePattern{
+			Actions: []ngac.Statement{
+				&ngac.IfStatement{
+					Condition: &ngac.ExistsCondition{Name: "marker"},
+					Then: []ngac.Statement{
+						&ngac.CreateNodeStatement{Name: "after", Kind: graph.ObjectAttribute, Parents: []string{"pc1"}},
+					},
+					Else: []ngac.Statement{
+						&ngac.CreateNodeStatement{Name: "before", Kind: graph.ObjectAttribute, Parents: []string{"pc1"}},
+					},
+				},
+			},
+		},
+	}
+
+	run := func(obligations ...ngac.Obligation) ngac.FunctionalEntity {
+		pip := memory.NewPIP()
+		require.NoError(t, pip.Graph().CreatePolicyClass("pc1"))
+		for _, o := range obligations {
+			require.NoError(t, pip.Obligations().Add(o))
+		}
+
+		require.NoError(t, NewEPP(pip).ProcessEvent(EventContext{User: "u1", Event: "op"}))
+		return pip
+	}
+
+	t.Run("definition order", func(t *testing.T) {
+		for i := 0; i < 20; i++ {
+			pip := run(marker, check)
+			exists, _ := pip.Graph().Exists("after")
+			require.True(t, exists)
+			exists, _ = pip.Graph().Exists("before")
+			require.False(t, exists)
+		}
+	})
+
+	t.Run("priority", func(t *testing.T) {
+		prioritized := check
+		prioritized.Priority = 1
+		for i := 0; i < 20; i++ {
+			pip := run(marker, prioritized)
+			exists, _ := pip.Graph().Exists("before")
+			require.True(t, exists)
+			exists, _ = pip.Graph().Exists("after")
+			require.False(t, exists)
+		}
+	})
+}
+
+func TestHistory(t *testing.T) {
+	pip := memory.NewPIP()
+	history := memory.NewHistory()
+	options := DefaultOptions()
+	options.History = history
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	options.Clock = func() time.Time { return now }
+
+	e := NewEPPWithOptions(pip, options)
+	require.NoError(t, e.ProcessEvent(EventContext{User: "u1", Event: "create", Target: "r1"}))
+	require.NoError(t, e.ProcessEvent(EventContext{User: "u1", Event: "approve", Target: "r2"}))
+
+	ops, err := history.Operations("u1", "r1")
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{"create": true}, ops)
+
+	entries, err := history.All()
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	require.Equal(t, now, entries[0].Time)
+	require.Equal(t, now, entries[1].Time)
 }
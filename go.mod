@@ -2,4 +2,8 @@ module github.com/PM-Master/policy-machine-go
 
 go 1.14
 
-require github.com/stretchr/testify v1.7.0
+require (
+	github.com/stretchr/testify v1.7.0
+	google.golang.org/grpc v1.40.0
+	google.golang.org/protobuf v1.27.1
+)
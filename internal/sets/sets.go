@@ -0,0 +1,37 @@
+// Package sets provides the helpers for sets of node names shared by the packages that analyze policies.
+package sets
+
+import "sort"
+
+// Sorted returns the names in the set sorted.
+func Sorted(set map[string]bool) []string {
+	sorted := make([]string, 0, len(set))
+	for name := range set {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	return sorted
+}
+
+// Reachable returns the nodes reachable from the node by following next, excluding the node itself.
+func Reachable(name string, next func(string) (map[string]bool, error)) (map[string]bool, error) {
+	reachable := make(map[string]bool)
+	queue := []string{name}
+	for len(queue) > 0 {
+		adjacent, err := next(queue[0])
+		if err != nil {
+			return nil, err
+		}
+		queue = queue[1:]
+
+		for node := range adjacent {
+			if !reachable[node] {
+				reachable[node] = true
+				queue = append(queue, node)
+			}
+		}
+	}
+
+	return reachable, nil
+}
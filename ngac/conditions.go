@@ -0,0 +1,195 @@
+package ngac
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+type (
+	// Condition is a predicate over the graph used by conditional response statements.
+	Condition interface {
+		Evaluate(graph Graph) (bool, error)
+
+		json.Marshaler
+		json.Unmarshaler
+	}
+
+	// ExistsCondition is true if a node with the given name exists.
+	ExistsCondition struct {
+		Name string `json:"name,omitempty"`
+	}
+
+	jsonExistsCondition struct {
+		Name string `json:"name,omitempty"`
+	}
+
+	// InCondition is true if Child is contained in Parent, directly or through other attributes.
+	InCondition struct {
+		Child  string `json:"child,omitempty"`
+		Parent string `json:"parent,omitempty"`
+	}
+
+	jsonInCondition struct {
+		Child  string `json:"child,omitempty"`
+		Parent string `json:"parent,omitempty"`
+	}
+
+	// NotCondition negates another condition.
+	NotCondition struct {
+		Condition Condition `json:"condition"`
+	}
+
+	jsonNotCondition struct {
+		Condition map[string][]byte `json:"condition"`
+	}
+)
+
+func (e *ExistsCondition) Evaluate(graph Graph) (bool, error) {
+	return graph.Exists(e.Name)
+}
+
+func (e *ExistsCondition) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&jsonExistsCondition{Name: e.Name})
+}
+
+func (e *ExistsCondition) UnmarshalJSON(bytes []byte) error {
+	j := &jsonExistsCondition{}
+	if err := json.Unmarshal(bytes, j); err != nil {
+		return err
+	}
+
+	e.Name = j.Name
+
+	return nil
+}
+
+func (i *InCondition) Evaluate(graph Graph) (bool, error) {
+	if ok, err := graph.Exists(i.Child); err != nil || !ok {
+		return false, err
+	}
+
+	queue := []string{i.Child}
+	seen := map[string]bool{i.Child: true}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+
+		parents, err := graph.GetParents(name)
+		if err != nil {
+			return false, err
+		}
+
+		for parent := range parents {
+			if parent == i.Parent {
+				return true, nil
+			}
+
+			if seen[parent] {
+				continue
+			}
+
+			seen[parent] = true
+			queue = append(queue, parent)
+		}
+	}
+
+	return false, nil
+}
+
+func (i *InCondition) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&jsonInCondition{
+		Child:  i.Child,
+		Parent: i.Parent,
+	})
+}
+
+func (i *InCondition) UnmarshalJSON(bytes []byte) error {
+	j := &jsonInCondition{}
+	if err := json.Unmarshal(bytes, j); err != nil {
+		return err
+	}
+
+	i.Child = j.Child
+	i.Parent = j.Parent
+
+	return nil
+}
+
+func (n *NotCondition) Evaluate(graph Graph) (bool, error) {
+	ok, err := n.Condition.Evaluate(graph)
+	if err != nil {
+		return false, err
+	}
+
+	return !ok, nil
+}
+
+func (n *NotCondition) MarshalJSON() ([]byte, error) {
+	condition, err := marshalCondition(n.Condition)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(&jsonNotCondition{Condition: condition})
+}
+
+func (n *NotCondition) UnmarshalJSON(bytes []byte) error {
+	j := &jsonNotCondition{}
+	if err := json.Unmarshal(bytes, j); err != nil {
+		return err
+	}
+
+	condition, err := unmarshalCondition(j.Condition)
+	if err != nil {
+		return err
+	}
+
+	n.Condition = condition
+
+	return nil
+}
+
+func marshalCondition(condition Condition) (map[string][]byte, error) {
+	var conditionName string
+	switch condition.(type) {
+	case *ExistsCondition:
+		conditionName = "ExistsCondition"
+	case *InCondition:
+		conditionName = "InCondition"
+	case *NotCondition:
+		conditionName = "NotCondition"
+	default:
+		return nil, fmt.Errorf("unknown condition %T", condition)
+	}
+
+	bytes, err := json.Marshal(condition)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string][]byte{conditionName: bytes}, nil
+}
+
+func unmarshalCondition(conditionMap map[string][]byte) (Condition, error) {
+	for conditionType, conditionBytes := range conditionMap {
+		var condition Condition
+		switch conditionType {
+		case "ExistsCondition":
+			condition = &ExistsCondition{}
+		case "InCondition":
+			condition = &InCondition{}
+		case "NotCondition":
+			condition = &NotCondition{}
+		default:
+			return nil, fmt.Errorf("unknown condition %q", conditionType)
+		}
+
+		if err := json.Unmarshal(conditionBytes, condition); err != nil {
+			return nil, err
+		}
+
+		return condition, nil
+	}
+
+	return nil, fmt.Errorf("missing condition")
+}
@@ -0,0 +1,164 @@
+package ngac
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
+	"sort"
+)
+
+type (
+	Constraints interface {
+		AddSSoD(constraint SSoD) error
+		GetSSoD() ([]SSoD, error)
+		RemoveSSoD(name string) error
+		AddDSoD(constraint DSoD) error
+		GetDSoD() ([]DSoD, error)
+		RemoveDSoD(name string) error
+
+		json.Marshaler
+		json.Unmarshaler
+	}
+
+	// SSoD is a static separation of duty constraint. A user can be a member of at most Max of the Attributes,
+	// directly or through other user attributes.
+	SSoD struct {
+		Name       string   `json:"name,omitempty"`
+		Attributes []string `json:"attributes,omitempty"`
+		Max        int      `json:"max,omitempty"`
+	}
+
+	// DSoD is a dynamic separation of duty constraint. A user that has performed one of the Operations on a target
+	// cannot perform any of the other Operations on the same target.
+	DSoD struct {
+		Name       string   `json:"name,omitempty"`
+		Operations []string `json:"operations,omitempty"`
+	}
+
+	// SSoDViolation describes a user that is a member of more of the constraint's attributes than allowed.
+	SSoDViolation struct {
+		Constraint string   `json:"constraint"`
+		User       string   `json:"user"`
+		Attributes []string `json:"attributes"`
+	}
+)
+
+// Validate returns an error if the constraint could never be satisfied or never be violated.
+func (s SSoD) Validate() error {
+	if len(s.Attributes) < 2 {
+		return InvalidError("ssod constraint %q must have at least two attributes", s.Name)
+	}
+
+	if s.Max < 1 || s.Max >= len(s.Attributes) {
+		return InvalidError("ssod constraint %q must have a max between 1 and %d", s.Name, len(s.Attributes)-1)
+	}
+
+	return nil
+}
+
+// Validate returns an error if the constraint has fewer than two operations.
+func (d DSoD) Validate() error {
+	if len(d.Operations) < 2 {
+		return InvalidError("dsod constraint %q must have at least two operations", d.Name)
+	}
+
+	return nil
+}
+
+// Conflicts returns the operations that the user cannot perform because they have performed the other operations.
+func (d DSoD) Conflicts(performed map[string]bool) map[string]bool {
+	conflicts := make(map[string]bool)
+	for _, op := range d.Operations {
+		if !performed[op] {
+			continue
+		}
+
+		for _, other := range d.Operations {
+			if other != op {
+				conflicts[other] = true
+			}
+		}
+	}
+
+	return conflicts
+}
+
+func (v SSoDViolation) Error() string {
+	return fmt.Sprintf("user %q violates ssod constraint %q as a member of %v", v.User, v.Constraint, v.Attributes)
+}
+
+// CheckSSoD returns the constraints the user violates.
+func CheckSSoD(g Graph, constraints []SSoD, user string) ([]SSoDViolation, error) {
+	memberships, err := userAttributes(g, user)
+	if err != nil {
+		return nil, err
+	}
+
+	violations := make([]SSoDViolation, 0)
+	for _, constraint := range constraints {
+		attrs := make([]string, 0)
+		for _, attr := range constraint.Attributes {
+			if memberships[attr] {
+				attrs = append(attrs, attr)
+			}
+		}
+
+		if len(attrs) > constraint.Max {
+			violations = append(violations, SSoDViolation{
+				Constraint: constraint.Name,
+				User:       user,
+				Attributes: attrs,
+			})
+		}
+	}
+
+	return violations, nil
+}
+
+// ValidateSSoD checks every user in the graph against the constraints and returns the violations ordered by user.
+func ValidateSSoD(g Graph, constraints []SSoD) ([]SSoDViolation, error) {
+	users, err := g.Find(graph.User, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(users))
+	for name := range users {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	violations := make([]SSoDViolation, 0)
+	for _, name := range names {
+		userViolations, err := CheckSSoD(g, constraints, name)
+		if err != nil {
+			return nil, err
+		}
+
+		violations = append(violations, userViolations...)
+	}
+
+	return violations, nil
+}
+
+// userAttributes returns the attributes the user is contained in.
+func userAttributes(g Graph, user string) (map[string]bool, error) {
+	attrs := make(map[string]bool)
+	queue := []string{user}
+	for len(queue) > 0 {
+		parents, err := g.GetParents(queue[0])
+		if err != nil {
+			return nil, err
+		}
+		queue = queue[1:]
+
+		for parent := range parents {
+			if !attrs[parent] {
+				attrs[parent] = true
+				queue = append(queue, parent)
+			}
+		}
+	}
+
+	return attrs, nil
+}
@@ -0,0 +1,50 @@
+package ngac
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotFound is matched by the errors returned when a node, association, prohibition, obligation or constraint
+	// does not exist.
+	ErrNotFound = errors.New("not found")
+	// ErrAlreadyExists is matched by the errors returned when an element with the same name already exists.
+	ErrAlreadyExists = errors.New("already exists")
+	// ErrInvalid is matched by the errors returned when a change is not allowed, such as an assignment between kinds
+	// of nodes that cannot be assigned or a change that violates a constraint.
+	ErrInvalid = errors.New("invalid")
+)
+
+// policyError is an error that errors.Is matches with one of the errors above, without changing its message.
+type policyError struct {
+	kind error
+	err  error
+}
+
+// NotFoundError returns an error with the formatted message that matches ErrNotFound.
+func NotFoundError(format string, args ...interface{}) error {
+	return policyError{kind: ErrNotFound, err: fmt.Errorf(format, args...)}
+}
+
+// AlreadyExistsError returns an error with the formatted message that matches ErrAlreadyExists.
+func AlreadyExistsError(format string, args ...interface{}) error {
+	return policyError{kind: ErrAlreadyExists, err: fmt.Errorf(format, args...)}
+}
+
+// InvalidError returns an error with the formatted message that matches ErrInvalid.
+func InvalidError(format string, args ...interface{}) error {
+	return policyError{kind: ErrInvalid, err: fmt.Errorf(format, args...)}
+}
+
+func (e policyError) Error() string {
+	return e.err.Error()
+}
+
+func (e policyError) Is(target error) bool {
+	return target == e.kind
+}
+
+func (e policyError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,219 @@
+// Package expr implements the conditions of conditional associations.
+//
+// A condition compares attributes of the user and object of a decision and of the environment the decision is made
+// in:
+//
+//	user.clearance >= object.level
+//	env.ip in "10.0.0.0/8" and env.time between "09:00" and "17:00"
+//	not (object.classification == "secret" or env.network != "internal")
+//
+// Operands are user.<property> and object.<property>, which are read from the properties of the user and object
+// nodes, env.<attribute>, which is read from the environment, and string or number literals. Two operands are
+// compared as numbers if both are numbers, otherwise as strings. `in` checks that an IP address is in a CIDR range.
+// `between` is inclusive and wraps around if the low value is greater than the high value, so
+// `env.time between "22:00" and "06:00"` is satisfied overnight.
+package expr
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+type (
+	// Context holds the attributes an expression is evaluated against.
+	Context struct {
+		User        map[string]string
+		Object      map[string]string
+		Environment map[string]string
+	}
+
+	// Expression is a parsed condition. Evaluating an expression that references an attribute that is not set
+	// returns an error.
+	Expression interface {
+		Evaluate(ctx Context) (bool, error)
+	}
+
+	andExpression struct {
+		left  Expression
+		right Expression
+	}
+
+	orExpression struct {
+		left  Expression
+		right Expression
+	}
+
+	notExpression struct {
+		expression Expression
+	}
+
+	comparison struct {
+		left     operand
+		operator string
+		right    operand
+	}
+
+	inExpression struct {
+		value   operand
+		network operand
+	}
+
+	betweenExpression struct {
+		value operand
+		low   operand
+		high  operand
+	}
+
+	// operand is an attribute if scope is set, otherwise a literal
+	operand struct {
+		scope string
+		name  string
+	}
+)
+
+const (
+	UserScope        = "user"
+	ObjectScope      = "object"
+	EnvironmentScope = "env"
+)
+
+func (a andExpression) Evaluate(ctx Context) (bool, error) {
+	ok, err := a.left.Evaluate(ctx)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	return a.right.Evaluate(ctx)
+}
+
+func (o orExpression) Evaluate(ctx Context) (bool, error) {
+	ok, err := o.left.Evaluate(ctx)
+	if err != nil || ok {
+		return ok, err
+	}
+
+	return o.right.Evaluate(ctx)
+}
+
+func (n notExpression) Evaluate(ctx Context) (bool, error) {
+	ok, err := n.expression.Evaluate(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	return !ok, nil
+}
+
+func (c comparison) Evaluate(ctx Context) (bool, error) {
+	left, err := c.left.value(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	right, err := c.right.value(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	result := compare(left, right)
+	switch c.operator {
+	case "==":
+		return result == 0, nil
+	case "!=":
+		return result != 0, nil
+	case "<":
+		return result < 0, nil
+	case "<=":
+		return result <= 0, nil
+	case ">":
+		return result > 0, nil
+	case ">=":
+		return result >= 0, nil
+	default:
+		return false, fmt.Errorf("unknown operator %q", c.operator)
+	}
+}
+
+func (i inExpression) Evaluate(ctx Context) (bool, error) {
+	value, err := i.value.value(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	network, err := i.network.value(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	_, ipNet, err := net.ParseCIDR(network)
+	if err != nil {
+		return false, fmt.Errorf("invalid network %q: %w", network, err)
+	}
+
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return false, fmt.Errorf("invalid ip address %q", value)
+	}
+
+	return ipNet.Contains(ip), nil
+}
+
+func (b betweenExpression) Evaluate(ctx Context) (bool, error) {
+	values := make([]string, 0, 3)
+	for _, o := range []operand{b.value, b.low, b.high} {
+		value, err := o.value(ctx)
+		if err != nil {
+			return false, err
+		}
+
+		values = append(values, value)
+	}
+
+	value, low, high := values[0], values[1], values[2]
+	if compare(low, high) <= 0 {
+		return compare(value, low) >= 0 && compare(value, high) <= 0, nil
+	}
+
+	return compare(value, low) >= 0 || compare(value, high) <= 0, nil
+}
+
+func (o operand) value(ctx Context) (string, error) {
+	var attrs map[string]string
+	switch o.scope {
+	case "":
+		return o.name, nil
+	case UserScope:
+		attrs = ctx.User
+	case ObjectScope:
+		attrs = ctx.Object
+	case EnvironmentScope:
+		attrs = ctx.Environment
+	}
+
+	value, ok := attrs[o.name]
+	if !ok {
+		return "", fmt.Errorf("attribute %s.%s is not set", o.scope, o.name)
+	}
+
+	return value, nil
+}
+
+// compare compares the values as numbers if both are numbers, otherwise as strings.
+func compare(a string, b string) int {
+	x, errA := strconv.ParseFloat(a, 64)
+	y, errB := strconv.ParseFloat(b, 64)
+	if errA != nil || errB != nil {
+		return strings.Compare(a, b)
+	}
+
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,72 @@
+package expr
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestEvaluate(t *testing.T) {
+	ctx := Context{
+		User:   map[string]string{"clearance": "3", "department": "hr"},
+		Object: map[string]string{"level": "2", "department": "hr"},
+		Environment: map[string]string{
+			"ip":   "10.1.2.3",
+			"time": "23:30",
+		},
+	}
+
+	tests := []struct {
+		condition string
+		expected  bool
+	}{
+		{`user.clearance >= object.level`, true},
+		{`user.clearance < object.level`, false},
+		{`user.clearance > "10"`, false},
+		{`user.department == object.department`, true},
+		{`user.department != "hr"`, false},
+		{`env.ip in "10.0.0.0/8"`, true},
+		{`env.ip in "192.168.0.0/16"`, false},
+		{`env.time between "09:00" and "17:00"`, false},
+		{`env.time between "22:00" and "06:00"`, true},
+		{`not env.ip in "10.0.0.0/8"`, false},
+		{`user.clearance >= 3 and env.ip in "10.0.0.0/8"`, true},
+		{`user.clearance >= 4 or object.level == 2`, true},
+		{`not (user.clearance >= 4 or object.level == 3) AND env.time between "23:00" and "23:59"`, true},
+	}
+
+	for _, test := range tests {
+		expression, err := Parse(test.condition)
+		require.NoError(t, err, test.condition)
+
+		ok, err := expression.Evaluate(ctx)
+		require.NoError(t, err, test.condition)
+		require.Equal(t, test.expected, ok, test.condition)
+	}
+}
+
+func TestEvaluateMissingAttribute(t *testing.T) {
+	expression, err := Parse(`user.clearance >= object.level`)
+	require.NoError(t, err)
+
+	_, err = expression.Evaluate(Context{User: map[string]string{"clearance": "1"}})
+	require.Error(t, err)
+}
+
+func TestParseErrors(t *testing.T) {
+	conditions := []string{
+		``,
+		`user.clearance`,
+		`user.clearance = 1`,
+		`clearance >= 1`,
+		`group.name == "a"`,
+		`user.clearance >= "1`,
+		`(user.clearance >= 1`,
+		`user.clearance >= 1 user.level`,
+		`env.time between "09:00" "17:00"`,
+	}
+
+	for _, condition := range conditions {
+		_, err := Parse(condition)
+		require.Error(t, err, condition)
+	}
+}
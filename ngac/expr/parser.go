@@ -0,0 +1,269 @@
+package expr
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+type (
+	token struct {
+		kind tokenKind
+		text string
+	}
+
+	tokenKind int
+
+	parser struct {
+		tokens []token
+		pos    int
+	}
+)
+
+const (
+	identToken tokenKind = iota
+	literalToken
+	operatorToken
+	openToken
+	closeToken
+	endToken
+)
+
+// Parse parses a condition.
+func Parse(condition string) (Expression, error) {
+	tokens, err := tokenize(condition)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing condition %q: %w", condition, err)
+	}
+
+	p := &parser{tokens: tokens}
+	expression, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("error parsing condition %q: %w", condition, err)
+	}
+
+	if t := p.peek(); t.kind != endToken {
+		return nil, fmt.Errorf("error parsing condition %q: unexpected %q", condition, t.text)
+	}
+
+	return expression, nil
+}
+
+func tokenize(condition string) ([]token, error) {
+	tokens := make([]token, 0)
+	runes := []rune(condition)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: openToken, text: "("})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: closeToken, text: ")"})
+			i++
+		case r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+
+			tokens = append(tokens, token{kind: literalToken, text: string(runes[i+1 : end])})
+			i = end + 1
+		case strings.ContainsRune("=!<>", r):
+			end := i + 1
+			if end < len(runes) && runes[end] == '=' {
+				end++
+			}
+
+			op := string(runes[i:end])
+			if op == "=" || op == "!" {
+				return nil, fmt.Errorf("unknown operator %q", op)
+			}
+
+			tokens = append(tokens, token{kind: operatorToken, text: op})
+			i = end
+		case isWordRune(r):
+			end := i
+			for end < len(runes) && isWordRune(runes[end]) {
+				end++
+			}
+
+			word := string(runes[i:end])
+			kind := identToken
+			if unicode.IsDigit(r) || r == '-' {
+				kind = literalToken
+			}
+
+			tokens = append(tokens, token{kind: kind, text: word})
+			i = end
+		default:
+			return nil, fmt.Errorf("unexpected character %q", r)
+		}
+	}
+
+	return append(tokens, token{kind: endToken}), nil
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_.-", r)
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != endToken {
+		p.pos++
+	}
+
+	return t
+}
+
+// keyword returns true and consumes the next token if it is the given keyword.
+func (p *parser) keyword(keyword string) bool {
+	t := p.peek()
+	if t.kind == identToken && strings.EqualFold(t.text, keyword) {
+		p.pos++
+		return true
+	}
+
+	return false
+}
+
+func (p *parser) parseOr() (Expression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.keyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+
+		left = orExpression{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Expression, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.keyword("and") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		left = andExpression{left: left, right: right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseUnary() (Expression, error) {
+	if p.keyword("not") {
+		expression, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return notExpression{expression: expression}, nil
+	}
+
+	if p.peek().kind == openToken {
+		p.next()
+		expression, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+
+		if t := p.next(); t.kind != closeToken {
+			return nil, fmt.Errorf("expected ) but found %q", t.text)
+		}
+
+		return expression, nil
+	}
+
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (Expression, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.keyword("in") {
+		network, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+
+		return inExpression{value: left, network: network}, nil
+	}
+
+	if p.keyword("between") {
+		low, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+
+		if !p.keyword("and") {
+			return nil, fmt.Errorf("expected and after %q in between", low.name)
+		}
+
+		high, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+
+		return betweenExpression{value: left, low: low, high: high}, nil
+	}
+
+	t := p.next()
+	if t.kind != operatorToken {
+		return nil, fmt.Errorf("expected comparison operator but found %q", t.text)
+	}
+
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	return comparison{left: left, operator: t.text, right: right}, nil
+}
+
+func (p *parser) parseOperand() (operand, error) {
+	t := p.next()
+	switch t.kind {
+	case literalToken:
+		return operand{name: t.text}, nil
+	case identToken:
+		split := strings.SplitN(t.text, ".", 2)
+		if len(split) != 2 || split[1] == "" {
+			return operand{}, fmt.Errorf("expected user.<property>, object.<property> or env.<attribute> but found %q", t.text)
+		}
+
+		scope := strings.ToLower(split[0])
+		if scope != UserScope && scope != ObjectScope && scope != EnvironmentScope {
+			return operand{}, fmt.Errorf("unknown attribute scope %q", split[0])
+		}
+
+		return operand{scope: scope, name: split[1]}, nil
+	default:
+		return operand{}, fmt.Errorf("expected operand but found %q", t.text)
+	}
+}
@@ -21,11 +21,28 @@ type (
 		GetParents(name string) (map[string]graph.Node, error)
 		GetAssignments() (map[string]map[string]bool, error)
 		Associate(subject string, target string, operations graph.Operations) error
+		// AssociateWithCondition creates an association that only grants the operations if the condition is
+		// satisfied when a decision is made. See the expr package for the syntax of conditions.
+		AssociateWithCondition(subject string, target string, operations graph.Operations, condition string) error
 		Dissociate(subject string, target string) error
 		GetAssociationsForSubject(subject string) (map[string]graph.Operations, error)
 		GetAssociations() (map[string]map[string]graph.Operations, error)
+		// GetAssociationConditions returns the conditions of the subject's conditional associations keyed by target.
+		GetAssociationConditions(subject string) (map[string]string, error)
+		// SetAssociationValidity limits the period an existing association is in effect. Associating the subject and
+		// target again clears the validity.
+		SetAssociationValidity(subject string, target string, validity graph.Validity) error
+		// GetAssociationValidity returns the validity of the subject's time bound associations keyed by target.
+		GetAssociationValidity(subject string) (map[string]graph.Validity, error)
 
 		json.Marshaler
 		json.Unmarshaler
 	}
+
+	// AncestorIndex is implemented by graphs that maintain the set of nodes each node is contained in. The decider
+	// uses the index, when a graph provides one, to compute permissions without traversing the graph.
+	AncestorIndex interface {
+		// Ancestors returns the names of all nodes the node is assigned to directly or indirectly.
+		Ancestors(name string) (map[string]bool, error)
+	}
 )
@@ -0,0 +1,31 @@
+package graph
+
+import "time"
+
+type (
+	// Validity is the period an association or prohibition is in effect. A nil bound leaves that side of the
+	// period open.
+	Validity struct {
+		NotBefore *time.Time `json:"not_before,omitempty"`
+		NotAfter  *time.Time `json:"not_after,omitempty"`
+	}
+)
+
+// IsZero returns true if the validity is not bounded.
+func (v Validity) IsZero() bool {
+	return v.NotBefore == nil && v.NotAfter == nil
+}
+
+// Active returns true if t is within the validity period. Both bounds are inclusive.
+func (v Validity) Active(t time.Time) bool {
+	if v.NotBefore != nil && t.Before(*v.NotBefore) {
+		return false
+	}
+
+	return !v.Expired(t)
+}
+
+// Expired returns true if t is after the end of the validity period.
+func (v Validity) Expired(t time.Time) bool {
+	return v.NotAfter != nil && t.After(*v.NotAfter)
+}
@@ -0,0 +1,28 @@
+package ngac
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type (
+	// History records the operations users have performed on targets. It is used to enforce dynamic separation of
+	// duty constraints.
+	History interface {
+		Record(entry HistoryEntry) error
+		// Operations returns the operations the user has performed on the target.
+		Operations(user string, target string) (map[string]bool, error)
+		// All returns every entry in the order they were recorded.
+		All() ([]HistoryEntry, error)
+
+		json.Marshaler
+		json.Unmarshaler
+	}
+
+	HistoryEntry struct {
+		User      string    `json:"user"`
+		Operation string    `json:"operation"`
+		Target    string    `json:"target"`
+		Time      time.Time `json:"time"`
+	}
+)
@@ -6,5 +6,6 @@ type (
 		Graph() Graph
 		Prohibitions() Prohibitions
 		Obligations() Obligations
+		Constraints() Constraints
 	}
 )
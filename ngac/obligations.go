@@ -9,15 +9,23 @@ type (
 		Add(obligation Obligation) error
 		Remove(label string) error
 		Get(label string) (Obligation, error)
+		// All returns the obligations in the order they should be applied.
 		All() ([]Obligation, error)
+		Enable(label string) error
+		Disable(label string) error
 
 		json.Marshaler
 		json.Unmarshaler
 	}
 
+	// Obligation is applied by the EPP when an event matches its event pattern. When several
+	// obligations match the same event, those with a higher Priority are applied first and
+	// obligations with equal priority are applied in the order they were added.
 	Obligation struct {
 		User     string          `json:"user"`
 		Label    string          `json:"label"`
+		Priority int             `json:"priority,omitempty"`
+		Disabled bool            `json:"disabled,omitempty"`
 		Event    EventPattern    `json:"event"`
 		Response ResponsePattern `json:"response"`
 	}
@@ -40,6 +48,8 @@ type (
 	jsonObligation struct {
 		User     string       `json:"user"`
 		Label    string       `json:"label"`
+		Priority int          `json:"priority,omitempty"`
+		Disabled bool         `json:"disabled,omitempty"`
 		Event    EventPattern `json:"event"`
 		Response jsonResponse `json:"response"`
 	}
@@ -50,39 +60,16 @@ type (
 )
 
 func (o *Obligation) MarshalJSON() ([]byte, error) {
-	actions := make([]map[string][]byte, 0)
-	for _, action := range o.Response.Actions {
-		var actionName string
-		switch action.(type) {
-		case *CreatePolicyStatement:
-			actionName = "CreatePolicyStatement"
-		case *CreateNodeStatement:
-			actionName = "CreateNodeStatement"
-		case *AssignStatement:
-			actionName = "AssignStatement"
-		case *DeassignStatement:
-			actionName = "DeassignStatement"
-		case *DeleteNodeStatement:
-			actionName = "DeleteNodeStatement"
-		case *GrantStatement:
-			actionName = "GrantStatement"
-		case *DenyStatement:
-			actionName = "DenyStatement"
-		case *ObligationStatement:
-			actionName = "ObligationStatement"
-		}
-
-		bytes, err := json.Marshal(action)
-		if err != nil {
-			return nil, err
-		}
-
-		actions = append(actions, map[string][]byte{actionName: bytes})
+	actions, err := marshalStatements(o.Response.Actions)
+	if err != nil {
+		return nil, err
 	}
 
 	return json.Marshal(jsonObligation{
 		User:     o.User,
 		Label:    o.Label,
+		Priority: o.Priority,
+		Disabled: o.Disabled,
 		Event:    o.Event,
 		Response: jsonResponse{actions},
 	})
@@ -95,75 +82,163 @@ func (o *Obligation) UnmarshalJSON(bytes []byte) error {
 		return err
 	}
 
-	actions := make([]Statement, 0)
-
-	for _, actionMap := range j.Response.Actions {
-		for actionType, actionBytes := range actionMap {
-			switch actionType {
-			case "CreatePolicyStatement":
-				action := CreatePolicyStatement{}
-				err = json.Unmarshal(actionBytes, &action)
-				if err != nil {
-					return err
-				}
-				actions = append(actions, &action)
-			case "CreateNodeStatement":
-				action := CreateNodeStatement{}
-				err = json.Unmarshal(actionBytes, &action)
-				if err != nil {
-					return err
-				}
-				actions = append(actions, &action)
-			case "AssignStatement":
-				action := AssignStatement{}
-				err = json.Unmarshal(actionBytes, &action)
-				if err != nil {
-					return err
-				}
-				actions = append(actions, &action)
-			case "DeassignStatement":
-				action := DeassignStatement{}
-				err = json.Unmarshal(actionBytes, &action)
-				if err != nil {
-					return err
-				}
-				actions = append(actions, &action)
-			case "DeleteNodeStatement":
-				action := DeleteNodeStatement{}
-				err = json.Unmarshal(actionBytes, &action)
-				if err != nil {
-					return err
-				}
-				actions = append(actions, &action)
-			case "GrantStatement":
-				action := GrantStatement{}
-				err = json.Unmarshal(actionBytes, &action)
-				if err != nil {
-					return err
-				}
-				actions = append(actions, &action)
-			case "DenyStatement":
-				action := DenyStatement{}
-				err = json.Unmarshal(actionBytes, &action)
-				if err != nil {
-					return err
-				}
-				actions = append(actions, &action)
-			case "ObligationStatement":
-				action := ObligationStatement{}
-				err = json.Unmarshal(actionBytes, &action)
-				if err != nil {
-					return err
-				}
-				actions = append(actions, &action)
-			}
-		}
+	actions, err := unmarshalStatements(j.Response.Actions)
+	if err != nil {
+		return err
 	}
 
 	o.Label = j.Label
 	o.User = j.User
+	o.Priority = j.Priority
+	o.Disabled = j.Disabled
 	o.Event = j.Event
 	o.Response = ResponsePattern{Actions: actions}
 
 	return nil
 }
+
+// CopyStatements returns a deep copy of the given statements.
+func CopyStatements(statements []Statement) ([]Statement, error) {
+	actions, err := marshalStatements(statements)
+	if err != nil {
+		return nil, err
+	}
+
+	return unmarshalStatements(actions)
+}
+
+func marshalStatements(statements []Statement) ([]map[string][]byte, error) {
+	actions := make([]map[string][]byte, 0)
+	for _, action := range statements {
+		bytes, err := json.Marshal(action)
+		if err != nil {
+			return nil, err
+		}
+
+		actions = append(actions, map[string][]byte{statementName(action): bytes})
+	}
+
+	return actions, nil
+}
+
+func unmarshalStatements(actions []map[string][]byte) ([]Statement, error) {
+	statements := make([]Statement, 0)
+	for _, actionMap := range actions {
+		for actionType, actionBytes := range actionMap {
+			action := newStatement(actionType)
+			if action == nil {
+				continue
+			}
+
+			if err := json.Unmarshal(actionBytes, action); err != nil {
+				return nil, err
+			}
+
+			statements = append(statements, action)
+		}
+	}
+
+	return statements, nil
+}
+
+func statementName(statement Statement) string {
+	switch statement.(type) {
+	case *CreatePolicyStatement:
+		return "CreatePolicyStatement"
+	case *CreateNodeStatement:
+		return "CreateNodeStatement"
+	case *AssignStatement:
+		return "AssignStatement"
+	case *DeassignStatement:
+		return "DeassignStatement"
+	case *DeleteNodeStatement:
+		return "DeleteNodeStatement"
+	case *UpdateNodeStatement:
+		return "UpdateNodeStatement"
+	case *GrantStatement:
+		return "GrantStatement"
+	case *DissociateStatement:
+		return "DissociateStatement"
+	case *DenyStatement:
+		return "DenyStatement"
+	case *DeleteProhibitionStatement:
+		return "DeleteProhibitionStatement"
+	case *SSoDStatement:
+		return "SSoDStatement"
+	case *DSoDStatement:
+		return "DSoDStatement"
+	case *DeleteConstraintStatement:
+		return "DeleteConstraintStatement"
+	case *AssertPermissionStatement:
+		return "AssertPermissionStatement"
+	case *AssertAssignmentStatement:
+		return "AssertAssignmentStatement"
+	case *ObligationStatement:
+		return "ObligationStatement"
+	case *DeleteObligationStatement:
+		return "DeleteObligationStatement"
+	case *EnableObligationStatement:
+		return "EnableObligationStatement"
+	case *DisableObligationStatement:
+		return "DisableObligationStatement"
+	case *IfStatement:
+		return "IfStatement"
+	case *ForeachStatement:
+		return "ForeachStatement"
+	case *EmitStatement:
+		return "EmitStatement"
+	default:
+		return ""
+	}
+}
+
+func newStatement(name string) Statement {
+	switch name {
+	case "CreatePolicyStatement":
+		return &CreatePolicyStatement{}
+	case "CreateNodeStatement":
+		return &CreateNodeStatement{}
+	case "AssignStatement":
+		return &AssignStatement{}
+	case "DeassignStatement":
+		return &DeassignStatement{}
+	case "DeleteNodeStatement":
+		return &DeleteNodeStatement{}
+	case "UpdateNodeStatement":
+		return &UpdateNodeStatement{}
+	case "GrantStatement":
+		return &GrantStatement{}
+	case "DissociateStatement":
+		return &DissociateStatement{}
+	case "DenyStatement":
+		return &DenyStatement{}
+	case "DeleteProhibitionStatement":
+		return &DeleteProhibitionStatement{}
+	case "SSoDStatement":
+		return &SSoDStatement{}
+	case "DSoDStatement":
+		return &DSoDStatement{}
+	case "DeleteConstraintStatement":
+		return &DeleteConstraintStatement{}
+	case "AssertPermissionStatement":
+		return &AssertPermissionStatement{}
+	case "AssertAssignmentStatement":
+		return &AssertAssignmentStatement{}
+	case "ObligationStatement":
+		return &ObligationStatement{}
+	case "DeleteObligationStatement":
+		return &DeleteObligationStatement{}
+	case "EnableObligationStatement":
+		return &EnableObligationStatement{}
+	case "DisableObligationStatement":
+		return &DisableObligationStatement{}
+	case "IfStatement":
+		return &IfStatement{}
+	case "ForeachStatement":
+		return &ForeachStatement{}
+	case "EmitStatement":
+		return &EmitStatement{}
+	default:
+		return nil
+	}
+}
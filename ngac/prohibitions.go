@@ -9,17 +9,26 @@ type (
 	Prohibitions interface {
 		Add(prohibition Prohibition) error
 		Get(subject string) ([]Prohibition, error)
+		GetByName(name string) (Prohibition, error)
+		All() ([]Prohibition, error)
+		// Update replaces the prohibition with the same name as the given prohibition.
+		Update(prohibition Prohibition) error
 		Delete(subject string, prohibitionName string) error
 
 		json.Marshaler
 		json.Unmarshaler
 	}
 
+	// Prohibition denies Operations to Subject on the targets described by Containers. The subject is
+	// a user or user attribute unless Process is true, in which case it is the ID of a process acting on
+	// behalf of a user. The prohibition is only in effect during its Validity.
 	Prohibition struct {
 		Name         string           `json:"name,omitempty"`
 		Subject      string           `json:"subject,omitempty"`
+		Process      bool             `json:"process,omitempty"`
 		Containers   map[string]bool  `json:"containers,omitempty"`
 		Operations   graph.Operations `json:"operations,omitempty"`
 		Intersection bool             `json:"intersection,omitempty"`
+		graph.Validity
 	}
 )
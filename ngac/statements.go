@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/PM-Master/policy-machine-go/ngac/graph"
+	"sort"
 	"strings"
 )
 
@@ -65,30 +66,135 @@ type (
 		Name string `json:"name,omitempty"`
 	}
 
+	// UpdateNodeStatement replaces the properties of a node.
+	UpdateNodeStatement struct {
+		Name       string            `json:"name,omitempty"`
+		Properties map[string]string `json:"properties,omitempty"`
+	}
+
+	jsonUpdateNodeStatement struct {
+		Name       string            `json:"name,omitempty"`
+		Properties map[string]string `json:"properties,omitempty"`
+	}
+
+	// GrantStatement associates Uattr with Target. If Condition is set the association is conditional and if
+	// Validity is set the association is only in effect during that period.
 	GrantStatement struct {
 		Uattr      string           `json:"uattr,omitempty"`
 		Target     string           `json:"target,omitempty"`
 		Operations graph.Operations `json:"operations,omitempty"`
+		Condition  string           `json:"condition,omitempty"`
+		graph.Validity
 	}
 
 	jsonGrantStatement struct {
 		Uattr      string           `json:"uattr,omitempty"`
 		Target     string           `json:"target,omitempty"`
 		Operations graph.Operations `json:"operations,omitempty"`
+		Condition  string           `json:"condition,omitempty"`
+		graph.Validity
+	}
+
+	DissociateStatement struct {
+		Uattr  string `json:"uattr,omitempty"`
+		Target string `json:"target,omitempty"`
+	}
+
+	jsonDissociateStatement struct {
+		Uattr  string `json:"uattr,omitempty"`
+		Target string `json:"target,omitempty"`
 	}
 
 	DenyStatement struct {
+		Name         string           `json:"name,omitempty"`
 		Subject      string           `json:"subject,omitempty"`
+		Process      bool             `json:"process,omitempty"`
 		Operations   graph.Operations `json:"operations,omitempty"`
 		Intersection bool             `json:"intersection,omitempty"`
 		Containers   []string         `json:"containers,omitempty"`
+		graph.Validity
 	}
 
 	jsonDenyStatement struct {
+		Name         string           `json:"name,omitempty"`
 		Subject      string           `json:"subject,omitempty"`
+		Process      bool             `json:"process,omitempty"`
 		Operations   graph.Operations `json:"operations,omitempty"`
 		Intersection bool             `json:"intersection,omitempty"`
 		Containers   []string         `json:"containers,omitempty"`
+		graph.Validity
+	}
+
+	DeleteProhibitionStatement struct {
+		Name string `json:"name,omitempty"`
+	}
+
+	jsonDeleteProhibitionStatement struct {
+		Name string `json:"name,omitempty"`
+	}
+
+	// SSoDStatement adds a static separation of duty constraint.
+	SSoDStatement struct {
+		Name       string   `json:"name,omitempty"`
+		Attributes []string `json:"attributes,omitempty"`
+		Max        int      `json:"max,omitempty"`
+	}
+
+	jsonSSoDStatement struct {
+		Name       string   `json:"name,omitempty"`
+		Attributes []string `json:"attributes,omitempty"`
+		Max        int      `json:"max,omitempty"`
+	}
+
+	// DSoDStatement adds a dynamic separation of duty constraint.
+	DSoDStatement struct {
+		Name       string   `json:"name,omitempty"`
+		Operations []string `json:"operations,omitempty"`
+	}
+
+	jsonDSoDStatement struct {
+		Name       string   `json:"name,omitempty"`
+		Operations []string `json:"operations,omitempty"`
+	}
+
+	// DeleteConstraintStatement removes the ssod constraint with the name or, if Dynamic, the dsod constraint.
+	DeleteConstraintStatement struct {
+		Name    string `json:"name,omitempty"`
+		Dynamic bool   `json:"dynamic,omitempty"`
+	}
+
+	jsonDeleteConstraintStatement struct {
+		Name    string `json:"name,omitempty"`
+		Dynamic bool   `json:"dynamic,omitempty"`
+	}
+
+	// AssertPermissionStatement asserts that User has Operations on Target, or if Negated that the user has none
+	// of them. Assertions do not modify the policy, they are evaluated by the author test runner.
+	AssertPermissionStatement struct {
+		User       string           `json:"user,omitempty"`
+		Operations graph.Operations `json:"operations,omitempty"`
+		Target     string           `json:"target,omitempty"`
+		Negated    bool             `json:"negated,omitempty"`
+	}
+
+	jsonAssertPermissionStatement struct {
+		User       string           `json:"user,omitempty"`
+		Operations graph.Operations `json:"operations,omitempty"`
+		Target     string           `json:"target,omitempty"`
+		Negated    bool             `json:"negated,omitempty"`
+	}
+
+	// AssertAssignmentStatement asserts that Child is contained in Parent, or if Negated that it is not.
+	AssertAssignmentStatement struct {
+		Child   string `json:"child,omitempty"`
+		Parent  string `json:"parent,omitempty"`
+		Negated bool   `json:"negated,omitempty"`
+	}
+
+	jsonAssertAssignmentStatement struct {
+		Child   string `json:"child,omitempty"`
+		Parent  string `json:"parent,omitempty"`
+		Negated bool   `json:"negated,omitempty"`
 	}
 
 	ObligationStatement struct {
@@ -98,6 +204,79 @@ type (
 	jsonObligationStatement struct {
 		Obligation Obligation `json:"obligation"`
 	}
+
+	DeleteObligationStatement struct {
+		Label string `json:"label,omitempty"`
+	}
+
+	jsonDeleteObligationStatement struct {
+		Label string `json:"label,omitempty"`
+	}
+
+	EnableObligationStatement struct {
+		Label string `json:"label,omitempty"`
+	}
+
+	jsonEnableObligationStatement struct {
+		Label string `json:"label,omitempty"`
+	}
+
+	DisableObligationStatement struct {
+		Label string `json:"label,omitempty"`
+	}
+
+	jsonDisableObligationStatement struct {
+		Label string `json:"label,omitempty"`
+	}
+
+	IfStatement struct {
+		Condition Condition   `json:"condition"`
+		Then      []Statement `json:"then,omitempty"`
+		Else      []Statement `json:"else,omitempty"`
+	}
+
+	jsonIfStatement struct {
+		Condition map[string][]byte   `json:"condition"`
+		Then      []map[string][]byte `json:"then,omitempty"`
+		Else      []map[string][]byte `json:"else,omitempty"`
+	}
+
+	// ForeachStatement executes Body once for each child or parent of Node, binding
+	// the node's name to Variable. Variables are bound by the event processor.
+	ForeachStatement struct {
+		Variable string      `json:"variable,omitempty"`
+		Relation Relation    `json:"relation,omitempty"`
+		Node     string      `json:"node,omitempty"`
+		Body     []Statement `json:"body,omitempty"`
+	}
+
+	jsonForeachStatement struct {
+		Variable string              `json:"variable,omitempty"`
+		Relation Relation            `json:"relation,omitempty"`
+		Node     string              `json:"node,omitempty"`
+		Body     []map[string][]byte `json:"body,omitempty"`
+	}
+
+	Relation string
+
+	// EmitStatement emits an event from an obligation response. Emitted events are
+	// processed by the event processor as a cascade of the event that triggered the response.
+	EmitStatement struct {
+		Event  string            `json:"event,omitempty"`
+		Target string            `json:"target,omitempty"`
+		Args   map[string]string `json:"args,omitempty"`
+	}
+
+	jsonEmitStatement struct {
+		Event  string            `json:"event,omitempty"`
+		Target string            `json:"target,omitempty"`
+		Args   map[string]string `json:"args,omitempty"`
+	}
+)
+
+const (
+	Children Relation = "children"
+	Parents  Relation = "parents"
 )
 
 func (c *CreatePolicyStatement) Apply(fe FunctionalEntity) error {
@@ -180,6 +359,29 @@ func (d *DeleteNodeStatement) UnmarshalJSON(bytes []byte) error {
 	return nil
 }
 
+func (u *UpdateNodeStatement) Apply(fe FunctionalEntity) error {
+	return fe.Graph().UpdateNode(u.Name, u.Properties)
+}
+
+func (u *UpdateNodeStatement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&jsonUpdateNodeStatement{
+		Name:       u.Name,
+		Properties: u.Properties,
+	})
+}
+
+func (u *UpdateNodeStatement) UnmarshalJSON(bytes []byte) error {
+	j := &jsonUpdateNodeStatement{}
+	if err := json.Unmarshal(bytes, j); err != nil {
+		return err
+	}
+
+	u.Name = j.Name
+	u.Properties = j.Properties
+
+	return nil
+}
+
 func (a *AssignStatement) Apply(fe FunctionalEntity) error {
 	for _, parent := range a.Parents {
 		if err := fe.Graph().Assign(a.Child, parent); err != nil {
@@ -239,7 +441,18 @@ func (d *DeassignStatement) UnmarshalJSON(bytes []byte) error {
 }
 
 func (g *GrantStatement) Apply(fe FunctionalEntity) error {
-	return fe.Graph().Associate(g.Uattr, g.Target, g.Operations)
+	var err error
+	if g.Condition != "" {
+		err = fe.Graph().AssociateWithCondition(g.Uattr, g.Target, g.Operations, g.Condition)
+	} else {
+		err = fe.Graph().Associate(g.Uattr, g.Target, g.Operations)
+	}
+
+	if err != nil || g.Validity.IsZero() {
+		return err
+	}
+
+	return fe.Graph().SetAssociationValidity(g.Uattr, g.Target, g.Validity)
 }
 
 func (g *GrantStatement) MarshalJSON() ([]byte, error) {
@@ -247,6 +460,8 @@ func (g *GrantStatement) MarshalJSON() ([]byte, error) {
 		Uattr:      g.Uattr,
 		Target:     g.Target,
 		Operations: g.Operations,
+		Condition:  g.Condition,
+		Validity:   g.Validity,
 	})
 }
 
@@ -259,10 +474,59 @@ func (g *GrantStatement) UnmarshalJSON(bytes []byte) error {
 	g.Uattr = j.Uattr
 	g.Target = j.Target
 	g.Operations = j.Operations
+	g.Condition = j.Condition
+	g.Validity = j.Validity
 
 	return nil
 }
 
+func (d *DissociateStatement) Apply(fe FunctionalEntity) error {
+	return fe.Graph().Dissociate(d.Uattr, d.Target)
+}
+
+func (d *DissociateStatement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&jsonDissociateStatement{
+		Uattr:  d.Uattr,
+		Target: d.Target,
+	})
+}
+
+func (d *DissociateStatement) UnmarshalJSON(bytes []byte) error {
+	j := &jsonDissociateStatement{}
+	if err := json.Unmarshal(bytes, j); err != nil {
+		return err
+	}
+
+	d.Uattr = j.Uattr
+	d.Target = j.Target
+
+	return nil
+}
+
+// DenyStatementFor returns the statement that creates the prohibition. Complemented containers are prefixed with !
+// and the containers are sorted by name.
+func DenyStatementFor(prohibition Prohibition) *DenyStatement {
+	containers := make([]string, 0, len(prohibition.Containers))
+	for container, complement := range prohibition.Containers {
+		if complement {
+			container = "!" + container
+		}
+
+		containers = append(containers, container)
+	}
+	sort.Strings(containers)
+
+	return &DenyStatement{
+		Name:         prohibition.Name,
+		Subject:      prohibition.Subject,
+		Process:      prohibition.Process,
+		Operations:   prohibition.Operations,
+		Intersection: prohibition.Intersection,
+		Containers:   containers,
+		Validity:     prohibition.Validity,
+	}
+}
+
 func (d *DenyStatement) Apply(fe FunctionalEntity) error {
 	containers := make(map[string]bool)
 	for _, containerName := range d.Containers {
@@ -274,12 +538,21 @@ func (d *DenyStatement) Apply(fe FunctionalEntity) error {
 		containers[containerName] = complement
 	}
 
+	name := d.Name
+	if name == "" && d.Process {
+		name = fmt.Sprintf("deny-process-%s-%v-on-%v", d.Subject, d.Operations, d.Containers)
+	} else if name == "" {
+		name = fmt.Sprintf("deny-%s-%v-on-%v", d.Subject, d.Operations, d.Containers)
+	}
+
 	prohibition := Prohibition{
-		Name:         fmt.Sprintf("deny-%s-%v-on-%v", d.Subject, d.Operations, d.Containers),
+		Name:         name,
 		Subject:      d.Subject,
+		Process:      d.Process,
 		Containers:   containers,
 		Operations:   d.Operations,
 		Intersection: d.Intersection,
+		Validity:     d.Validity,
 	}
 
 	return fe.Prohibitions().Add(prohibition)
@@ -287,10 +560,13 @@ func (d *DenyStatement) Apply(fe FunctionalEntity) error {
 
 func (d *DenyStatement) MarshalJSON() ([]byte, error) {
 	return json.Marshal(&jsonDenyStatement{
+		Name:         d.Name,
 		Subject:      d.Subject,
+		Process:      d.Process,
 		Operations:   d.Operations,
 		Intersection: d.Intersection,
 		Containers:   d.Containers,
+		Validity:     d.Validity,
 	})
 }
 
@@ -300,10 +576,178 @@ func (d *DenyStatement) UnmarshalJSON(bytes []byte) error {
 		return err
 	}
 
+	d.Name = j.Name
 	d.Subject = j.Subject
+	d.Process = j.Process
 	d.Operations = j.Operations
 	d.Intersection = j.Intersection
 	d.Containers = j.Containers
+	d.Validity = j.Validity
+
+	return nil
+}
+
+func (d *DeleteProhibitionStatement) Apply(fe FunctionalEntity) error {
+	prohibition, err := fe.Prohibitions().GetByName(d.Name)
+	if err != nil {
+		return err
+	}
+
+	return fe.Prohibitions().Delete(prohibition.Subject, prohibition.Name)
+}
+
+func (s *SSoDStatement) Apply(fe FunctionalEntity) error {
+	name := s.Name
+	if name == "" {
+		name = fmt.Sprintf("ssod-%s", strings.Join(s.Attributes, "-"))
+	}
+
+	return fe.Constraints().AddSSoD(SSoD{
+		Name:       name,
+		Attributes: s.Attributes,
+		Max:        s.Max,
+	})
+}
+
+func (s *SSoDStatement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&jsonSSoDStatement{
+		Name:       s.Name,
+		Attributes: s.Attributes,
+		Max:        s.Max,
+	})
+}
+
+func (s *SSoDStatement) UnmarshalJSON(bytes []byte) error {
+	j := &jsonSSoDStatement{}
+	if err := json.Unmarshal(bytes, j); err != nil {
+		return err
+	}
+
+	s.Name = j.Name
+	s.Attributes = j.Attributes
+	s.Max = j.Max
+
+	return nil
+}
+
+func (d *DSoDStatement) Apply(fe FunctionalEntity) error {
+	name := d.Name
+	if name == "" {
+		name = fmt.Sprintf("dsod-%s", strings.Join(d.Operations, "-"))
+	}
+
+	return fe.Constraints().AddDSoD(DSoD{
+		Name:       name,
+		Operations: d.Operations,
+	})
+}
+
+func (d *DSoDStatement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&jsonDSoDStatement{
+		Name:       d.Name,
+		Operations: d.Operations,
+	})
+}
+
+func (d *DSoDStatement) UnmarshalJSON(bytes []byte) error {
+	j := &jsonDSoDStatement{}
+	if err := json.Unmarshal(bytes, j); err != nil {
+		return err
+	}
+
+	d.Name = j.Name
+	d.Operations = j.Operations
+
+	return nil
+}
+
+func (d *DeleteConstraintStatement) Apply(fe FunctionalEntity) error {
+	if d.Dynamic {
+		return fe.Constraints().RemoveDSoD(d.Name)
+	}
+
+	return fe.Constraints().RemoveSSoD(d.Name)
+}
+
+func (d *DeleteConstraintStatement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&jsonDeleteConstraintStatement{Name: d.Name, Dynamic: d.Dynamic})
+}
+
+func (d *DeleteConstraintStatement) UnmarshalJSON(bytes []byte) error {
+	j := &jsonDeleteConstraintStatement{}
+	if err := json.Unmarshal(bytes, j); err != nil {
+		return err
+	}
+
+	d.Name = j.Name
+	d.Dynamic = j.Dynamic
+
+	return nil
+}
+
+func (a *AssertPermissionStatement) Apply(FunctionalEntity) error {
+	return nil
+}
+
+func (a *AssertPermissionStatement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&jsonAssertPermissionStatement{
+		User:       a.User,
+		Operations: a.Operations,
+		Target:     a.Target,
+		Negated:    a.Negated,
+	})
+}
+
+func (a *AssertPermissionStatement) UnmarshalJSON(bytes []byte) error {
+	j := &jsonAssertPermissionStatement{}
+	if err := json.Unmarshal(bytes, j); err != nil {
+		return err
+	}
+
+	a.User = j.User
+	a.Operations = j.Operations
+	a.Target = j.Target
+	a.Negated = j.Negated
+
+	return nil
+}
+
+func (a *AssertAssignmentStatement) Apply(FunctionalEntity) error {
+	return nil
+}
+
+func (a *AssertAssignmentStatement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&jsonAssertAssignmentStatement{
+		Child:   a.Child,
+		Parent:  a.Parent,
+		Negated: a.Negated,
+	})
+}
+
+func (a *AssertAssignmentStatement) UnmarshalJSON(bytes []byte) error {
+	j := &jsonAssertAssignmentStatement{}
+	if err := json.Unmarshal(bytes, j); err != nil {
+		return err
+	}
+
+	a.Child = j.Child
+	a.Parent = j.Parent
+	a.Negated = j.Negated
+
+	return nil
+}
+
+func (d *DeleteProhibitionStatement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&jsonDeleteProhibitionStatement{Name: d.Name})
+}
+
+func (d *DeleteProhibitionStatement) UnmarshalJSON(bytes []byte) error {
+	j := &jsonDeleteProhibitionStatement{}
+	if err := json.Unmarshal(bytes, j); err != nil {
+		return err
+	}
+
+	d.Name = j.Name
 
 	return nil
 }
@@ -328,3 +772,196 @@ func (o *ObligationStatement) UnmarshalJSON(bytes []byte) error {
 
 	return nil
 }
+
+func (d *DeleteObligationStatement) Apply(fe FunctionalEntity) error {
+	return fe.Obligations().Remove(d.Label)
+}
+
+func (d *DeleteObligationStatement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&jsonDeleteObligationStatement{Label: d.Label})
+}
+
+func (d *DeleteObligationStatement) UnmarshalJSON(bytes []byte) error {
+	j := &jsonDeleteObligationStatement{}
+	if err := json.Unmarshal(bytes, j); err != nil {
+		return err
+	}
+
+	d.Label = j.Label
+
+	return nil
+}
+
+func (e *EnableObligationStatement) Apply(fe FunctionalEntity) error {
+	return fe.Obligations().Enable(e.Label)
+}
+
+func (e *EnableObligationStatement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&jsonEnableObligationStatement{Label: e.Label})
+}
+
+func (e *EnableObligationStatement) UnmarshalJSON(bytes []byte) error {
+	j := &jsonEnableObligationStatement{}
+	if err := json.Unmarshal(bytes, j); err != nil {
+		return err
+	}
+
+	e.Label = j.Label
+
+	return nil
+}
+
+func (d *DisableObligationStatement) Apply(fe FunctionalEntity) error {
+	return fe.Obligations().Disable(d.Label)
+}
+
+func (d *DisableObligationStatement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&jsonDisableObligationStatement{Label: d.Label})
+}
+
+func (d *DisableObligationStatement) UnmarshalJSON(bytes []byte) error {
+	j := &jsonDisableObligationStatement{}
+	if err := json.Unmarshal(bytes, j); err != nil {
+		return err
+	}
+
+	d.Label = j.Label
+
+	return nil
+}
+
+func (i *IfStatement) Apply(fe FunctionalEntity) error {
+	ok, err := i.Condition.Evaluate(fe.Graph())
+	if err != nil {
+		return fmt.Errorf("error evaluating condition: %w", err)
+	}
+
+	branch := i.Else
+	if ok {
+		branch = i.Then
+	}
+
+	for _, stmt := range branch {
+		if err = stmt.Apply(fe); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (i *IfStatement) MarshalJSON() ([]byte, error) {
+	var (
+		j   = &jsonIfStatement{}
+		err error
+	)
+
+	if j.Condition, err = marshalCondition(i.Condition); err != nil {
+		return nil, err
+	}
+	if j.Then, err = marshalStatements(i.Then); err != nil {
+		return nil, err
+	}
+	if j.Else, err = marshalStatements(i.Else); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(j)
+}
+
+func (i *IfStatement) UnmarshalJSON(bytes []byte) error {
+	j := &jsonIfStatement{}
+	if err := json.Unmarshal(bytes, j); err != nil {
+		return err
+	}
+
+	var err error
+	if i.Condition, err = unmarshalCondition(j.Condition); err != nil {
+		return err
+	}
+	if i.Then, err = unmarshalStatements(j.Then); err != nil {
+		return err
+	}
+	if i.Else, err = unmarshalStatements(j.Else); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Nodes returns the nodes the statement iterates over.
+func (f *ForeachStatement) Nodes(g Graph) (map[string]graph.Node, error) {
+	switch f.Relation {
+	case Children:
+		return g.GetChildren(f.Node)
+	case Parents:
+		return g.GetParents(f.Node)
+	default:
+		return nil, fmt.Errorf("unknown relation %q", f.Relation)
+	}
+}
+
+// Apply is not supported for foreach statements because the loop variable can only
+// be substituted into the body by the event processor.
+func (f *ForeachStatement) Apply(FunctionalEntity) error {
+	return fmt.Errorf("foreach statements can only be applied by the event processor")
+}
+
+func (f *ForeachStatement) MarshalJSON() ([]byte, error) {
+	body, err := marshalStatements(f.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(&jsonForeachStatement{
+		Variable: f.Variable,
+		Relation: f.Relation,
+		Node:     f.Node,
+		Body:     body,
+	})
+}
+
+func (f *ForeachStatement) UnmarshalJSON(bytes []byte) error {
+	j := &jsonForeachStatement{}
+	if err := json.Unmarshal(bytes, j); err != nil {
+		return err
+	}
+
+	body, err := unmarshalStatements(j.Body)
+	if err != nil {
+		return err
+	}
+
+	f.Variable = j.Variable
+	f.Relation = j.Relation
+	f.Node = j.Node
+	f.Body = body
+
+	return nil
+}
+
+// Apply is not supported for emit statements because only the event processor can process events.
+func (e *EmitStatement) Apply(FunctionalEntity) error {
+	return fmt.Errorf("emit statements can only be applied by the event processor")
+}
+
+func (e *EmitStatement) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&jsonEmitStatement{
+		Event:  e.Event,
+		Target: e.Target,
+		Args:   e.Args,
+	})
+}
+
+func (e *EmitStatement) UnmarshalJSON(bytes []byte) error {
+	j := &jsonEmitStatement{}
+	if err := json.Unmarshal(bytes, j); err != nil {
+		return err
+	}
+
+	e.Event = j.Event
+	e.Target = j.Target
+	e.Args = j.Args
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package ngac
+
+import (
+	"encoding/json"
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
+)
+
+type (
+	// Watchable is implemented by functional entities that publish the changes made to their policy.
+	Watchable interface {
+		// Watch returns a channel that receives every change made after the call, in the order the changes were made,
+		// and a function that ends the subscription and closes the channel.
+		Watch() (<-chan Change, func())
+	}
+
+	ChangeKind string
+
+	// Change describes a change to a policy. Revision numbers the changes of a policy from 1 in the order they were
+	// made. The fields that are set depend on the kind of change.
+	Change struct {
+		Revision uint64     `json:"revision"`
+		Kind     ChangeKind `json:"kind"`
+		// Node is the node after it was created or updated and before it was deleted.
+		Node *graph.Node `json:"node,omitempty"`
+		// Parents are the parents a node was created in.
+		Parents []string `json:"parents,omitempty"`
+		Child   string   `json:"child,omitempty"`
+		Parent  string   `json:"parent,omitempty"`
+		// Subject, Target, Operations, Condition and Validity describe an association after it was created or
+		// changed. Only Subject and Target are set when it is dissociated.
+		Subject     string           `json:"subject,omitempty"`
+		Target      string           `json:"target,omitempty"`
+		Operations  graph.Operations `json:"operations,omitempty"`
+		Condition   string           `json:"condition,omitempty"`
+		Validity    *graph.Validity  `json:"validity,omitempty"`
+		Prohibition *Prohibition     `json:"prohibition,omitempty"`
+		Obligation  *Obligation      `json:"obligation,omitempty"`
+		// Label is the label of a removed, enabled or disabled obligation.
+		Label string `json:"label,omitempty"`
+		SSoD  *SSoD  `json:"ssod,omitempty"`
+		DSoD  *DSoD  `json:"dsod,omitempty"`
+		// Part is the part of the policy that was loaded from Data.
+		Part PolicyPart      `json:"part,omitempty"`
+		Data json.RawMessage `json:"data,omitempty"`
+	}
+
+	PolicyPart string
+)
+
+const (
+	NodeCreated ChangeKind = "NodeCreated"
+	NodeUpdated ChangeKind = "NodeUpdated"
+	NodeDeleted ChangeKind = "NodeDeleted"
+	Assigned    ChangeKind = "Assigned"
+	Deassigned  ChangeKind = "Deassigned"
+	// Associated is published when an association is created or its operations, condition or validity change.
+	Associated         ChangeKind = "Associated"
+	Dissociated        ChangeKind = "Dissociated"
+	ProhibitionAdded   ChangeKind = "ProhibitionAdded"
+	ProhibitionUpdated ChangeKind = "ProhibitionUpdated"
+	ProhibitionDeleted ChangeKind = "ProhibitionDeleted"
+	ObligationAdded    ChangeKind = "ObligationAdded"
+	ObligationRemoved  ChangeKind = "ObligationRemoved"
+	ObligationEnabled  ChangeKind = "ObligationEnabled"
+	ObligationDisabled ChangeKind = "ObligationDisabled"
+	// ConstraintAdded and ConstraintRemoved set SSoD or DSoD. Only the name is set when a constraint is removed.
+	ConstraintAdded   ChangeKind = "ConstraintAdded"
+	ConstraintRemoved ChangeKind = "ConstraintRemoved"
+	// PolicyLoaded is published when a part of the policy is replaced by unmarshaling JSON.
+	PolicyLoaded ChangeKind = "PolicyLoaded"
+
+	GraphPart        PolicyPart = "graph"
+	ProhibitionsPart PolicyPart = "prohibitions"
+	ObligationsPart  PolicyPart = "obligations"
+	ConstraintsPart  PolicyPart = "constraints"
+)
@@ -0,0 +1,61 @@
+package pdp
+
+import (
+	"fmt"
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
+)
+
+// ListPermissionsBatch processes the user side of the graph once and shares the traversal of the target side
+// of the graph between all of the targets. If the user has conditional associations, the traversal is shared between
+// the targets that satisfy the same conditions.
+func (d decider) ListPermissionsBatch(user string, targets []string) (map[string]graph.Operations, error) {
+	userNode, _ := d.graph.GetNode(user)
+	userCtx, err := d.userDAG(userNode, "")
+	if err != nil {
+		return nil, fmt.Errorf("error processing user side of graph for %q: %v", user, err)
+	}
+
+	environment := d.conditionEnvironment()
+	searches := make(map[string]*targetSearch)
+	permissions := make(map[string]graph.Operations, len(targets))
+	for _, target := range targets {
+		if _, ok := permissions[target]; ok {
+			continue
+		}
+
+		targetNode, _ := d.graph.GetNode(target)
+		targetUserCtx, satisfied := d.applyConditions(userCtx, userNode, targetNode, environment)
+		search, ok := searches[satisfied]
+		if !ok {
+			search = d.newTargetSearch(targetUserCtx)
+			searches[satisfied] = search
+		}
+
+		targetCtx, err := search.search(targetNode)
+		if err != nil {
+			return nil, fmt.Errorf("error processing target side of graph for %q: %v", target, err)
+		}
+
+		if permissions[target], err = d.resolvePermissions(userCtx, targetCtx, target); err != nil {
+			return nil, fmt.Errorf("error resolving permissions of %q on %q: %v", user, target, err)
+		}
+	}
+
+	return permissions, nil
+}
+
+func (d decider) FilterAccessible(user string, targets []string, permissions ...string) ([]string, error) {
+	allowed, err := d.ListPermissionsBatch(user, targets)
+	if err != nil {
+		return nil, fmt.Errorf("error filtering targets user %s has permissions %s on: %w", user, permissions, err)
+	}
+
+	accessible := make([]string, 0)
+	for _, target := range targets {
+		if containsAll(allowed[target], permissions) {
+			accessible = append(accessible, target)
+		}
+	}
+
+	return accessible, nil
+}
@@ -0,0 +1,117 @@
+package pdp
+
+import (
+	"fmt"
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
+	"github.com/PM-Master/policy-machine-go/pip/memory"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestListPermissionsBatch(t *testing.T) {
+	g := newProhibitionsGraph(t)
+	prohibitions := memory.NewProhibitions()
+	require.NoError(t, prohibitions.Add(ngac.Prohibition{
+		Name:       "no-writes",
+		Subject:    "ua1",
+		Containers: map[string]bool{"oa3": false},
+		Operations: graph.ToOps("write"),
+	}))
+	decider := NewDecider(g, prohibitions)
+
+	nodes, err := g.GetNodes()
+	require.NoError(t, err)
+	targets := make([]string, 0)
+	for name := range nodes {
+		targets = append(targets, name)
+	}
+
+	batch, err := decider.ListPermissionsBatch("u1", targets)
+	require.NoError(t, err)
+	require.Equal(t, len(targets), len(batch))
+
+	for _, target := range targets {
+		expected, err := decider.ListPermissions("u1", target)
+		require.NoError(t, err)
+		require.Equal(t, expected, batch[target], "unexpected permissions on %s", target)
+	}
+
+	accessible, err := decider.FilterAccessible("u1", []string{"o4", "o1", "o2", "o3"}, "read", "write")
+	require.NoError(t, err)
+	require.Equal(t, []string{"o4", "o2", "o3"}, accessible)
+
+	accessible, err = decider.FilterAccessible("u1", []string{"o1", "o2"}, "read")
+	require.NoError(t, err)
+	require.Equal(t, []string{"o1", "o2"}, accessible)
+}
+
+// newBenchmarkGraph creates a graph with a chain of object attributes under each of two policy classes and
+// folders of objects at the bottom of the chains.
+func newBenchmarkGraph(b *testing.B, depth int, folders int, objects int) (ngac.Graph, []string) {
+	g := memory.NewGraph()
+	require.NoError(b, g.CreatePolicyClass("pc1"))
+	require.NoError(b, g.CreatePolicyClass("pc2"))
+
+	_, err := g.CreateNode("ua1", graph.UserAttribute, nil, "pc1", "pc2")
+	require.NoError(b, err)
+	_, err = g.CreateNode("u1", graph.User, nil, "ua1")
+	require.NoError(b, err)
+
+	// the bottom of each chain
+	parents := make([]string, 0)
+	for _, pc := range []string{"pc1", "pc2"} {
+		parent := pc
+		for i := 0; i < depth; i++ {
+			name := fmt.Sprintf("%s_oa%d", pc, i)
+			_, err = g.CreateNode(name, graph.ObjectAttribute, nil, parent)
+			require.NoError(b, err)
+			require.NoError(b, g.Associate("ua1", name, graph.ToOps("read")))
+			parent = name
+		}
+
+		parents = append(parents, parent)
+	}
+
+	targets := make([]string, 0, objects)
+	for i := 0; i < folders; i++ {
+		folder := fmt.Sprintf("folder%d", i)
+		_, err = g.CreateNode(folder, graph.ObjectAttribute, nil, parents[0], parents[1])
+		require.NoError(b, err)
+
+		for j := 0; j < objects/folders; j++ {
+			name := fmt.Sprintf("%s_o%d", folder, j)
+			_, err = g.CreateNode(name, graph.Object, nil, folder)
+			require.NoError(b, err)
+			targets = append(targets, name)
+		}
+	}
+
+	return g, targets
+}
+
+func BenchmarkListPermissionsLoop(b *testing.B) {
+	g, targets := newBenchmarkGraph(b, 20, 10, 500)
+	decider := NewDecider(g, nil)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, target := range targets {
+			if _, err := decider.ListPermissions("u1", target); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkListPermissionsBatch(b *testing.B) {
+	g, targets := newBenchmarkGraph(b, 20, 10, 500)
+	decider := NewDecider(g, nil)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := decider.ListPermissionsBatch("u1", targets); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package pdp
+
+import (
+	"github.com/PM-Master/policy-machine-go/ngac/expr"
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
+	"strconv"
+	"strings"
+)
+
+type (
+	conditionalAssociation struct {
+		target     string
+		operations graph.Operations
+		expression expr.Expression
+	}
+)
+
+func (d decider) WithEnvironment(environment map[string]string) Decider {
+	d.environment = make(map[string]string, len(environment))
+	for k, v := range environment {
+		d.environment[k] = v
+	}
+
+	return d
+}
+
+// conditionEnvironment returns the environment conditions are evaluated against, setting "time" to the current time
+// formatted as HH:MM if it was not provided.
+func (d decider) conditionEnvironment() map[string]string {
+	environment := make(map[string]string, len(d.environment)+1)
+	for k, v := range d.environment {
+		environment[k] = v
+	}
+
+	if _, ok := environment["time"]; !ok {
+		environment["time"] = d.clock().Format("15:04")
+	}
+
+	return environment
+}
+
+// applyConditions returns the user context with the operations of the conditional associations that are satisfied
+// for the target added to the border targets, and a key identifying which associations were satisfied. A condition
+// that cannot be evaluated, for example because it references a property that is not set, is not satisfied.
+func (d decider) applyConditions(userCtx userContext, user graph.Node, target graph.Node, environment map[string]string) (userContext, string) {
+	if len(userCtx.conditional) == 0 {
+		return userCtx, ""
+	}
+
+	ctx := expr.Context{
+		User:        user.Properties,
+		Object:      target.Properties,
+		Environment: environment,
+	}
+
+	borderTargets := make(map[string]graph.Operations, len(userCtx.borderTargets))
+	for borderTarget, ops := range userCtx.borderTargets {
+		borderTargets[borderTarget] = ops
+	}
+
+	satisfied := make([]string, 0)
+	for i, assoc := range userCtx.conditional {
+		if ok, err := assoc.expression.Evaluate(ctx); err != nil || !ok {
+			continue
+		}
+
+		ops := make(graph.Operations)
+		ops.AddAll(borderTargets[assoc.target])
+		ops.AddAll(assoc.operations)
+		borderTargets[assoc.target] = ops
+
+		satisfied = append(satisfied, strconv.Itoa(i))
+	}
+
+	userCtx.borderTargets = borderTargets
+
+	return userCtx, strings.Join(satisfied, ",")
+}
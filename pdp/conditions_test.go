@@ -0,0 +1,144 @@
+package pdp
+
+import (
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
+	"github.com/PM-Master/policy-machine-go/pip/memory"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func newConditionsGraph(t *testing.T) ngac.Graph {
+	g := memory.NewGraph()
+	require.NoError(t, g.CreatePolicyClass("pc1"))
+	_, err := g.CreateNode("oa1", graph.ObjectAttribute, nil, "pc1")
+	require.NoError(t, err)
+	_, err = g.CreateNode("o1", graph.Object, map[string]string{"level": "2"}, "oa1")
+	require.NoError(t, err)
+	_, err = g.CreateNode("o2", graph.Object, map[string]string{"level": "5"}, "oa1")
+	require.NoError(t, err)
+	_, err = g.CreateNode("o3", graph.Object, nil, "oa1")
+	require.NoError(t, err)
+	_, err = g.CreateNode("ua1", graph.UserAttribute, nil, "pc1")
+	require.NoError(t, err)
+	_, err = g.CreateNode("u1", graph.User, map[string]string{"clearance": "3"}, "ua1")
+	require.NoError(t, err)
+
+	require.NoError(t, g.Associate("ua1", "oa1", graph.ToOps("list")))
+	require.NoError(t, g.AssociateWithCondition("ua1", "oa1", graph.ToOps("read"), "user.clearance >= object.level"))
+
+	return g
+}
+
+func TestConditionalAssociations(t *testing.T) {
+	g := newConditionsGraph(t)
+	decider := NewDecider(g, nil)
+
+	// associating the same subject and target again replaces the association
+	perms, err := decider.ListPermissions("u1", "o1")
+	require.NoError(t, err)
+	require.Equal(t, graph.ToOps("read"), perms)
+	perms, err = decider.ListPermissions("u1", "o2")
+	require.NoError(t, err)
+	require.Empty(t, perms)
+	// o3 does not have a level so the condition is not satisfied
+	perms, err = decider.ListPermissions("u1", "o3")
+	require.NoError(t, err)
+	require.Empty(t, perms)
+
+	_, err = g.CreateNode("ua2", graph.UserAttribute, nil, "pc1")
+	require.NoError(t, err)
+	require.NoError(t, g.Assign("u1", "ua2"))
+	require.NoError(t, g.AssociateWithCondition("ua2", "oa1", graph.ToOps("write"),
+		`env.ip in "10.0.0.0/8" and env.time between "09:00" and "17:00"`))
+
+	tests := []struct {
+		environment map[string]string
+		target      string
+		expected    graph.Operations
+	}{
+		{map[string]string{"ip": "10.0.0.1", "time": "10:00"}, "o1", graph.ToOps("read", "write")},
+		{map[string]string{"ip": "10.0.0.1", "time": "10:00"}, "o2", graph.ToOps("write")},
+		{map[string]string{"ip": "10.0.0.1", "time": "18:00"}, "o1", graph.ToOps("read")},
+		{map[string]string{"ip": "192.168.0.1", "time": "10:00"}, "o3", graph.ToOps()},
+		{nil, "o3", graph.ToOps()},
+	}
+
+	for _, test := range tests {
+		perms, err := decider.WithEnvironment(test.environment).ListPermissions("u1", test.target)
+		require.NoError(t, err)
+		require.Equal(t, test.expected, perms, "environment %v target %s", test.environment, test.target)
+
+		ok, err := decider.WithEnvironment(test.environment).HasPermissions("u1", test.target, "write")
+		require.NoError(t, err)
+		require.Equal(t, test.expected["write"], ok)
+	}
+}
+
+func TestConditionalAssociationsBatch(t *testing.T) {
+	g := newConditionsGraph(t)
+	decider := NewDecider(g, nil).WithEnvironment(map[string]string{"time": "12:00"})
+
+	targets := []string{"o1", "o2", "o3", "oa1"}
+	batch, err := decider.ListPermissionsBatch("u1", targets)
+	require.NoError(t, err)
+
+	for _, target := range targets {
+		perms, err := decider.ListPermissions("u1", target)
+		require.NoError(t, err)
+		require.Equal(t, perms, batch[target], target)
+	}
+
+	require.Equal(t, graph.ToOps("read"), batch["o1"])
+	require.Empty(t, batch["o2"])
+
+	accessible, err := decider.FilterAccessible("u1", targets, "read")
+	require.NoError(t, err)
+	require.Equal(t, []string{"o1"}, accessible)
+}
+
+func TestTimeBoundAssociationsAndProhibitions(t *testing.T) {
+	g := newConditionsGraph(t)
+	require.NoError(t, g.Associate("ua1", "oa1", graph.ToOps("read", "write")))
+
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, g.SetAssociationValidity("ua1", "oa1", graph.Validity{NotBefore: &start, NotAfter: &end}))
+	require.Error(t, g.SetAssociationValidity("ua1", "o1", graph.Validity{NotAfter: &end}))
+
+	prohibitions := memory.NewProhibitions()
+	require.NoError(t, prohibitions.Add(ngac.Prohibition{
+		Name:       "no write in june",
+		Subject:    "u1",
+		Containers: map[string]bool{"oa1": false},
+		Operations: graph.ToOps("write"),
+		Validity: graph.Validity{
+			NotBefore: timePtr(time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)),
+			NotAfter:  timePtr(time.Date(2026, 6, 30, 0, 0, 0, 0, time.UTC)),
+		},
+	}))
+
+	tests := []struct {
+		now      time.Time
+		expected graph.Operations
+	}{
+		{time.Date(2025, 12, 31, 0, 0, 0, 0, time.UTC), graph.ToOps()},
+		{start, graph.ToOps("read", "write")},
+		{time.Date(2026, 6, 15, 0, 0, 0, 0, time.UTC), graph.ToOps("read")},
+		{end, graph.ToOps("read", "write")},
+		{end.Add(time.Second), graph.ToOps()},
+	}
+
+	for _, test := range tests {
+		now := test.now
+		decider := NewDecider(g, prohibitions, WithClock(func() time.Time { return now }))
+		perms, err := decider.ListPermissions("u1", "o1")
+		require.NoError(t, err)
+		require.Equal(t, test.expected, perms, now.String())
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
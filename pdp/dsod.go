@@ -0,0 +1,41 @@
+package pdp
+
+import (
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
+)
+
+// WithDSoD enforces the dynamic separation of duty constraints using the operations recorded in the history.
+func WithDSoD(constraints ngac.Constraints, history ngac.History) Option {
+	return func(d *decider) {
+		d.constraints = constraints
+		d.history = history
+	}
+}
+
+// dsodDenied returns the operations the user cannot perform on the target because of the operations they have
+// already performed on it.
+func (d decider) dsodDenied(user string, target string) (graph.Operations, error) {
+	denied := make(graph.Operations)
+	if d.constraints == nil || d.history == nil {
+		return denied, nil
+	}
+
+	constraints, err := d.constraints.GetDSoD()
+	if err != nil || len(constraints) == 0 {
+		return denied, err
+	}
+
+	performed, err := d.history.Operations(user, target)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, constraint := range constraints {
+		for op := range constraint.Conflicts(performed) {
+			denied.Add(op)
+		}
+	}
+
+	return denied, nil
+}
@@ -0,0 +1,54 @@
+package pdp
+
+import (
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
+	"github.com/PM-Master/policy-machine-go/pip/memory"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestDSoD(t *testing.T) {
+	pip := memory.NewPIP()
+	g := pip.Graph()
+	require.NoError(t, g.CreatePolicyClass("pc1"))
+	_, err := g.CreateNode("requests", graph.ObjectAttribute, nil, "pc1")
+	require.NoError(t, err)
+	_, err = g.CreateNode("r1", graph.Object, nil, "requests")
+	require.NoError(t, err)
+	_, err = g.CreateNode("r2", graph.Object, nil, "requests")
+	require.NoError(t, err)
+	_, err = g.CreateNode("clerks", graph.UserAttribute, nil, "pc1")
+	require.NoError(t, err)
+	_, err = g.CreateNode("u1", graph.User, nil, "clerks")
+	require.NoError(t, err)
+	require.NoError(t, g.Associate("clerks", "requests", graph.ToOps("create", "approve", "read")))
+	require.NoError(t, pip.Constraints().AddDSoD(ngac.DSoD{Name: "create-approve", Operations: []string{"create", "approve"}}))
+
+	history := memory.NewHistory()
+	decider := NewDecider(g, pip.Prohibitions(), WithDSoD(pip.Constraints(), history))
+
+	ok, err := decider.HasPermissions("u1", "r1", "approve")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	require.NoError(t, history.Record(ngac.HistoryEntry{User: "u1", Operation: "create", Target: "r1", Time: time.Now()}))
+
+	perms, err := decider.ListPermissions("u1", "r1")
+	require.NoError(t, err)
+	require.Equal(t, graph.ToOps("create", "read"), perms)
+
+	// the constraint only applies to the object the conflicting operation was performed on
+	batch, err := decider.ListPermissionsBatch("u1", []string{"r1", "r2"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]graph.Operations{
+		"r1": graph.ToOps("create", "read"),
+		"r2": graph.ToOps("create", "approve", "read"),
+	}, batch)
+
+	// without the option the history is not consulted
+	ok, err = NewDecider(g, pip.Prohibitions()).HasPermissions("u1", "r1", "approve")
+	require.NoError(t, err)
+	require.True(t, ok)
+}
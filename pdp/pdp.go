@@ -4,38 +4,85 @@ import (
 	"fmt"
 	"github.com/PM-Master/policy-machine-go/dag"
 	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/ngac/expr"
 	"github.com/PM-Master/policy-machine-go/ngac/graph"
 	"github.com/PM-Master/policy-machine-go/pip/memory"
+	"time"
 )
 
 type (
 	Decider interface {
 		HasPermissions(user string, target string, permissions ...string) (bool, error)
 		ListPermissions(user string, target string) (graph.Operations, error)
+		// DecideFor determines if a process acting on behalf of a user has the permissions on the target.
+		// In addition to the user's prohibitions, any prohibitions on the process are applied.
+		DecideFor(user string, process string, target string, permissions ...string) (bool, error)
+		// ListPermissionsBatch lists the permissions the user has on each of the targets.
+		ListPermissionsBatch(user string, targets []string) (map[string]graph.Operations, error)
+		// FilterAccessible returns the targets the user has all of the permissions on, in the order they were given.
+		FilterAccessible(user string, targets []string, permissions ...string) ([]string, error)
+		// WithEnvironment returns a Decider that evaluates the conditions of conditional associations against the
+		// environment attributes. If the environment does not have a "time" attribute the current time is used.
+		WithEnvironment(environment map[string]string) Decider
 	}
 
 	decider struct {
 		graph        ngac.Graph
 		prohibitions ngac.Prohibitions
+		environment  map[string]string
+		clock        func() time.Time
+		constraints  ngac.Constraints
+		history      ngac.History
 	}
 
+	// Option configures a Decider.
+	Option func(d *decider)
+
 	userContext struct {
+		user          string
 		borderTargets map[string]graph.Operations
 		prohibitions  []ngac.Prohibition
+		// conditional holds the associations whose operations depend on the target and environment of a decision
+		conditional []conditionalAssociation
+	}
+
+	// targetSearch traverses the target side of the graph. The permissions and containers found for each node are
+	// kept so that targets with common ancestors only traverse those ancestors once.
+	targetSearch struct {
+		userCtx          userContext
+		foundPermissions map[string]map[string]graph.Operations
+		containers       map[string]map[string]bool
+		dfs              dag.Searcher
+		// index is used instead of traversing the graph if the graph implements ngac.AncestorIndex
+		index ngac.AncestorIndex
+		graph ngac.Graph
 	}
 
 	targetContext struct {
-		pcSet   map[string]graph.Operations
-		visited map[string]bool
+		pcSet map[string]graph.Operations
+		// containers holds every attribute and policy class the target is contained in
+		containers map[string]bool
 	}
 )
 
-func NewDecider(graph ngac.Graph, prohibitions ngac.Prohibitions) Decider {
+func NewDecider(graph ngac.Graph, prohibitions ngac.Prohibitions, options ...Option) Decider {
 	if prohibitions == nil {
 		prohibitions = memory.NewProhibitions()
 	}
 
-	return decider{graph: graph, prohibitions: prohibitions}
+	d := decider{graph: graph, prohibitions: prohibitions, clock: time.Now}
+	for _, option := range options {
+		option(&d)
+	}
+
+	return d
+}
+
+// WithClock sets the clock used to determine which time bound associations and prohibitions are in effect.
+func WithClock(clock func() time.Time) Option {
+	return func(d *decider) {
+		d.clock = clock
+	}
 }
 
 func (d decider) HasPermissions(user string, target string, permissions ...string) (bool, error) {
@@ -44,16 +91,33 @@ func (d decider) HasPermissions(user string, target string, permissions ...strin
 		return false, fmt.Errorf("error checking if user %s has permissions %s on target %s", user, permissions, target)
 	}
 
+	return containsAll(allowed, permissions), nil
+}
+
+func (d decider) DecideFor(user string, process string, target string, permissions ...string) (bool, error) {
+	allowed, err := d.listPermissions(user, process, target)
+	if err != nil {
+		return false, fmt.Errorf("error checking if process %s of user %s has permissions %s on target %s", process, user, permissions, target)
+	}
+
+	return containsAll(allowed, permissions), nil
+}
+
+func containsAll(allowed graph.Operations, permissions []string) bool {
 	for _, permission := range permissions {
 		if !allowed.Contains(permission) {
-			return false, nil
+			return false
 		}
 	}
 
-	return true, nil
+	return true
 }
 
 func (d decider) ListPermissions(user string, target string) (graph.Operations, error) {
+	return d.listPermissions(user, "", target)
+}
+
+func (d decider) listPermissions(user string, process string, target string) (graph.Operations, error) {
 	var (
 		userCtx   userContext
 		targetCtx targetContext
@@ -62,35 +126,77 @@ func (d decider) ListPermissions(user string, target string) (graph.Operations,
 
 	// process user dag
 	userNode, _ := d.graph.GetNode(user)
-	if userCtx, err = d.userDAG(userNode); err != nil {
+	if userCtx, err = d.userDAG(userNode, process); err != nil {
 		return nil, fmt.Errorf("error processing user side of graph for %q: %v", user, err)
 	}
 
 	// process target dag
 	targetNode, _ := d.graph.GetNode(target)
+	userCtx, _ = d.applyConditions(userCtx, userNode, targetNode, d.conditionEnvironment())
 	if targetCtx, err = d.targetDAG(targetNode, userCtx); err != nil {
 		return nil, fmt.Errorf("error processing target side of graph for %q: %v", target, err)
 	}
 
 	// resolve permissions
-	allowed := d.resolvePermissions(userCtx, targetCtx, target)
+	allowed, err := d.resolvePermissions(userCtx, targetCtx, target)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving permissions of %q on %q: %v", user, target, err)
+	}
 
 	return allowed, nil
 }
 
-func (d decider) userDAG(user graph.Node) (userContext, error) {
+// userDAG collects the associations and prohibitions of the user and, if process is not empty, the prohibitions
+// of the process.
+func (d decider) userDAG(user graph.Node, process string) (userContext, error) {
 	bfs := dag.NewBFS(d.graph)
 	userCtx := userContext{
+		user:          user.Name,
 		borderTargets: make(map[string]graph.Operations),
 		prohibitions:  make([]ngac.Prohibition, 0),
 	}
 
+	now := d.clock()
 	visitor := func(node graph.Node) error {
 		assocs, err := d.graph.GetAssociationsForSubject(node.Name)
 		if err != nil {
 			return err
 		}
 
+		validity, err := d.graph.GetAssociationValidity(node.Name)
+		if err != nil {
+			return err
+		}
+
+		for target, v := range validity {
+			if !v.Active(now) {
+				delete(assocs, target)
+			}
+		}
+
+		conditions, err := d.graph.GetAssociationConditions(node.Name)
+		if err != nil {
+			return err
+		}
+
+		for target, condition := range conditions {
+			if _, ok := assocs[target]; !ok {
+				continue
+			}
+
+			expression, err := expr.Parse(condition)
+			if err != nil {
+				return err
+			}
+
+			userCtx.conditional = append(userCtx.conditional, conditionalAssociation{
+				target:     target,
+				operations: assocs[target],
+				expression: expression,
+			})
+			delete(assocs, target)
+		}
+
 		d.collectAssociations(assocs, userCtx.borderTargets)
 
 		pros, err := d.prohibitions.Get(node.Name)
@@ -98,7 +204,7 @@ func (d decider) userDAG(user graph.Node) (userContext, error) {
 			return err
 		}
 
-		userCtx.prohibitions = append(userCtx.prohibitions, pros...)
+		userCtx.prohibitions = append(userCtx.prohibitions, filterProhibitions(pros, false, now)...)
 
 		return nil
 	}
@@ -111,9 +217,31 @@ func (d decider) userDAG(user graph.Node) (userContext, error) {
 		return userContext{}, err
 	}
 
+	if process != "" {
+		pros, err := d.prohibitions.Get(process)
+		if err != nil {
+			return userContext{}, err
+		}
+
+		userCtx.prohibitions = append(userCtx.prohibitions, filterProhibitions(pros, true, now)...)
+	}
+
 	return userCtx, nil
 }
 
+// filterProhibitions returns the process prohibitions if process is true, otherwise the prohibitions on nodes.
+// Prohibitions that are not in effect at now are excluded.
+func filterProhibitions(prohibitions []ngac.Prohibition, process bool, now time.Time) []ngac.Prohibition {
+	filtered := make([]ngac.Prohibition, 0, len(prohibitions))
+	for _, prohibition := range prohibitions {
+		if prohibition.Process == process && prohibition.Active(now) {
+			filtered = append(filtered, prohibition)
+		}
+	}
+
+	return filtered
+}
+
 func (d decider) collectAssociations(assocs map[string]graph.Operations, borderTargets map[string]graph.Operations) {
 	for target := range assocs {
 		ops := assocs[target]
@@ -128,72 +256,159 @@ func (d decider) collectAssociations(assocs map[string]graph.Operations, borderT
 }
 
 func (d decider) targetDAG(target graph.Node, userCtx userContext) (targetContext, error) {
-	foundPermissions := make(map[string]map[string]graph.Operations)
-	visited := make(map[string]bool)
+	return d.newTargetSearch(userCtx).search(target)
+}
 
-	visitor := func(node graph.Node) error {
-		visited[node.Name] = true
+func (d decider) newTargetSearch(userCtx userContext) *targetSearch {
+	index, _ := d.graph.(ngac.AncestorIndex)
 
-		nodeCtx, ok := foundPermissions[node.Name]
-		if !ok {
-			nodeCtx = make(map[string]graph.Operations)
-			foundPermissions[node.Name] = nodeCtx
+	return &targetSearch{
+		userCtx:          userCtx,
+		foundPermissions: make(map[string]map[string]graph.Operations),
+		containers:       make(map[string]map[string]bool),
+		dfs:              dag.NewDFS(d.graph),
+		index:            index,
+		graph:            d.graph,
+	}
+}
+
+// search returns the context of the target. Nodes visited by previous searches are not traversed again.
+func (s *targetSearch) search(target graph.Node) (targetContext, error) {
+	if s.index != nil {
+		return s.indexedSearch(target)
+	}
+
+	err := s.dfs.Traverse(target, s.propagate, s.visit)
+	return targetContext{
+		pcSet:      s.foundPermissions[target.Name],
+		containers: s.containers[target.Name],
+	}, err
+}
+
+// indexedSearch computes the context of the target from the ancestor index. The operations of each association the
+// user has on the target or one of its ancestors are added to every policy class the association's target is in.
+func (s *targetSearch) indexedSearch(target graph.Node) (targetContext, error) {
+	if ok, err := s.graph.Exists(target.Name); err != nil || !ok {
+		return targetContext{}, err
+	}
+
+	containers, err := s.index.Ancestors(target.Name)
+	if err != nil {
+		return targetContext{}, err
+	}
+
+	pcSet := make(map[string]graph.Operations)
+	if target.Kind == graph.PolicyClass {
+		pcSet[target.Name] = make(graph.Operations)
+	}
+
+	for container := range containers {
+		node, err := s.graph.GetNode(container)
+		if err != nil {
+			return targetContext{}, err
 		}
 
 		if node.Kind == graph.PolicyClass {
-			nodeCtx[node.Name] = make(graph.Operations)
-		} else {
-			ops, ok := userCtx.borderTargets[node.Name]
-			if ok {
-				for pc := range nodeCtx {
-					pcOps := nodeCtx[pc]
-					for op := range ops {
-						pcOps[op] = true
-					}
-					nodeCtx[pc] = pcOps
+			pcSet[container] = make(graph.Operations)
+		}
+	}
+
+	for borderTarget, ops := range s.userCtx.borderTargets {
+		if borderTarget != target.Name && !containers[borderTarget] {
+			continue
+		}
+
+		borderContainers, err := s.index.Ancestors(borderTarget)
+		if err != nil {
+			return targetContext{}, err
+		}
+
+		for pc := range pcSet {
+			if borderContainers[pc] {
+				pcSet[pc].AddAll(ops)
+			}
+		}
+	}
+
+	return targetContext{
+		pcSet:      pcSet,
+		containers: containers,
+	}, nil
+}
+
+func (s *targetSearch) visit(node graph.Node) error {
+	nodeCtx, ok := s.foundPermissions[node.Name]
+	if !ok {
+		nodeCtx = make(map[string]graph.Operations)
+		s.foundPermissions[node.Name] = nodeCtx
+	}
+
+	if node.Kind == graph.PolicyClass {
+		nodeCtx[node.Name] = make(graph.Operations)
+	} else {
+		ops, ok := s.userCtx.borderTargets[node.Name]
+		if ok {
+			for pc := range nodeCtx {
+				pcOps := nodeCtx[pc]
+				for op := range ops {
+					pcOps[op] = true
 				}
+				nodeCtx[pc] = pcOps
 			}
 		}
+	}
 
-		return nil
+	return nil
+}
+
+func (s *targetSearch) propagate(parent graph.Node, child graph.Node) error {
+	childContainers, ok := s.containers[child.Name]
+	if !ok {
+		childContainers = make(map[string]bool)
+		s.containers[child.Name] = childContainers
 	}
 
-	propagator := func(parent graph.Node, child graph.Node) error {
-		parentCtx := foundPermissions[parent.Name]
-		nodeCtx, ok := foundPermissions[child.Name]
+	childContainers[parent.Name] = true
+	for container := range s.containers[parent.Name] {
+		childContainers[container] = true
+	}
+
+	parentCtx := s.foundPermissions[parent.Name]
+	nodeCtx, ok := s.foundPermissions[child.Name]
+	if !ok {
+		nodeCtx = make(map[string]graph.Operations)
+	}
+	for name := range parentCtx {
+		ops, ok := nodeCtx[name]
 		if !ok {
-			nodeCtx = make(map[string]graph.Operations)
+			ops = make(graph.Operations)
 		}
-		for name := range parentCtx {
-			ops, ok := nodeCtx[name]
-			if !ok {
-				ops = make(graph.Operations)
-			}
-
-			parentOps := parentCtx[name]
-			for op := range parentOps {
-				ops[op] = true
-			}
 
-			nodeCtx[name] = ops
+		parentOps := parentCtx[name]
+		for op := range parentOps {
+			ops[op] = true
 		}
 
-		foundPermissions[child.Name] = nodeCtx
-		return nil
+		nodeCtx[name] = ops
 	}
 
-	dfs := dag.NewDFS(d.graph)
-	err := dfs.Traverse(target, propagator, visitor)
-	return targetContext{pcSet: foundPermissions[target.Name]}, err
+	s.foundPermissions[child.Name] = nodeCtx
+	return nil
 }
 
-func (d decider) resolvePermissions(userCtx userContext, targetContext targetContext, target string) graph.Operations {
+func (d decider) resolvePermissions(userCtx userContext, targetContext targetContext, target string) (graph.Operations, error) {
 	allowed := d.allowedPermissions(targetContext)
 	denied := d.deniedPermissions(userCtx, targetContext, target)
 
+	dsodDenied, err := d.dsodDenied(userCtx.user, target)
+	if err != nil {
+		return nil, err
+	}
+
 	allowed.RemoveAll(denied)
+	allowed.RemoveAll(dsodDenied)
 
-	return allowed
+	return allowed, nil
 }
 
 func (d decider) allowedPermissions(ctx targetContext) graph.Operations {
@@ -207,7 +422,10 @@ func (d decider) allowedPermissions(ctx targetContext) graph.Operations {
 			}
 			first = false
 		} else {
-			if allowed[graph.AllOps] {
+			if allowed[graph.AllOps] && ops[graph.AllOps] {
+				// both policy classes allow all operations, keep the result independent of the order of the pcSet
+				allowed.AddAll(ops)
+			} else if allowed[graph.AllOps] {
 				allowed = make(graph.Operations)
 				for op := range ops {
 					allowed[op] = true
@@ -224,46 +442,3 @@ func (d decider) allowedPermissions(ctx targetContext) graph.Operations {
 
 	return allowed
 }
-
-func (d decider) deniedPermissions(userCtx userContext, targetCtx targetContext, target string) graph.Operations {
-	denied := make(graph.Operations)
-	visited := targetCtx.visited
-	prohibitions := userCtx.prohibitions
-
-	for _, prohibition := range prohibitions {
-		isIntersection := prohibition.Intersection
-		containers := prohibition.Containers
-		addOps := false
-
-		for container, complement := range containers {
-			if target == container {
-				addOps = false
-				if isIntersection {
-					break
-				} else {
-					continue
-				}
-			}
-
-			if (!complement && visited[target]) || (complement && !visited[target]) {
-				addOps = true
-
-				if !isIntersection {
-					break
-				}
-			} else {
-				addOps = false
-
-				if isIntersection {
-					break
-				}
-			}
-		}
-
-		if addOps {
-			denied.AddAll(prohibition.Operations)
-		}
-	}
-
-	return denied
-}
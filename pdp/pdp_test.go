@@ -1,8 +1,12 @@
 package pdp
 
 import (
+	"fmt"
+	"github.com/PM-Master/policy-machine-go/ngac"
 	"github.com/PM-Master/policy-machine-go/ngac/graph"
 	"github.com/PM-Master/policy-machine-go/pip/memory"
+	"github.com/stretchr/testify/require"
+	"math/rand"
 	"testing"
 )
 
@@ -61,3 +65,102 @@ func TestPDPAllOps(t *testing.T) {
 		t.Fatal("u1 should have [r] on o1")
 	}
 }
+
+// buildRandomGraph applies a random but reproducible sequence of operations to the graph. Given the same seed it
+// produces the same graph regardless of the graph implementation.
+func buildRandomGraph(t *testing.T, g ngac.Graph, seed int64) (users []string, targets []string) {
+	r := rand.New(rand.NewSource(seed))
+	pick := func(names []string) string {
+		return names[r.Intn(len(names))]
+	}
+
+	pcs := []string{"pc1", "pc2", "pc3"}
+	for _, pc := range pcs {
+		require.NoError(t, g.CreatePolicyClass(pc))
+	}
+
+	oas := make([]string, 0)
+	uas := make([]string, 0)
+	for i := 0; i < 40; i++ {
+		oa := fmt.Sprintf("oa%d", i)
+		_, err := g.CreateNode(oa, graph.ObjectAttribute, nil, pick(append(pcs, oas...)))
+		require.NoError(t, err)
+		oas = append(oas, oa)
+
+		ua := fmt.Sprintf("ua%d", i)
+		_, err = g.CreateNode(ua, graph.UserAttribute, nil, pick(append(pcs, uas...)))
+		require.NoError(t, err)
+		uas = append(uas, ua)
+	}
+
+	objects := make([]string, 0)
+	for i := 0; i < 30; i++ {
+		o := fmt.Sprintf("o%d", i)
+		_, err := g.CreateNode(o, graph.Object, nil, pick(oas), pick(oas))
+		require.NoError(t, err)
+		objects = append(objects, o)
+
+		u := fmt.Sprintf("u%d", i)
+		_, err = g.CreateNode(u, graph.User, nil, pick(uas))
+		require.NoError(t, err)
+		users = append(users, u)
+	}
+
+	ops := []string{"read", "write", "delete", "*"}
+	for i := 0; i < 80; i++ {
+		require.NoError(t, g.Associate(pick(uas), pick(oas), graph.ToOps(pick(ops), pick(ops))))
+	}
+
+	// add and remove assignments, only assigning an attribute to an attribute created before it to avoid cycles
+	for i := 0; i < 30; i++ {
+		child := r.Intn(len(oas)-1) + 1
+		require.NoError(t, g.Assign(oas[child], oas[r.Intn(child)]))
+		require.NoError(t, g.Assign(pick(objects), pick(oas)))
+		require.NoError(t, g.Deassign(pick(objects), pick(oas)))
+		require.NoError(t, g.Deassign(oas[child], pick(oas)))
+	}
+
+	for i := 0; i < 10; i++ {
+		o := objects[0]
+		objects = objects[1:]
+		require.NoError(t, g.DeleteNode(o))
+	}
+
+	return users, append(objects, oas...)
+}
+
+func TestIndexedDeciderConsistency(t *testing.T) {
+	for seed := int64(0); seed < 3; seed++ {
+		traversed := memory.NewGraph()
+		indexed := memory.NewIndexedGraph()
+		users, targets := buildRandomGraph(t, traversed, seed)
+		buildRandomGraph(t, indexed, seed)
+
+		prohibitions := memory.NewProhibitions()
+		require.NoError(t, prohibitions.Add(ngac.Prohibition{
+			Name:       "p1",
+			Subject:    "ua1",
+			Containers: map[string]bool{"oa2": false, "oa3": true},
+			Operations: graph.ToOps("read"),
+		}))
+
+		traversalDecider := NewDecider(traversed, prohibitions)
+		indexedDecider := NewDecider(indexed, prohibitions)
+
+		for _, user := range users {
+			for _, target := range targets {
+				expected, err := traversalDecider.ListPermissions(user, target)
+				require.NoError(t, err)
+				actual, err := indexedDecider.ListPermissions(user, target)
+				require.NoError(t, err)
+				require.Equal(t, expected, actual, "seed %d: permissions of %s on %s", seed, user, target)
+			}
+
+			expected, err := traversalDecider.ListPermissionsBatch(user, targets)
+			require.NoError(t, err)
+			actual, err := indexedDecider.ListPermissionsBatch(user, targets)
+			require.NoError(t, err)
+			require.Equal(t, expected, actual)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package pdp
+
+import (
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
+)
+
+// deniedPermissions returns the operations denied on the target by the prohibitions of the user.
+func (d decider) deniedPermissions(userCtx userContext, targetCtx targetContext, target string) graph.Operations {
+	denied := make(graph.Operations)
+
+	for _, prohibition := range userCtx.prohibitions {
+		if ProhibitionApplies(prohibition, target, targetCtx.containers) {
+			denied.AddAll(prohibition.Operations)
+		}
+	}
+
+	return denied
+}
+
+// ProhibitionApplies determines if a prohibition applies to a target given the set of attributes the target is
+// contained in. A container condition is satisfied if the target is contained in the container or, for a complement
+// container, if the target is not contained in it. A container is not considered to contain itself, so a condition on
+// a container is never satisfied for the container itself. A union prohibition applies if any of its conditions are
+// satisfied and an intersection prohibition applies if all of them are. A prohibition without containers never applies.
+func ProhibitionApplies(prohibition ngac.Prohibition, target string, containers map[string]bool) bool {
+	if len(prohibition.Containers) == 0 {
+		return false
+	}
+
+	for container, complement := range prohibition.Containers {
+		satisfied := false
+		if container != target {
+			satisfied = containers[container] != complement
+		}
+
+		if prohibition.Intersection && !satisfied {
+			return false
+		} else if !prohibition.Intersection && satisfied {
+			return true
+		}
+	}
+
+	return prohibition.Intersection
+}
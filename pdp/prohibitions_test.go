@@ -0,0 +1,213 @@
+package pdp
+
+import (
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
+	"github.com/PM-Master/policy-machine-go/pip/memory"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// newProhibitionsGraph creates the graph used by the prohibition conformance tests:
+//
+//	pc1
+//	├── oa1
+//	│   ├── oa3
+//	│   │   └── o1 (also in oa2)
+//	│   └── o2
+//	├── oa2
+//	│   └── o3
+//	├── oa4
+//	│   └── o4
+//	└── ua1
+//	    └── u1
+//	ua2 (in pc1)
+//	└── u2
+//
+// ua1 and ua2 are granted read and write on oa1, oa2 and oa4.
+func newProhibitionsGraph(t *testing.T) ngac.Graph {
+	g := memory.NewGraph()
+	require.NoError(t, g.CreatePolicyClass("pc1"))
+	for _, oa := range [][]string{{"oa1", "pc1"}, {"oa2", "pc1"}, {"oa4", "pc1"}, {"oa3", "oa1"}} {
+		_, err := g.CreateNode(oa[0], graph.ObjectAttribute, nil, oa[1])
+		require.NoError(t, err)
+	}
+	for _, o := range [][]string{{"o1", "oa3", "oa2"}, {"o2", "oa1"}, {"o3", "oa2"}, {"o4", "oa4"}} {
+		_, err := g.CreateNode(o[0], graph.Object, nil, o[1], o[2:]...)
+		require.NoError(t, err)
+	}
+	for _, ua := range []string{"ua1", "ua2"} {
+		_, err := g.CreateNode(ua, graph.UserAttribute, nil, "pc1")
+		require.NoError(t, err)
+		for _, oa := range []string{"oa1", "oa2", "oa4"} {
+			require.NoError(t, g.Associate(ua, oa, graph.ToOps("read", "write")))
+		}
+	}
+	_, err := g.CreateNode("u1", graph.User, nil, "ua1")
+	require.NoError(t, err)
+	_, err = g.CreateNode("u2", graph.User, nil, "ua2")
+	require.NoError(t, err)
+
+	return g
+}
+
+func TestProhibitionConformance(t *testing.T) {
+	targets := []string{"o1", "o2", "o3", "o4", "oa1", "oa2", "oa3", "oa4"}
+
+	tests := []struct {
+		name         string
+		subject      string
+		containers   map[string]bool
+		intersection bool
+		denied       []string
+	}{
+		{
+			name:       "no containers",
+			subject:    "u1",
+			containers: map[string]bool{},
+			denied:     []string{},
+		},
+		{
+			name:       "single container",
+			subject:    "u1",
+			containers: map[string]bool{"oa1": false},
+			denied:     []string{"o1", "o2", "oa3"},
+		},
+		{
+			name:       "prohibition on user attribute",
+			subject:    "ua1",
+			containers: map[string]bool{"oa1": false},
+			denied:     []string{"o1", "o2", "oa3"},
+		},
+		{
+			name:       "prohibition on another user",
+			subject:    "u2",
+			containers: map[string]bool{"oa1": false},
+			denied:     []string{},
+		},
+		{
+			name:       "nested container",
+			subject:    "u1",
+			containers: map[string]bool{"oa3": false},
+			denied:     []string{"o1"},
+		},
+		{
+			name:       "policy class container",
+			subject:    "u1",
+			containers: map[string]bool{"pc1": false},
+			denied:     targets,
+		},
+		{
+			name:       "union",
+			subject:    "u1",
+			containers: map[string]bool{"oa1": false, "oa2": false},
+			denied:     []string{"o1", "o2", "o3", "oa3"},
+		},
+		{
+			name:         "intersection",
+			subject:      "u1",
+			containers:   map[string]bool{"oa1": false, "oa2": false},
+			intersection: true,
+			denied:       []string{"o1"},
+		},
+		{
+			name:       "complement",
+			subject:    "u1",
+			containers: map[string]bool{"oa1": true},
+			denied:     []string{"o3", "o4", "oa2", "oa4"},
+		},
+		{
+			name:       "union with complement",
+			subject:    "u1",
+			containers: map[string]bool{"oa1": true, "oa2": false},
+			denied:     []string{"o1", "o3", "o4", "oa2", "oa4"},
+		},
+		{
+			name:         "intersection with complement",
+			subject:      "u1",
+			containers:   map[string]bool{"oa1": true, "oa2": false},
+			intersection: true,
+			denied:       []string{"o3"},
+		},
+		{
+			name:       "union of complements",
+			subject:    "u1",
+			containers: map[string]bool{"oa1": true, "oa2": true},
+			denied:     []string{"o2", "o3", "o4", "oa1", "oa2", "oa3", "oa4"},
+		},
+		{
+			name:         "intersection of complements",
+			subject:      "u1",
+			containers:   map[string]bool{"oa1": true, "oa2": true},
+			intersection: true,
+			denied:       []string{"o4", "oa4"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			prohibitions := memory.NewProhibitions()
+			require.NoError(t, prohibitions.Add(ngac.Prohibition{
+				Name:         "prohibition",
+				Subject:      test.subject,
+				Containers:   test.containers,
+				Operations:   graph.ToOps("write"),
+				Intersection: test.intersection,
+			}))
+
+			decider := NewDecider(newProhibitionsGraph(t), prohibitions)
+
+			denied := make(map[string]bool)
+			for _, target := range test.denied {
+				denied[target] = true
+			}
+
+			for _, target := range targets {
+				ops, err := decider.ListPermissions("u1", target)
+				require.NoError(t, err)
+				require.True(t, ops.Contains("read"), "u1 should have read on %s", target)
+				require.Equal(t, !denied[target], ops.Contains("write"), "unexpected write permission on %s", target)
+			}
+		})
+	}
+}
+
+func TestProcessProhibitions(t *testing.T) {
+	prohibitions := memory.NewProhibitions()
+	require.NoError(t, prohibitions.Add(ngac.Prohibition{
+		Name:       "compromised-session",
+		Subject:    "1234",
+		Process:    true,
+		Containers: map[string]bool{"oa1": false},
+		Operations: graph.ToOps("write"),
+	}))
+	// a node prohibition on a subject with the same name as the process should not apply to the process
+	require.NoError(t, prohibitions.Add(ngac.Prohibition{
+		Name:       "node-1234",
+		Subject:    "1234",
+		Containers: map[string]bool{"oa2": false},
+		Operations: graph.ToOps("write"),
+	}))
+
+	decider := NewDecider(newProhibitionsGraph(t), prohibitions)
+
+	ok, err := decider.DecideFor("u1", "1234", "o2", "read")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = decider.DecideFor("u1", "1234", "o2", "write")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	ok, err = decider.DecideFor("u1", "1234", "o3", "write")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = decider.DecideFor("u1", "5678", "o2", "write")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = decider.HasPermissions("u1", "o2", "write")
+	require.NoError(t, err)
+	require.True(t, ok)
+}
@@ -0,0 +1,149 @@
+package memory
+
+import (
+	"encoding/json"
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"sort"
+)
+
+type constraints struct {
+	ssod map[string]ngac.SSoD
+	dsod map[string]ngac.DSoD
+}
+
+func NewConstraints() ngac.Constraints {
+	return &constraints{
+		ssod: make(map[string]ngac.SSoD),
+		dsod: make(map[string]ngac.DSoD),
+	}
+}
+
+func (c *constraints) AddSSoD(constraint ngac.SSoD) error {
+	if _, ok := c.ssod[constraint.Name]; ok {
+		return ngac.AlreadyExistsError("constraint %q already exists", constraint.Name)
+	}
+
+	if err := constraint.Validate(); err != nil {
+		return err
+	}
+
+	attrs := make([]string, len(constraint.Attributes))
+	copy(attrs, constraint.Attributes)
+	constraint.Attributes = attrs
+	c.ssod[constraint.Name] = constraint
+
+	return nil
+}
+
+// GetSSoD returns the ssod constraints sorted by name.
+func (c *constraints) GetSSoD() ([]ngac.SSoD, error) {
+	all := make([]ngac.SSoD, 0, len(c.ssod))
+	for _, constraint := range c.ssod {
+		attrs := make([]string, len(constraint.Attributes))
+		copy(attrs, constraint.Attributes)
+		constraint.Attributes = attrs
+		all = append(all, constraint)
+	}
+
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Name < all[j].Name
+	})
+
+	return all, nil
+}
+
+func (c *constraints) RemoveSSoD(name string) error {
+	if _, ok := c.ssod[name]; !ok {
+		return ngac.NotFoundError("constraint %q does not exist", name)
+	}
+
+	delete(c.ssod, name)
+
+	return nil
+}
+
+func (c *constraints) AddDSoD(constraint ngac.DSoD) error {
+	if _, ok := c.dsod[constraint.Name]; ok {
+		return ngac.AlreadyExistsError("constraint %q already exists", constraint.Name)
+	}
+
+	if err := constraint.Validate(); err != nil {
+		return err
+	}
+
+	ops := make([]string, len(constraint.Operations))
+	copy(ops, constraint.Operations)
+	constraint.Operations = ops
+	c.dsod[constraint.Name] = constraint
+
+	return nil
+}
+
+// GetDSoD returns the dsod constraints sorted by name.
+func (c *constraints) GetDSoD() ([]ngac.DSoD, error) {
+	all := make([]ngac.DSoD, 0, len(c.dsod))
+	for _, constraint := range c.dsod {
+		ops := make([]string, len(constraint.Operations))
+		copy(ops, constraint.Operations)
+		constraint.Operations = ops
+		all = append(all, constraint)
+	}
+
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Name < all[j].Name
+	})
+
+	return all, nil
+}
+
+func (c *constraints) RemoveDSoD(name string) error {
+	if _, ok := c.dsod[name]; !ok {
+		return ngac.NotFoundError("constraint %q does not exist", name)
+	}
+
+	delete(c.dsod, name)
+
+	return nil
+}
+
+type jsonConstraints struct {
+	SSoD []ngac.SSoD `json:"ssod"`
+	DSoD []ngac.DSoD `json:"dsod"`
+}
+
+func (c *constraints) MarshalJSON() ([]byte, error) {
+	ssod, err := c.GetSSoD()
+	if err != nil {
+		return nil, err
+	}
+
+	dsod, err := c.GetDSoD()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(jsonConstraints{SSoD: ssod, DSoD: dsod})
+}
+
+func (c *constraints) UnmarshalJSON(bytes []byte) error {
+	jc := jsonConstraints{}
+	if err := json.Unmarshal(bytes, &jc); err != nil {
+		return err
+	}
+
+	c.ssod = make(map[string]ngac.SSoD)
+	for _, constraint := range jc.SSoD {
+		if err := c.AddSSoD(constraint); err != nil {
+			return err
+		}
+	}
+
+	c.dsod = make(map[string]ngac.DSoD)
+	for _, constraint := range jc.DSoD {
+		if err := c.AddDSoD(constraint); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package memory
+
+import (
+	"errors"
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestConstraints(t *testing.T) {
+	c := NewConstraints()
+	require.NoError(t, c.AddSSoD(ngac.SSoD{Name: "c1", Attributes: []string{"a", "b"}, Max: 1}))
+	require.Error(t, c.AddSSoD(ngac.SSoD{Name: "c1", Attributes: []string{"a", "b"}, Max: 1}))
+	require.Error(t, c.AddSSoD(ngac.SSoD{Name: "c2", Attributes: []string{"a"}, Max: 1}))
+	require.Error(t, c.AddSSoD(ngac.SSoD{Name: "c2", Attributes: []string{"a", "b"}, Max: 2}))
+	require.NoError(t, c.AddSSoD(ngac.SSoD{Name: "c0", Attributes: []string{"a", "b", "c"}, Max: 2}))
+
+	bytes, err := c.MarshalJSON()
+	require.NoError(t, err)
+	c1 := NewConstraints()
+	require.NoError(t, c1.UnmarshalJSON(bytes))
+	ssod, err := c1.GetSSoD()
+	require.NoError(t, err)
+	require.Equal(t, []ngac.SSoD{
+		{Name: "c0", Attributes: []string{"a", "b", "c"}, Max: 2},
+		{Name: "c1", Attributes: []string{"a", "b"}, Max: 1},
+	}, ssod)
+
+	require.NoError(t, c.RemoveSSoD("c0"))
+	require.Error(t, c.RemoveSSoD("c0"))
+}
+
+func TestSSoDEnforcement(t *testing.T) {
+	pip := NewPIP()
+	g := pip.Graph()
+	require.NoError(t, g.CreatePolicyClass("pc1"))
+	for _, ua := range []string{"Requester", "Approver", "Staff", "Managers"} {
+		_, err := g.CreateNode(ua, graph.UserAttribute, nil, "pc1")
+		require.NoError(t, err)
+	}
+	require.NoError(t, pip.Constraints().AddSSoD(ngac.SSoD{
+		Name:       "request-approve",
+		Attributes: []string{"Requester", "Approver"},
+		Max:        1,
+	}))
+
+	_, err := g.CreateNode("u1", graph.User, nil, "Requester")
+	require.NoError(t, err)
+
+	// direct membership
+	err = g.Assign("u1", "Approver")
+	violation := ngac.SSoDViolation{}
+	require.True(t, errors.As(err, &violation))
+	require.Equal(t, ngac.SSoDViolation{
+		Constraint: "request-approve",
+		User:       "u1",
+		Attributes: []string{"Requester", "Approver"},
+	}, violation)
+	parents, err := g.GetParents("u1")
+	require.NoError(t, err)
+	require.Len(t, parents, 1)
+
+	// creating a user in both attributes
+	_, err = g.CreateNode("u2", graph.User, nil, "Requester", "Approver")
+	require.Error(t, err)
+	ok, err := g.Exists("u2")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// indirect membership through a chain of user attributes
+	require.NoError(t, g.Assign("Staff", "Requester"))
+	require.NoError(t, g.Assign("Managers", "Approver"))
+	_, err = g.CreateNode("u3", graph.User, nil, "Managers")
+	require.NoError(t, err)
+	require.Error(t, g.Assign("u3", "Staff"))
+	require.Error(t, g.Assign("Managers", "Staff"))
+	parents, err = g.GetParents("Managers")
+	require.NoError(t, err)
+	require.Len(t, parents, 2)
+}
+
+func TestDSoDConstraints(t *testing.T) {
+	c := NewConstraints()
+	require.NoError(t, c.AddDSoD(ngac.DSoD{Name: "d1", Operations: []string{"create", "approve"}}))
+	require.Error(t, c.AddDSoD(ngac.DSoD{Name: "d1", Operations: []string{"create", "approve"}}))
+	require.Error(t, c.AddDSoD(ngac.DSoD{Name: "d2", Operations: []string{"create"}}))
+
+	bytes, err := c.MarshalJSON()
+	require.NoError(t, err)
+	c1 := NewConstraints()
+	require.NoError(t, c1.UnmarshalJSON(bytes))
+	dsod, err := c1.GetDSoD()
+	require.NoError(t, err)
+	require.Equal(t, []ngac.DSoD{{Name: "d1", Operations: []string{"create", "approve"}}}, dsod)
+
+	require.NoError(t, c.RemoveDSoD("d1"))
+	require.Error(t, c.RemoveDSoD("d1"))
+}
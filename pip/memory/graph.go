@@ -2,8 +2,8 @@ package memory
 
 import (
 	"encoding/json"
-	"fmt"
 	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/ngac/expr"
 	"github.com/PM-Master/policy-machine-go/ngac/graph"
 )
 
@@ -12,20 +12,31 @@ type (
 		nodes        map[string]graph.Node
 		assignments  map[string]map[string]bool
 		associations map[string]map[string]graph.Operations
+		conditions   map[string]map[string]string
+		validity     map[string]map[string]graph.Validity
+		// constraints are checked when users are created and users or user attributes are assigned
+		constraints ngac.Constraints
 	}
 )
 
 func NewGraph() ngac.Graph {
+	return newGraph(nil)
+}
+
+func newGraph(constraints ngac.Constraints) *memgraph {
 	return &memgraph{
+		constraints:  constraints,
 		nodes:        make(map[string]graph.Node),
 		assignments:  make(map[string]map[string]bool),
 		associations: make(map[string]map[string]graph.Operations),
+		conditions:   make(map[string]map[string]string),
+		validity:     make(map[string]map[string]graph.Validity),
 	}
 }
 
 func (g *memgraph) CreatePolicyClass(name string) error {
 	if _, ok := g.nodes[name]; ok {
-		return fmt.Errorf("name %q already exists", name)
+		return ngac.AlreadyExistsError("name %q already exists", name)
 	}
 
 	g.nodes[name] = graph.Node{
@@ -39,49 +50,56 @@ func (g *memgraph) CreatePolicyClass(name string) error {
 
 func (g *memgraph) CreateNode(name string, kind graph.Kind, properties map[string]string, parent string, parents ...string) (graph.Node, error) {
 	if _, ok := g.nodes[name]; ok {
-		return graph.Node{}, fmt.Errorf("name %q already exists", name)
+		return graph.Node{}, ngac.AlreadyExistsError("name %q already exists", name)
 	}
 
 	if properties == nil {
 		properties = make(map[string]string)
 	}
 
-	// create the node
-	n := graph.Node{
-		Name:       name,
-		Kind:       kind,
-		Properties: properties,
-	}
-	node := copyNode(n)
-	g.nodes[name] = node
-
 	// set assignments for the new node
 	assignments := make(map[string]bool)
 
 	// check the initial parent exists
 	if _, ok := g.nodes[parent]; !ok {
-		return graph.Node{}, fmt.Errorf("parent %q does not exist", parent)
+		return graph.Node{}, ngac.NotFoundError("parent %q does not exist", parent)
 	}
 
 	assignments[parent] = true
 
 	// check other parents exist and add to assignments
 	for _, p := range parents {
-		if _, ok := g.nodes[parent]; !ok {
-			return graph.Node{}, fmt.Errorf("parent %q does not exist", parent)
+		if _, ok := g.nodes[p]; !ok {
+			return graph.Node{}, ngac.NotFoundError("parent %q does not exist", p)
 		}
 
 		assignments[p] = true
 	}
 
+	// create the node
+	n := graph.Node{
+		Name:       name,
+		Kind:       kind,
+		Properties: properties,
+	}
+	node := copyNode(n)
+	g.nodes[name] = node
 	g.assignments[name] = assignments
 
+	if kind == graph.User {
+		if err := g.checkConstraints(name); err != nil {
+			delete(g.nodes, name)
+			delete(g.assignments, name)
+			return graph.Node{}, ngac.InvalidError("error creating %q: %w", name, err)
+		}
+	}
+
 	return node, nil
 }
 
 func (g *memgraph) UpdateNode(name string, properties map[string]string) error {
 	if ok, _ := g.Exists(name); !ok {
-		return fmt.Errorf("node %q does not exist", name)
+		return ngac.NotFoundError("node %q does not exist", name)
 	}
 
 	n := g.nodes[name]
@@ -95,7 +113,7 @@ func (g *memgraph) DeleteNode(name string) error {
 	// delete this node's assignments
 	// return an error if this node has other nodes assigned to it still
 	if children, _ := g.GetChildren(name); len(children) > 0 {
-		return fmt.Errorf("cannot delete %q because it has nodes assigned to it", name)
+		return ngac.InvalidError("cannot delete %q because it has nodes assigned to it", name)
 	}
 
 	delete(g.assignments, name)
@@ -108,9 +126,13 @@ func (g *memgraph) DeleteNode(name string) error {
 		}
 
 		delete(g.associations[subject], name)
+		delete(g.conditions[subject], name)
+		delete(g.validity[subject], name)
 	}
 
 	delete(g.associations, name)
+	delete(g.conditions, name)
+	delete(g.validity, name)
 
 	// delete node
 	delete(g.nodes, name)
@@ -135,7 +157,7 @@ func (g *memgraph) GetNodes() (map[string]graph.Node, error) {
 func (g *memgraph) GetNode(name string) (graph.Node, error) {
 	node, ok := g.nodes[name]
 	if !ok {
-		return graph.Node{}, fmt.Errorf("node %q does not exist", name)
+		return graph.Node{}, ngac.NotFoundError("node %q does not exist", name)
 	}
 	return copyNode(node), nil
 }
@@ -177,7 +199,11 @@ func (g *memgraph) Assign(child string, parent string) error {
 	}
 
 	if err = graph.CheckAssignment(childNode.Kind, parentNode.Kind); err != nil {
-		return err
+		return ngac.InvalidError("%w", err)
+	}
+
+	if g.assignments[child][parent] {
+		return nil
 	}
 
 	if _, ok := g.assignments[child]; !ok {
@@ -185,9 +211,67 @@ func (g *memgraph) Assign(child string, parent string) error {
 	}
 	g.assignments[child][parent] = true
 
+	if childNode.Kind == graph.User || childNode.Kind == graph.UserAttribute {
+		if err = g.checkConstraints(child); err != nil {
+			delete(g.assignments[child], parent)
+			return ngac.InvalidError("error assigning %q to %q: %w", child, parent, err)
+		}
+	}
+
 	return nil
 }
 
+// checkConstraints returns the first ssod constraint violation of the users contained in the node.
+func (g *memgraph) checkConstraints(name string) error {
+	if g.constraints == nil {
+		return nil
+	}
+
+	constraints, err := g.constraints.GetSSoD()
+	if err != nil || len(constraints) == 0 {
+		return err
+	}
+
+	for _, user := range g.users(name) {
+		violations, err := ngac.CheckSSoD(g, constraints, user)
+		if err != nil {
+			return err
+		}
+
+		if len(violations) > 0 {
+			return violations[0]
+		}
+	}
+
+	return nil
+}
+
+// users returns the users contained in the node, or the node itself if it is a user.
+func (g *memgraph) users(name string) []string {
+	users := make([]string, 0)
+	visited := map[string]bool{name: true}
+	queue := []string{name}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+
+		if g.nodes[node].Kind == graph.User {
+			users = append(users, node)
+			continue
+		}
+
+		children, _ := g.GetChildren(node)
+		for child := range children {
+			if !visited[child] {
+				visited[child] = true
+				queue = append(queue, child)
+			}
+		}
+	}
+
+	return users
+}
+
 func (g *memgraph) Deassign(child string, parent string) error {
 	delete(g.assignments[child], parent)
 	return nil
@@ -247,19 +331,40 @@ func (g *memgraph) Associate(subject string, target string, operations graph.Ope
 	}
 
 	if err = graph.CheckAssociation(subjectNode.Kind, targetNode.Kind); err != nil {
-		return err
+		return ngac.InvalidError("%w", err)
 	}
 
 	if _, ok := g.associations[subject]; !ok {
 		g.associations[subject] = make(map[string]graph.Operations)
 	}
 	g.associations[subject][target] = copyOps(operations)
+	delete(g.conditions[subject], target)
+	delete(g.validity[subject], target)
+
+	return nil
+}
+
+func (g *memgraph) AssociateWithCondition(subject string, target string, operations graph.Operations, condition string) error {
+	if _, err := expr.Parse(condition); err != nil {
+		return ngac.InvalidError("%w", err)
+	}
+
+	if err := g.Associate(subject, target, operations); err != nil {
+		return err
+	}
+
+	if _, ok := g.conditions[subject]; !ok {
+		g.conditions[subject] = make(map[string]string)
+	}
+	g.conditions[subject][target] = condition
 
 	return nil
 }
 
 func (g *memgraph) Dissociate(subject string, target string) error {
 	delete(g.associations[subject], target)
+	delete(g.conditions[subject], target)
+	delete(g.validity[subject], target)
 	return nil
 }
 
@@ -286,10 +391,48 @@ func (g *memgraph) GetAssociations() (map[string]map[string]graph.Operations, er
 	return assocs, nil
 }
 
+func (g *memgraph) GetAssociationConditions(subject string) (map[string]string, error) {
+	conditions := make(map[string]string)
+	for target, condition := range g.conditions[subject] {
+		conditions[target] = condition
+	}
+
+	return conditions, nil
+}
+
+func (g *memgraph) SetAssociationValidity(subject string, target string, validity graph.Validity) error {
+	if _, ok := g.associations[subject][target]; !ok {
+		return ngac.NotFoundError("association %q -> %q does not exist", subject, target)
+	}
+
+	if validity.IsZero() {
+		delete(g.validity[subject], target)
+		return nil
+	}
+
+	if _, ok := g.validity[subject]; !ok {
+		g.validity[subject] = make(map[string]graph.Validity)
+	}
+	g.validity[subject][target] = validity
+
+	return nil
+}
+
+func (g *memgraph) GetAssociationValidity(subject string) (map[string]graph.Validity, error) {
+	validity := make(map[string]graph.Validity)
+	for target, v := range g.validity[subject] {
+		validity[target] = v
+	}
+
+	return validity, nil
+}
+
 type jsonGraph struct {
 	Nodes        map[string]graph.Node                  `json:"nodes"`
 	Assignments  map[string]map[string]bool             `json:"assignments"`
 	Associations map[string]map[string]graph.Operations `json:"associations"`
+	Conditions   map[string]map[string]string           `json:"conditions,omitempty"`
+	Validity     map[string]map[string]graph.Validity   `json:"validity,omitempty"`
 }
 
 func (g *memgraph) MarshalJSON() ([]byte, error) {
@@ -312,6 +455,28 @@ func (g *memgraph) MarshalJSON() ([]byte, error) {
 		return nil, err
 	}
 
+	jg.Conditions = make(map[string]map[string]string)
+	for subject := range g.conditions {
+		if len(g.conditions[subject]) == 0 {
+			continue
+		}
+
+		if jg.Conditions[subject], err = g.GetAssociationConditions(subject); err != nil {
+			return nil, err
+		}
+	}
+
+	jg.Validity = make(map[string]map[string]graph.Validity)
+	for subject := range g.validity {
+		if len(g.validity[subject]) == 0 {
+			continue
+		}
+
+		if jg.Validity[subject], err = g.GetAssociationValidity(subject); err != nil {
+			return nil, err
+		}
+	}
+
 	return json.Marshal(jg)
 }
 
@@ -331,6 +496,14 @@ func (g *memgraph) UnmarshalJSON(bytes []byte) error {
 	g.nodes = jg.Nodes
 	g.assignments = jg.Assignments
 	g.associations = jg.Associations
+	g.conditions = jg.Conditions
+	if g.conditions == nil {
+		g.conditions = make(map[string]map[string]string)
+	}
+	g.validity = jg.Validity
+	if g.validity == nil {
+		g.validity = make(map[string]map[string]graph.Validity)
+	}
 
 	return nil
 }
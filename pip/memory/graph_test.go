@@ -1,8 +1,11 @@
 package memory
 
 import (
+	"github.com/PM-Master/policy-machine-go/ngac"
 	"github.com/PM-Master/policy-machine-go/ngac/graph"
+	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 )
 
 func TestJSON(t *testing.T) {
@@ -34,3 +37,95 @@ func TestJSON(t *testing.T) {
 		t.Fatal("u1 should exist but does not")
 	}
 }
+
+func TestConditionalAssociations(t *testing.T) {
+	g := NewGraph()
+	require.NoError(t, g.CreatePolicyClass("pc1"))
+	_, err := g.CreateNode("oa1", graph.ObjectAttribute, nil, "pc1")
+	require.NoError(t, err)
+	_, err = g.CreateNode("oa2", graph.ObjectAttribute, nil, "pc1")
+	require.NoError(t, err)
+	_, err = g.CreateNode("ua1", graph.UserAttribute, nil, "pc1")
+	require.NoError(t, err)
+
+	require.Error(t, g.AssociateWithCondition("ua1", "oa1", graph.ToOps("read"), "user.clearance >="))
+	require.NoError(t, g.AssociateWithCondition("ua1", "oa1", graph.ToOps("read"), "user.clearance >= object.level"))
+	require.NoError(t, g.AssociateWithCondition("ua1", "oa2", graph.ToOps("read"), `env.ip in "10.0.0.0/8"`))
+
+	assocs, err := g.GetAssociationsForSubject("ua1")
+	require.NoError(t, err)
+	require.Equal(t, map[string]graph.Operations{"oa1": graph.ToOps("read"), "oa2": graph.ToOps("read")}, assocs)
+
+	b, err := g.MarshalJSON()
+	require.NoError(t, err)
+	g1 := NewGraph()
+	require.NoError(t, g1.UnmarshalJSON(b))
+	conditions, err := g1.GetAssociationConditions("ua1")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"oa1": "user.clearance >= object.level", "oa2": `env.ip in "10.0.0.0/8"`}, conditions)
+
+	// an unconditional association replaces the conditional association
+	require.NoError(t, g.Associate("ua1", "oa1", graph.ToOps("read")))
+	require.NoError(t, g.DeleteNode("oa2"))
+	conditions, err = g.GetAssociationConditions("ua1")
+	require.NoError(t, err)
+	require.Empty(t, conditions)
+}
+
+func TestAssociationValidity(t *testing.T) {
+	g := NewGraph()
+	require.NoError(t, g.CreatePolicyClass("pc1"))
+	_, err := g.CreateNode("oa1", graph.ObjectAttribute, nil, "pc1")
+	require.NoError(t, err)
+	_, err = g.CreateNode("ua1", graph.UserAttribute, nil, "pc1")
+	require.NoError(t, err)
+
+	end := time.Date(2026, 12, 31, 0, 0, 0, 0, time.UTC)
+	require.Error(t, g.SetAssociationValidity("ua1", "oa1", graph.Validity{NotAfter: &end}))
+	require.NoError(t, g.Associate("ua1", "oa1", graph.ToOps("read")))
+	require.NoError(t, g.SetAssociationValidity("ua1", "oa1", graph.Validity{NotAfter: &end}))
+
+	b, err := g.MarshalJSON()
+	require.NoError(t, err)
+	g1 := NewGraph()
+	require.NoError(t, g1.UnmarshalJSON(b))
+	validity, err := g1.GetAssociationValidity("ua1")
+	require.NoError(t, err)
+	require.True(t, end.Equal(*validity["oa1"].NotAfter))
+
+	// associating again clears the validity
+	require.NoError(t, g.Associate("ua1", "oa1", graph.ToOps("read")))
+	validity, err = g.GetAssociationValidity("ua1")
+	require.NoError(t, err)
+	require.Empty(t, validity)
+}
+
+func TestClone(t *testing.T) {
+	pip := NewPIP()
+	require.NoError(t, pip.Graph().CreatePolicyClass("pc1"))
+	_, err := pip.Graph().CreateNode("ua1", graph.UserAttribute, nil, "pc1")
+	require.NoError(t, err)
+	_, err = pip.Graph().CreateNode("ua2", graph.UserAttribute, nil, "pc1")
+	require.NoError(t, err)
+	require.NoError(t, pip.Constraints().AddSSoD(ngac.SSoD{Name: "c1", Attributes: []string{"ua1", "ua2"}, Max: 1}))
+	require.NoError(t, pip.Obligations().Add(ngac.Obligation{
+		Label:    "o1",
+		Response: ngac.ResponsePattern{Actions: []ngac.Statement{&ngac.CreatePolicyStatement{Name: "pc2"}}},
+	}))
+
+	clone, err := Clone(pip)
+	require.NoError(t, err)
+
+	_, err = clone.Graph().CreateNode("u1", graph.User, nil, "ua1")
+	require.NoError(t, err)
+	ok, err := pip.Graph().Exists("u1")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// the constraints of the clone are enforced by the clone's graph
+	require.Error(t, clone.Graph().Assign("u1", "ua2"))
+
+	obligation, err := clone.Obligations().Get("o1")
+	require.NoError(t, err)
+	require.Len(t, obligation.Response.Actions, 1)
+}
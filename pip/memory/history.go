@@ -0,0 +1,75 @@
+package memory
+
+import (
+	"encoding/json"
+	"github.com/PM-Master/policy-machine-go/ngac"
+)
+
+type history struct {
+	entries []ngac.HistoryEntry
+	// operations indexes the operations performed by user and then target
+	operations map[string]map[string]map[string]bool
+}
+
+func NewHistory() ngac.History {
+	return &history{
+		entries:    make([]ngac.HistoryEntry, 0),
+		operations: make(map[string]map[string]map[string]bool),
+	}
+}
+
+func (h *history) Record(entry ngac.HistoryEntry) error {
+	h.entries = append(h.entries, entry)
+
+	targets, ok := h.operations[entry.User]
+	if !ok {
+		targets = make(map[string]map[string]bool)
+		h.operations[entry.User] = targets
+	}
+
+	ops, ok := targets[entry.Target]
+	if !ok {
+		ops = make(map[string]bool)
+		targets[entry.Target] = ops
+	}
+
+	ops[entry.Operation] = true
+
+	return nil
+}
+
+func (h *history) Operations(user string, target string) (map[string]bool, error) {
+	ops := make(map[string]bool)
+	for op := range h.operations[user][target] {
+		ops[op] = true
+	}
+
+	return ops, nil
+}
+
+func (h *history) All() ([]ngac.HistoryEntry, error) {
+	entries := make([]ngac.HistoryEntry, len(h.entries))
+	copy(entries, h.entries)
+	return entries, nil
+}
+
+func (h *history) MarshalJSON() ([]byte, error) {
+	return json.Marshal(h.entries)
+}
+
+func (h *history) UnmarshalJSON(bytes []byte) error {
+	entries := make([]ngac.HistoryEntry, 0)
+	if err := json.Unmarshal(bytes, &entries); err != nil {
+		return err
+	}
+
+	h.entries = make([]ngac.HistoryEntry, 0, len(entries))
+	h.operations = make(map[string]map[string]map[string]bool)
+	for _, entry := range entries {
+		if err := h.Record(entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,35 @@
+package memory
+
+import (
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestHistory(t *testing.T) {
+	h := NewHistory()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, h.Record(ngac.HistoryEntry{User: "u1", Operation: "create", Target: "o1", Time: now}))
+	require.NoError(t, h.Record(ngac.HistoryEntry{User: "u1", Operation: "read", Target: "o1", Time: now}))
+	require.NoError(t, h.Record(ngac.HistoryEntry{User: "u2", Operation: "approve", Target: "o1", Time: now}))
+
+	ops, err := h.Operations("u1", "o1")
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{"create": true, "read": true}, ops)
+	ops, err = h.Operations("u1", "o2")
+	require.NoError(t, err)
+	require.Empty(t, ops)
+
+	bytes, err := h.MarshalJSON()
+	require.NoError(t, err)
+	h1 := NewHistory()
+	require.NoError(t, h1.UnmarshalJSON(bytes))
+	entries, err := h1.All()
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	require.Equal(t, "approve", entries[2].Operation)
+	ops, err = h1.Operations("u2", "o1")
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{"approve": true}, ops)
+}
@@ -0,0 +1,217 @@
+package memory
+
+import (
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
+)
+
+type (
+	// indexedGraph is a memgraph that maintains the ancestors of every node as the graph changes.
+	indexedGraph struct {
+		*memgraph
+		ancestors map[string]map[string]bool
+		children  map[string]map[string]bool
+	}
+)
+
+// NewIndexedGraph returns an in memory graph that implements ngac.AncestorIndex. The index is updated incrementally
+// when nodes are created, assigned, deassigned and deleted, which makes those operations more expensive in exchange
+// for decisions that do not traverse the graph.
+func NewIndexedGraph() ngac.Graph {
+	return &indexedGraph{
+		memgraph:  NewGraph().(*memgraph),
+		ancestors: make(map[string]map[string]bool),
+		children:  make(map[string]map[string]bool),
+	}
+}
+
+func (g *indexedGraph) Ancestors(name string) (map[string]bool, error) {
+	nodeAncestors, ok := g.ancestors[name]
+	if !ok {
+		return nil, ngac.NotFoundError("node %q does not exist", name)
+	}
+
+	ancestors := make(map[string]bool, len(nodeAncestors))
+	for ancestor := range nodeAncestors {
+		ancestors[ancestor] = true
+	}
+
+	return ancestors, nil
+}
+
+func (g *indexedGraph) CreatePolicyClass(name string) error {
+	if err := g.memgraph.CreatePolicyClass(name); err != nil {
+		return err
+	}
+
+	g.ancestors[name] = make(map[string]bool)
+
+	return nil
+}
+
+func (g *indexedGraph) CreateNode(name string, kind graph.Kind, properties map[string]string, parent string, parents ...string) (graph.Node, error) {
+	node, err := g.memgraph.CreateNode(name, kind, properties, parent, parents...)
+	if err != nil {
+		return node, err
+	}
+
+	g.ancestors[name] = make(map[string]bool)
+	for _, p := range append([]string{parent}, parents...) {
+		g.addEdge(name, p)
+	}
+
+	return node, nil
+}
+
+func (g *indexedGraph) DeleteNode(name string) error {
+	parents := g.assignments[name]
+	if err := g.memgraph.DeleteNode(name); err != nil {
+		return err
+	}
+
+	for parent := range parents {
+		delete(g.children[parent], name)
+	}
+
+	delete(g.children, name)
+	delete(g.ancestors, name)
+
+	return nil
+}
+
+func (g *indexedGraph) Assign(child string, parent string) error {
+	if err := g.memgraph.Assign(child, parent); err != nil {
+		return err
+	}
+
+	g.addEdge(child, parent)
+
+	return nil
+}
+
+// addEdge adds the parent and its ancestors to the ancestors of the child and all of the child's descendants.
+func (g *indexedGraph) addEdge(child string, parent string) {
+	if _, ok := g.children[parent]; !ok {
+		g.children[parent] = make(map[string]bool)
+	}
+	g.children[parent][child] = true
+
+	added := map[string]bool{parent: true}
+	for ancestor := range g.ancestors[parent] {
+		added[ancestor] = true
+	}
+
+	for _, node := range g.descendants(child) {
+		for ancestor := range added {
+			g.ancestors[node][ancestor] = true
+		}
+	}
+}
+
+func (g *indexedGraph) Deassign(child string, parent string) error {
+	if err := g.memgraph.Deassign(child, parent); err != nil {
+		return err
+	}
+
+	if _, ok := g.ancestors[child]; !ok {
+		return nil
+	}
+
+	delete(g.children[parent], child)
+
+	// recompute the ancestors of the child and its descendants, parents before children
+	for _, node := range g.descendants(child) {
+		ancestors := make(map[string]bool)
+		for p := range g.assignments[node] {
+			ancestors[p] = true
+			for ancestor := range g.ancestors[p] {
+				ancestors[ancestor] = true
+			}
+		}
+
+		g.ancestors[node] = ancestors
+	}
+
+	return nil
+}
+
+// descendants returns the node and every node contained in it in topological order.
+func (g *indexedGraph) descendants(name string) []string {
+	visited := make(map[string]bool)
+	order := make([]string, 0)
+
+	var visit func(node string)
+	visit = func(node string) {
+		if visited[node] {
+			return
+		}
+
+		visited[node] = true
+		for child := range g.children[node] {
+			visit(child)
+		}
+
+		order = append(order, node)
+	}
+	visit(name)
+
+	// reverse the post order so that every node comes before its children
+	for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
+		order[i], order[j] = order[j], order[i]
+	}
+
+	return order
+}
+
+// UnmarshalJSON into the graph and rebuild the index.
+func (g *indexedGraph) UnmarshalJSON(bytes []byte) error {
+	if err := g.memgraph.UnmarshalJSON(bytes); err != nil {
+		return err
+	}
+
+	g.ancestors = make(map[string]map[string]bool)
+	g.children = make(map[string]map[string]bool)
+	for name := range g.nodes {
+		g.ancestors[name] = make(map[string]bool)
+	}
+
+	for child, parents := range g.assignments {
+		for parent, ok := range parents {
+			if !ok {
+				continue
+			}
+
+			if _, ok = g.children[parent]; !ok {
+				g.children[parent] = make(map[string]bool)
+			}
+			g.children[parent][child] = true
+		}
+	}
+
+	var compute func(name string) map[string]bool
+	compute = func(name string) map[string]bool {
+		ancestors := g.ancestors[name]
+		if len(ancestors) > 0 {
+			return ancestors
+		}
+
+		for parent, ok := range g.assignments[name] {
+			if !ok {
+				continue
+			}
+
+			ancestors[parent] = true
+			for ancestor := range compute(parent) {
+				ancestors[ancestor] = true
+			}
+		}
+
+		return ancestors
+	}
+
+	for name := range g.nodes {
+		compute(name)
+	}
+
+	return nil
+}
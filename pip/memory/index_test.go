@@ -0,0 +1,84 @@
+package memory
+
+import (
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+// traverseAncestors computes the ancestors of a node by traversing the graph.
+func traverseAncestors(t *testing.T, g ngac.Graph, name string) map[string]bool {
+	ancestors := make(map[string]bool)
+	queue := []string{name}
+	for len(queue) > 0 {
+		parents, err := g.GetParents(queue[0])
+		require.NoError(t, err)
+		queue = queue[1:]
+
+		for parent := range parents {
+			if !ancestors[parent] {
+				ancestors[parent] = true
+				queue = append(queue, parent)
+			}
+		}
+	}
+
+	return ancestors
+}
+
+func requireIndexConsistent(t *testing.T, g ngac.Graph) {
+	nodes, err := g.GetNodes()
+	require.NoError(t, err)
+
+	for name := range nodes {
+		ancestors, err := g.(ngac.AncestorIndex).Ancestors(name)
+		require.NoError(t, err)
+		require.Equal(t, traverseAncestors(t, g, name), ancestors, "unexpected ancestors of %s", name)
+	}
+}
+
+func TestIndexedGraph(t *testing.T) {
+	g := NewIndexedGraph()
+	require.NoError(t, g.CreatePolicyClass("pc1"))
+	require.NoError(t, g.CreatePolicyClass("pc2"))
+	_, err := g.CreateNode("oa1", graph.ObjectAttribute, nil, "pc1")
+	require.NoError(t, err)
+	_, err = g.CreateNode("oa2", graph.ObjectAttribute, nil, "oa1")
+	require.NoError(t, err)
+	_, err = g.CreateNode("oa3", graph.ObjectAttribute, nil, "pc2")
+	require.NoError(t, err)
+	_, err = g.CreateNode("o1", graph.Object, nil, "oa2")
+	require.NoError(t, err)
+	_, err = g.CreateNode("o2", graph.Object, nil, "oa9")
+	require.Error(t, err)
+	requireIndexConsistent(t, g)
+
+	ancestors, err := g.(ngac.AncestorIndex).Ancestors("o1")
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{"oa2": true, "oa1": true, "pc1": true}, ancestors)
+
+	// assigning an attribute updates the ancestors of its descendants
+	require.NoError(t, g.Assign("oa2", "oa3"))
+	requireIndexConsistent(t, g)
+	ancestors, err = g.(ngac.AncestorIndex).Ancestors("o1")
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{"oa2": true, "oa1": true, "pc1": true, "oa3": true, "pc2": true}, ancestors)
+
+	require.NoError(t, g.Deassign("oa2", "oa1"))
+	requireIndexConsistent(t, g)
+	ancestors, err = g.(ngac.AncestorIndex).Ancestors("o1")
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{"oa2": true, "oa3": true, "pc2": true}, ancestors)
+
+	require.NoError(t, g.DeleteNode("o1"))
+	requireIndexConsistent(t, g)
+	_, err = g.(ngac.AncestorIndex).Ancestors("o1")
+	require.Error(t, err)
+
+	bytes, err := g.MarshalJSON()
+	require.NoError(t, err)
+	g = NewIndexedGraph()
+	require.NoError(t, g.UnmarshalJSON(bytes))
+	requireIndexConsistent(t, g)
+}
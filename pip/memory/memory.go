@@ -1,19 +1,28 @@
 package memory
 
-import "github.com/PM-Master/policy-machine-go/ngac"
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/PM-Master/policy-machine-go/ngac"
+)
 
 type mempip struct {
 	graph        ngac.Graph
 	prohibitions ngac.Prohibitions
 	obligations  ngac.Obligations
+	constraints  ngac.Constraints
 }
 
+// NewPIP returns an in memory PIP that implements ngac.Watchable. The constraints of the PIP are enforced by its graph.
 func NewPIP() ngac.FunctionalEntity {
-	return mempip{
-		graph:        NewGraph(),
+	constraints := NewConstraints()
+
+	return NewWatchable(mempip{
+		graph:        newGraph(constraints),
 		prohibitions: NewProhibitions(),
 		obligations:  NewObligations(),
-	}
+		constraints:  constraints,
+	})
 }
 
 func (m mempip) Graph() ngac.Graph {
@@ -27,3 +36,94 @@ func (m mempip) Prohibitions() ngac.Prohibitions {
 func (m mempip) Obligations() ngac.Obligations {
 	return m.obligations
 }
+
+func (m mempip) Constraints() ngac.Constraints {
+	return m.constraints
+}
+
+type policy struct {
+	Graph        json.RawMessage `json:"graph"`
+	Prohibitions json.RawMessage `json:"prohibitions"`
+	Obligations  json.RawMessage `json:"obligations"`
+	Constraints  json.RawMessage `json:"constraints"`
+}
+
+// Marshal returns a JSON snapshot of the graph, prohibitions, obligations and constraints of the functional entity.
+func Marshal(fe ngac.FunctionalEntity) ([]byte, error) {
+	p := policy{}
+	parts := []struct {
+		from json.Marshaler
+		to   *json.RawMessage
+	}{
+		{fe.Graph(), &p.Graph},
+		{fe.Prohibitions(), &p.Prohibitions},
+		{fe.Obligations(), &p.Obligations},
+		{fe.Constraints(), &p.Constraints},
+	}
+
+	for _, part := range parts {
+		bytes, err := part.from.MarshalJSON()
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling policy: %w", err)
+		}
+
+		*part.to = bytes
+	}
+
+	return json.Marshal(p)
+}
+
+// Unmarshal returns an in memory PIP holding the policy of a snapshot returned by Marshal.
+func Unmarshal(bytes []byte) (ngac.FunctionalEntity, error) {
+	pip := NewPIP()
+	if err := Restore(pip, bytes); err != nil {
+		return nil, err
+	}
+
+	return pip, nil
+}
+
+// Restore replaces the policy of the functional entity with the policy of a snapshot returned by Marshal.
+func Restore(fe ngac.FunctionalEntity, bytes []byte) error {
+	p := policy{}
+	if err := json.Unmarshal(bytes, &p); err != nil {
+		return fmt.Errorf("error unmarshaling policy: %w", err)
+	}
+
+	parts := []struct {
+		from json.RawMessage
+		to   json.Unmarshaler
+	}{
+		{p.Graph, fe.Graph()},
+		{p.Prohibitions, fe.Prohibitions()},
+		{p.Obligations, fe.Obligations()},
+		{p.Constraints, fe.Constraints()},
+	}
+
+	for _, part := range parts {
+		if len(part.from) == 0 {
+			continue
+		}
+
+		if err := part.to.UnmarshalJSON(part.from); err != nil {
+			return fmt.Errorf("error unmarshaling policy: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Clone returns an in memory copy of the functional entity.
+func Clone(fe ngac.FunctionalEntity) (ngac.FunctionalEntity, error) {
+	bytes, err := Marshal(fe)
+	if err != nil {
+		return nil, fmt.Errorf("error cloning policy: %w", err)
+	}
+
+	clone, err := Unmarshal(bytes)
+	if err != nil {
+		return nil, fmt.Errorf("error cloning policy: %w", err)
+	}
+
+	return clone, nil
+}
@@ -3,52 +3,107 @@ package memory
 import (
 	"encoding/json"
 	"github.com/PM-Master/policy-machine-go/ngac"
+	"sort"
 )
 
 type memobligations struct {
 	obligations map[string]ngac.Obligation
+	// order holds the labels of the obligations in the order they were added
+	order []string
 }
 
 func NewObligations() ngac.Obligations {
 	return &memobligations{
 		obligations: make(map[string]ngac.Obligation),
+		order:       make([]string, 0),
 	}
 }
 
 func (m *memobligations) Add(obligation ngac.Obligation) error {
+	if _, ok := m.obligations[obligation.Label]; ok {
+		return ngac.AlreadyExistsError("obligation %q already exists", obligation.Label)
+	}
+
 	m.obligations[obligation.Label] = obligation
+	m.order = append(m.order, obligation.Label)
 	return nil
 }
 
 func (m *memobligations) Remove(label string) error {
+	if _, ok := m.obligations[label]; !ok {
+		return ngac.NotFoundError("obligation %q does not exist", label)
+	}
+
 	delete(m.obligations, label)
+
+	order := make([]string, 0, len(m.order))
+	for _, l := range m.order {
+		if l != label {
+			order = append(order, l)
+		}
+	}
+	m.order = order
+
 	return nil
 }
 
 func (m *memobligations) Get(label string) (ngac.Obligation, error) {
-	o := m.obligations[label]
+	o, ok := m.obligations[label]
+	if !ok {
+		return ngac.Obligation{}, ngac.NotFoundError("obligation %q does not exist", label)
+	}
+
 	return ngac.Obligation{
 		User:     o.User,
 		Label:    o.Label,
+		Priority: o.Priority,
+		Disabled: o.Disabled,
 		Event:    o.Event,
 		Response: o.Response,
 	}, nil
 }
 
-func (m *memobligations) All() ([]ngac.Obligation, error) {
-	obligations := make([]ngac.Obligation, 0)
+func (m *memobligations) Enable(label string) error {
+	return m.setDisabled(label, false)
+}
 
-	for _, obligation := range m.obligations {
-		obligations = append(obligations, obligation)
+func (m *memobligations) Disable(label string) error {
+	return m.setDisabled(label, true)
+}
+
+func (m *memobligations) setDisabled(label string, disabled bool) error {
+	o, ok := m.obligations[label]
+	if !ok {
+		return ngac.NotFoundError("obligation %q does not exist", label)
 	}
 
+	o.Disabled = disabled
+	m.obligations[label] = o
+
+	return nil
+}
+
+// All returns the obligations sorted by descending priority. Obligations with the same
+// priority are returned in the order they were added.
+func (m *memobligations) All() ([]ngac.Obligation, error) {
+	obligations := make([]ngac.Obligation, 0, len(m.order))
+	for _, label := range m.order {
+		obligations = append(obligations, m.obligations[label])
+	}
+
+	sort.SliceStable(obligations, func(i, j int) bool {
+		return obligations[i].Priority > obligations[j].Priority
+	})
+
 	return obligations, nil
 }
 
+// MarshalJSON returns the obligations in the order they were added so that the order is
+// preserved when unmarshalled.
 func (m *memobligations) MarshalJSON() ([]byte, error) {
-	obligations := make([]ngac.Obligation, 0)
-	for _, obligation := range m.obligations {
-		obligations = append(obligations, obligation)
+	obligations := make([]ngac.Obligation, 0, len(m.order))
+	for _, label := range m.order {
+		obligations = append(obligations, m.obligations[label])
 	}
 
 	return json.Marshal(obligations)
@@ -61,11 +116,17 @@ func (m *memobligations) UnmarshalJSON(bytes []byte) error {
 	}
 
 	obligationsMap := make(map[string]ngac.Obligation)
+	order := make([]string, 0, len(obligationsArr))
 	for _, obligation := range obligationsArr {
+		if _, ok := obligationsMap[obligation.Label]; !ok {
+			order = append(order, obligation.Label)
+		}
+
 		obligationsMap[obligation.Label] = obligation
 	}
 
 	m.obligations = obligationsMap
+	m.order = order
 
 	return nil
 }
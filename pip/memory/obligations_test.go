@@ -1,6 +1,7 @@
 package memory
 
 import (
+	"fmt"
 	"github.com/PM-Master/policy-machine-go/ngac"
 	"github.com/PM-Master/policy-machine-go/ngac/graph"
 	"github.com/stretchr/testify/require"
@@ -13,7 +14,7 @@ func TestName(t *testing.T) {
 		Label: "test",
 		Event: ngac.EventPattern{
 			Subject:    "ANY_USER",
-			Operations: []ngac.EventOperation{{"test", []string{"arg1"}}},
+			Operations: []ngac.EventOperation{{Operation: "test", Args: []string{"arg1"}}},
 			Containers: []string{"!oa1", "oa2"},
 		},
 		Response: ngac.ResponsePattern{
@@ -57,7 +58,7 @@ func TestJson(t *testing.T) {
 		Label: "test",
 		Event: ngac.EventPattern{
 			Subject:    "ANY_USER",
-			Operations: []ngac.EventOperation{{"test", []string{"arg1"}}},
+			Operations: []ngac.EventOperation{{Operation: "test", Args: []string{"arg1"}}},
 			Containers: []string{"!oa1", "oa2"},
 		},
 		Response: ngac.ResponsePattern{
@@ -80,3 +81,128 @@ func TestJson(t *testing.T) {
 	err = obligations.UnmarshalJSON(bytes)
 	require.NoError(t, err)
 }
+
+func TestConditionalStatementsJSON(t *testing.T) {
+	o := &ngac.Obligation{
+		Label: "test",
+		Event: ngac.EventPattern{
+			Subject:    "ANY_USER",
+			Operations: []ngac.EventOperation{{Operation: "test"}},
+			Containers: []string{},
+		},
+		Response: ngac.ResponsePattern{
+			Actions: []ngac.Statement{
+				&ngac.IfStatement{
+					Condition: &ngac.NotCondition{Condition: &ngac.ExistsCondition{Name: "ua1"}},
+					Then: []ngac.Statement{
+						&ngac.CreateNodeStatement{Name: "ua1", Kind: graph.UserAttribute, Parents: []string{"pc1"}},
+					},
+					Else: []ngac.Statement{
+						&ngac.IfStatement{
+							Condition: &ngac.InCondition{Child: "$user", Parent: "ua1"},
+							Then:      []ngac.Statement{&ngac.DeleteNodeStatement{Name: "ua1"}},
+							Else:      []ngac.Statement{},
+						},
+					},
+				},
+				&ngac.ForeachStatement{
+					Variable: "child",
+					Relation: ngac.Children,
+					Node:     "oa1",
+					Body:     []ngac.Statement{&ngac.AssignStatement{Child: "$child", Parents: []string{"oa2"}}},
+				},
+			},
+		},
+	}
+	bytes, err := o.MarshalJSON()
+	require.NoError(t, err)
+
+	o2 := &ngac.Obligation{}
+	err = o2.UnmarshalJSON(bytes)
+	require.NoError(t, err)
+	require.Equal(t, o, o2)
+}
+
+func TestObligationLifecycle(t *testing.T) {
+	obligations := NewObligations()
+	obligation := ngac.Obligation{
+		Label: "obl1",
+		Event: ngac.EventPattern{
+			Subject:    "ANY_USER",
+			Operations: []ngac.EventOperation{{Operation: "op1"}},
+		},
+		Response: ngac.ResponsePattern{Actions: []ngac.Statement{}},
+	}
+
+	require.NoError(t, obligations.Add(obligation))
+	require.Error(t, obligations.Add(obligation))
+
+	_, err := obligations.Get("obl2")
+	require.Error(t, err)
+	require.Error(t, obligations.Remove("obl2"))
+	require.Error(t, obligations.Disable("obl2"))
+	require.Error(t, obligations.Enable("obl2"))
+
+	require.NoError(t, obligations.Disable("obl1"))
+	o, err := obligations.Get("obl1")
+	require.NoError(t, err)
+	require.True(t, o.Disabled)
+
+	bytes, err := obligations.MarshalJSON()
+	require.NoError(t, err)
+	obligations = NewObligations()
+	require.NoError(t, obligations.UnmarshalJSON(bytes))
+	o, err = obligations.Get("obl1")
+	require.NoError(t, err)
+	require.True(t, o.Disabled)
+
+	require.NoError(t, obligations.Enable("obl1"))
+	o, err = obligations.Get("obl1")
+	require.NoError(t, err)
+	require.False(t, o.Disabled)
+
+	require.NoError(t, obligations.Remove("obl1"))
+	_, err = obligations.Get("obl1")
+	require.Error(t, err)
+}
+
+func TestObligationOrder(t *testing.T) {
+	obligations := NewObligations()
+	expected := make([]string, 0)
+	for i := 0; i < 20; i++ {
+		label := fmt.Sprintf("obl%d", i)
+		require.NoError(t, obligations.Add(ngac.Obligation{Label: label}))
+		expected = append(expected, label)
+	}
+
+	labels := func(obligations ngac.Obligations) []string {
+		all, err := obligations.All()
+		require.NoError(t, err)
+
+		labels := make([]string, 0)
+		for _, o := range all {
+			labels = append(labels, o.Label)
+		}
+		return labels
+	}
+
+	for i := 0; i < 10; i++ {
+		require.Equal(t, expected, labels(obligations))
+	}
+
+	bytes, err := obligations.MarshalJSON()
+	require.NoError(t, err)
+	unmarshalled := NewObligations()
+	require.NoError(t, unmarshalled.UnmarshalJSON(bytes))
+	require.Equal(t, expected, labels(unmarshalled))
+
+	require.NoError(t, obligations.Remove("obl5"))
+	require.NoError(t, obligations.Add(ngac.Obligation{Label: "obl5"}))
+	require.NoError(t, obligations.Add(ngac.Obligation{Label: "high", Priority: 10}))
+	require.NoError(t, obligations.Add(ngac.Obligation{Label: "low", Priority: -1}))
+
+	expected = append([]string{"high"}, expected[:5]...)
+	expected = append(expected, "obl6", "obl7", "obl8", "obl9", "obl10", "obl11", "obl12", "obl13", "obl14",
+		"obl15", "obl16", "obl17", "obl18", "obl19", "obl5", "low")
+	require.Equal(t, expected, labels(obligations))
+}
@@ -3,6 +3,7 @@ package memory
 import (
 	"encoding/json"
 	"github.com/PM-Master/policy-machine-go/ngac"
+	"sort"
 )
 
 type prohibitions struct {
@@ -21,6 +22,10 @@ func (p *prohibitions) Add(prohibition ngac.Prohibition) error {
 		ok          bool
 	)
 
+	if _, err := p.GetByName(prohibition.Name); err == nil {
+		return ngac.AlreadyExistsError("prohibition %q already exists", prohibition.Name)
+	}
+
 	if subjectPros, ok = p.prohibitions[prohibition.Subject]; !ok {
 		subjectPros = make([]ngac.Prohibition, 0)
 	}
@@ -32,7 +37,59 @@ func (p *prohibitions) Add(prohibition ngac.Prohibition) error {
 }
 
 func (p *prohibitions) Get(subject string) ([]ngac.Prohibition, error) {
-	return p.prohibitions[subject], nil
+	subjectPros := make([]ngac.Prohibition, len(p.prohibitions[subject]))
+	copy(subjectPros, p.prohibitions[subject])
+	return subjectPros, nil
+}
+
+func (p *prohibitions) GetByName(name string) (ngac.Prohibition, error) {
+	for _, subjectPros := range p.prohibitions {
+		for _, prohibition := range subjectPros {
+			if prohibition.Name == name {
+				return prohibition, nil
+			}
+		}
+	}
+
+	return ngac.Prohibition{}, ngac.NotFoundError("prohibition %q does not exist", name)
+}
+
+// All returns every prohibition sorted by name.
+func (p *prohibitions) All() ([]ngac.Prohibition, error) {
+	all := make([]ngac.Prohibition, 0)
+	for _, subjectPros := range p.prohibitions {
+		all = append(all, subjectPros...)
+	}
+
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].Name < all[j].Name
+	})
+
+	return all, nil
+}
+
+func (p *prohibitions) Update(prohibition ngac.Prohibition) error {
+	existing, err := p.GetByName(prohibition.Name)
+	if err != nil {
+		return err
+	}
+
+	if existing.Subject != prohibition.Subject {
+		if err = p.Delete(existing.Subject, existing.Name); err != nil {
+			return err
+		}
+
+		return p.Add(prohibition)
+	}
+
+	subjectPros := p.prohibitions[prohibition.Subject]
+	for i, pro := range subjectPros {
+		if pro.Name == prohibition.Name {
+			subjectPros[i] = prohibition
+		}
+	}
+
+	return nil
 }
 
 func (p *prohibitions) Delete(subject string, prohibitionName string) error {
@@ -46,7 +103,12 @@ func (p *prohibitions) Delete(subject string, prohibitionName string) error {
 		newSubjectPros = append(newSubjectPros, p)
 	}
 
-	p.prohibitions[subject] = newSubjectPros
+	if len(newSubjectPros) == 0 {
+		delete(p.prohibitions, subject)
+	} else {
+		p.prohibitions[subject] = newSubjectPros
+	}
+
 	return nil
 }
 
@@ -55,17 +117,12 @@ type jsonProhibitions struct {
 }
 
 func (p *prohibitions) MarshalJSON() ([]byte, error) {
-	jp := jsonProhibitions{
-		Prohibitions: make([]ngac.Prohibition, 0),
-	}
-
-	for _, subjectProhibitions := range p.prohibitions {
-		for _, prohibition := range subjectProhibitions {
-			jp.Prohibitions = append(jp.Prohibitions, prohibition)
-		}
+	all, err := p.All()
+	if err != nil {
+		return nil, err
 	}
 
-	return json.Marshal(jp)
+	return json.Marshal(jsonProhibitions{Prohibitions: all})
 }
 
 func (p *prohibitions) UnmarshalJSON(bytes []byte) error {
@@ -36,3 +36,60 @@ func TestMarshalUnmarshal(t *testing.T) {
 	require.Equal(t, graph.ToOps("read", "write"), prohibition.Operations)
 	require.True(t, prohibition.Intersection)
 }
+
+func TestProhibitionsByName(t *testing.T) {
+	prohibitions := NewProhibitions()
+	require.NoError(t, prohibitions.Add(ngac.Prohibition{
+		Name:       "p2",
+		Subject:    "ua1",
+		Containers: map[string]bool{"oa1": false},
+		Operations: graph.ToOps("read"),
+	}))
+	require.NoError(t, prohibitions.Add(ngac.Prohibition{
+		Name:       "p1",
+		Subject:    "ua2",
+		Containers: map[string]bool{"oa1": false},
+		Operations: graph.ToOps("write"),
+	}))
+	require.Error(t, prohibitions.Add(ngac.Prohibition{Name: "p1", Subject: "ua3"}))
+
+	all, err := prohibitions.All()
+	require.NoError(t, err)
+	require.Equal(t, 2, len(all))
+	require.Equal(t, "p1", all[0].Name)
+	require.Equal(t, "p2", all[1].Name)
+
+	p, err := prohibitions.GetByName("p2")
+	require.NoError(t, err)
+	require.Equal(t, "ua1", p.Subject)
+	_, err = prohibitions.GetByName("p3")
+	require.Error(t, err)
+
+	// update in place
+	p.Operations = graph.ToOps("read", "write")
+	require.NoError(t, prohibitions.Update(p))
+	p, err = prohibitions.GetByName("p2")
+	require.NoError(t, err)
+	require.Equal(t, graph.ToOps("read", "write"), p.Operations)
+
+	// update the subject
+	p.Subject = "ua2"
+	require.NoError(t, prohibitions.Update(p))
+	subjectPros, err := prohibitions.Get("ua1")
+	require.NoError(t, err)
+	require.Empty(t, subjectPros)
+	subjectPros, err = prohibitions.Get("ua2")
+	require.NoError(t, err)
+	require.Equal(t, 2, len(subjectPros))
+
+	require.Error(t, prohibitions.Update(ngac.Prohibition{Name: "p3"}))
+
+	// deleting a prohibition that does not exist for the subject is a no-op
+	require.NoError(t, prohibitions.Delete("ua1", "p2"))
+	_, err = prohibitions.GetByName("p2")
+	require.NoError(t, err)
+
+	require.NoError(t, prohibitions.Delete("ua2", "p2"))
+	_, err = prohibitions.GetByName("p2")
+	require.Error(t, err)
+}
@@ -0,0 +1,627 @@
+package memory
+
+import (
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
+	"sync"
+)
+
+type (
+	watchable struct {
+		graph        ngac.Graph
+		prohibitions ngac.Prohibitions
+		obligations  ngac.Obligations
+		constraints  ngac.Constraints
+		hub          *hub
+	}
+
+	// hub numbers the changes made to a policy and publishes them to its subscribers.
+	hub struct {
+		mu          sync.Mutex
+		revision    uint64
+		subscribers map[*subscriber]bool
+	}
+
+	// subscriber queues the changes that have not been received yet, so a slow subscriber neither blocks changes to
+	// the policy nor misses any of them.
+	subscriber struct {
+		mu      sync.Mutex
+		cond    *sync.Cond
+		queue   []ngac.Change
+		done    bool
+		stop    chan struct{}
+		once    sync.Once
+		changes chan ngac.Change
+	}
+
+	watchedGraph struct {
+		ngac.Graph
+		hub *hub
+	}
+
+	// indexedWatchedGraph is a watchedGraph of a graph that implements ngac.AncestorIndex, so the decider can still use
+	// the index.
+	indexedWatchedGraph struct {
+		watchedGraph
+		index ngac.AncestorIndex
+	}
+
+	watchedProhibitions struct {
+		ngac.Prohibitions
+		hub *hub
+	}
+
+	watchedObligations struct {
+		ngac.Obligations
+		hub *hub
+	}
+
+	watchedConstraints struct {
+		ngac.Constraints
+		hub *hub
+	}
+)
+
+// NewWatchable returns a functional entity implementing ngac.Watchable that publishes the changes made through it to
+// the given functional entity. NewPIP is watchable, other backends can be made watchable with NewWatchable. If the
+// graph of the functional entity implements ngac.AncestorIndex, so does the graph of the watchable.
+func NewWatchable(fe ngac.FunctionalEntity) ngac.FunctionalEntity {
+	h := &hub{subscribers: make(map[*subscriber]bool)}
+
+	var g ngac.Graph = watchedGraph{Graph: fe.Graph(), hub: h}
+	if index, ok := fe.Graph().(ngac.AncestorIndex); ok {
+		g = indexedWatchedGraph{watchedGraph: g.(watchedGraph), index: index}
+	}
+
+	return watchable{
+		graph:        g,
+		prohibitions: watchedProhibitions{Prohibitions: fe.Prohibitions(), hub: h},
+		obligations:  watchedObligations{Obligations: fe.Obligations(), hub: h},
+		constraints:  watchedConstraints{Constraints: fe.Constraints(), hub: h},
+		hub:          h,
+	}
+}
+
+func (w watchable) Graph() ngac.Graph {
+	return w.graph
+}
+
+func (w watchable) Prohibitions() ngac.Prohibitions {
+	return w.prohibitions
+}
+
+func (w watchable) Obligations() ngac.Obligations {
+	return w.obligations
+}
+
+func (w watchable) Constraints() ngac.Constraints {
+	return w.constraints
+}
+
+func (w watchable) Watch() (<-chan ngac.Change, func()) {
+	s := &subscriber{
+		queue:   make([]ngac.Change, 0),
+		stop:    make(chan struct{}),
+		changes: make(chan ngac.Change),
+	}
+	s.cond = sync.NewCond(&s.mu)
+
+	w.hub.mu.Lock()
+	w.hub.subscribers[s] = true
+	w.hub.mu.Unlock()
+
+	go s.run()
+
+	return s.changes, func() {
+		w.hub.mu.Lock()
+		delete(w.hub.subscribers, s)
+		w.hub.mu.Unlock()
+
+		s.cancel()
+	}
+}
+
+// apply makes a change to the policy and publishes the changes it returns if it succeeds. Changes are applied one at
+// a time so they are published in the order they were made. A change that leaves the policy as it was returns no
+// changes. The published changes are copies, so they do not share maps or slices with the policy or the caller.
+func (h *hub) apply(change func() ([]ngac.Change, error)) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	changes, err := change()
+	if err != nil {
+		return err
+	}
+
+	for _, c := range changes {
+		c = copyChange(c)
+		h.revision++
+		c.Revision = h.revision
+		for s := range h.subscribers {
+			s.publish(c)
+		}
+	}
+
+	return nil
+}
+
+func (s *subscriber) publish(change ngac.Change) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.queue = append(s.queue, change)
+	s.cond.Signal()
+}
+
+func (s *subscriber) run() {
+	defer close(s.changes)
+
+	for {
+		s.mu.Lock()
+		for len(s.queue) == 0 && !s.done {
+			s.cond.Wait()
+		}
+
+		if s.done {
+			s.mu.Unlock()
+			return
+		}
+
+		change := s.queue[0]
+		s.queue = s.queue[1:]
+		s.mu.Unlock()
+
+		select {
+		case s.changes <- change:
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+func (s *subscriber) cancel() {
+	s.once.Do(func() {
+		s.mu.Lock()
+		s.done = true
+		s.cond.Signal()
+		s.mu.Unlock()
+
+		close(s.stop)
+	})
+}
+
+func (g watchedGraph) CreatePolicyClass(name string) error {
+	return g.hub.apply(func() ([]ngac.Change, error) {
+		if err := g.Graph.CreatePolicyClass(name); err != nil {
+			return nil, err
+		}
+
+		node, err := g.Graph.GetNode(name)
+		if err != nil {
+			return nil, err
+		}
+
+		return []ngac.Change{{Kind: ngac.NodeCreated, Node: &node}}, nil
+	})
+}
+
+func (g watchedGraph) CreateNode(name string, kind graph.Kind, properties map[string]string, parent string, parents ...string) (graph.Node, error) {
+	var node graph.Node
+	err := g.hub.apply(func() ([]ngac.Change, error) {
+		var err error
+		if node, err = g.Graph.CreateNode(name, kind, properties, parent, parents...); err != nil {
+			return nil, err
+		}
+
+		created := node
+		return []ngac.Change{{
+			Kind:    ngac.NodeCreated,
+			Node:    &created,
+			Parents: append([]string{parent}, parents...),
+		}}, nil
+	})
+
+	return node, err
+}
+
+func (g watchedGraph) UpdateNode(name string, properties map[string]string) error {
+	return g.hub.apply(func() ([]ngac.Change, error) {
+		if err := g.Graph.UpdateNode(name, properties); err != nil {
+			return nil, err
+		}
+
+		node, err := g.Graph.GetNode(name)
+		if err != nil {
+			return nil, err
+		}
+
+		return []ngac.Change{{Kind: ngac.NodeUpdated, Node: &node}}, nil
+	})
+}
+
+func (g watchedGraph) DeleteNode(name string) error {
+	return g.hub.apply(func() ([]ngac.Change, error) {
+		node, err := g.Graph.GetNode(name)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = g.Graph.DeleteNode(name); err != nil {
+			return nil, err
+		}
+
+		return []ngac.Change{{Kind: ngac.NodeDeleted, Node: &node}}, nil
+	})
+}
+
+func (g indexedWatchedGraph) Ancestors(name string) (map[string]bool, error) {
+	return g.index.Ancestors(name)
+}
+
+func (g watchedGraph) Assign(child string, parent string) error {
+	return g.hub.apply(func() ([]ngac.Change, error) {
+		assigned, err := g.assigned(child, parent)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = g.Graph.Assign(child, parent); err != nil || assigned {
+			return nil, err
+		}
+
+		return []ngac.Change{{Kind: ngac.Assigned, Child: child, Parent: parent}}, nil
+	})
+}
+
+func (g watchedGraph) Deassign(child string, parent string) error {
+	return g.hub.apply(func() ([]ngac.Change, error) {
+		assigned, err := g.assigned(child, parent)
+		if err != nil {
+			return nil, err
+		}
+
+		if err = g.Graph.Deassign(child, parent); err != nil || !assigned {
+			return nil, err
+		}
+
+		return []ngac.Change{{Kind: ngac.Deassigned, Child: child, Parent: parent}}, nil
+	})
+}
+
+func (g watchedGraph) assigned(child string, parent string) (bool, error) {
+	parents, err := g.Graph.GetParents(child)
+	if err != nil {
+		return false, err
+	}
+
+	_, ok := parents[parent]
+	return ok, nil
+}
+
+func (g watchedGraph) Associate(subject string, target string, operations graph.Operations) error {
+	return g.hub.apply(func() ([]ngac.Change, error) {
+		if err := g.Graph.Associate(subject, target, operations); err != nil {
+			return nil, err
+		}
+
+		return []ngac.Change{{Kind: ngac.Associated, Subject: subject, Target: target, Operations: operations}}, nil
+	})
+}
+
+func (g watchedGraph) AssociateWithCondition(subject string, target string, operations graph.Operations, condition string) error {
+	return g.hub.apply(func() ([]ngac.Change, error) {
+		if err := g.Graph.AssociateWithCondition(subject, target, operations, condition); err != nil {
+			return nil, err
+		}
+
+		return []ngac.Change{{
+			Kind:       ngac.Associated,
+			Subject:    subject,
+			Target:     target,
+			Operations: operations,
+			Condition:  condition,
+		}}, nil
+	})
+}
+
+func (g watchedGraph) SetAssociationValidity(subject string, target string, validity graph.Validity) error {
+	return g.hub.apply(func() ([]ngac.Change, error) {
+		if err := g.Graph.SetAssociationValidity(subject, target, validity); err != nil {
+			return nil, err
+		}
+
+		assocs, err := g.Graph.GetAssociationsForSubject(subject)
+		if err != nil {
+			return nil, err
+		}
+
+		conditions, err := g.Graph.GetAssociationConditions(subject)
+		if err != nil {
+			return nil, err
+		}
+
+		return []ngac.Change{{
+			Kind:       ngac.Associated,
+			Subject:    subject,
+			Target:     target,
+			Operations: assocs[target],
+			Condition:  conditions[target],
+			Validity:   &validity,
+		}}, nil
+	})
+}
+
+func (g watchedGraph) Dissociate(subject string, target string) error {
+	return g.hub.apply(func() ([]ngac.Change, error) {
+		assocs, err := g.Graph.GetAssociationsForSubject(subject)
+		if err != nil {
+			return nil, err
+		}
+
+		_, associated := assocs[target]
+		if err = g.Graph.Dissociate(subject, target); err != nil || !associated {
+			return nil, err
+		}
+
+		return []ngac.Change{{Kind: ngac.Dissociated, Subject: subject, Target: target}}, nil
+	})
+}
+
+func (g watchedGraph) UnmarshalJSON(bytes []byte) error {
+	return g.hub.apply(func() ([]ngac.Change, error) {
+		if err := g.Graph.UnmarshalJSON(bytes); err != nil {
+			return nil, err
+		}
+
+		return []ngac.Change{loaded(ngac.GraphPart, bytes)}, nil
+	})
+}
+
+func (p watchedProhibitions) Add(prohibition ngac.Prohibition) error {
+	return p.hub.apply(func() ([]ngac.Change, error) {
+		if err := p.Prohibitions.Add(prohibition); err != nil {
+			return nil, err
+		}
+
+		return []ngac.Change{{Kind: ngac.ProhibitionAdded, Prohibition: &prohibition}}, nil
+	})
+}
+
+func (p watchedProhibitions) Update(prohibition ngac.Prohibition) error {
+	return p.hub.apply(func() ([]ngac.Change, error) {
+		if err := p.Prohibitions.Update(prohibition); err != nil {
+			return nil, err
+		}
+
+		return []ngac.Change{{Kind: ngac.ProhibitionUpdated, Prohibition: &prohibition}}, nil
+	})
+}
+
+func (p watchedProhibitions) Delete(subject string, prohibitionName string) error {
+	return p.hub.apply(func() ([]ngac.Change, error) {
+		prohibitions, err := p.Prohibitions.Get(subject)
+		if err != nil {
+			return nil, err
+		}
+
+		exists := false
+		for _, prohibition := range prohibitions {
+			exists = exists || prohibition.Name == prohibitionName
+		}
+
+		if err = p.Prohibitions.Delete(subject, prohibitionName); err != nil || !exists {
+			return nil, err
+		}
+
+		return []ngac.Change{{
+			Kind:        ngac.ProhibitionDeleted,
+			Prohibition: &ngac.Prohibition{Name: prohibitionName, Subject: subject},
+		}}, nil
+	})
+}
+
+func (p watchedProhibitions) UnmarshalJSON(bytes []byte) error {
+	return p.hub.apply(func() ([]ngac.Change, error) {
+		if err := p.Prohibitions.UnmarshalJSON(bytes); err != nil {
+			return nil, err
+		}
+
+		return []ngac.Change{loaded(ngac.ProhibitionsPart, bytes)}, nil
+	})
+}
+
+func (o watchedObligations) Add(obligation ngac.Obligation) error {
+	return o.hub.apply(func() ([]ngac.Change, error) {
+		if err := o.Obligations.Add(obligation); err != nil {
+			return nil, err
+		}
+
+		return []ngac.Change{{Kind: ngac.ObligationAdded, Obligation: &obligation}}, nil
+	})
+}
+
+func (o watchedObligations) Remove(label string) error {
+	return o.hub.apply(func() ([]ngac.Change, error) {
+		if err := o.Obligations.Remove(label); err != nil {
+			return nil, err
+		}
+
+		return []ngac.Change{{Kind: ngac.ObligationRemoved, Label: label}}, nil
+	})
+}
+
+func (o watchedObligations) Enable(label string) error {
+	return o.hub.apply(func() ([]ngac.Change, error) {
+		if err := o.Obligations.Enable(label); err != nil {
+			return nil, err
+		}
+
+		return []ngac.Change{{Kind: ngac.ObligationEnabled, Label: label}}, nil
+	})
+}
+
+func (o watchedObligations) Disable(label string) error {
+	return o.hub.apply(func() ([]ngac.Change, error) {
+		if err := o.Obligations.Disable(label); err != nil {
+			return nil, err
+		}
+
+		return []ngac.Change{{Kind: ngac.ObligationDisabled, Label: label}}, nil
+	})
+}
+
+func (o watchedObligations) UnmarshalJSON(bytes []byte) error {
+	return o.hub.apply(func() ([]ngac.Change, error) {
+		if err := o.Obligations.UnmarshalJSON(bytes); err != nil {
+			return nil, err
+		}
+
+		return []ngac.Change{loaded(ngac.ObligationsPart, bytes)}, nil
+	})
+}
+
+func (c watchedConstraints) AddSSoD(constraint ngac.SSoD) error {
+	return c.hub.apply(func() ([]ngac.Change, error) {
+		if err := c.Constraints.AddSSoD(constraint); err != nil {
+			return nil, err
+		}
+
+		return []ngac.Change{{Kind: ngac.ConstraintAdded, SSoD: &constraint}}, nil
+	})
+}
+
+func (c watchedConstraints) RemoveSSoD(name string) error {
+	return c.hub.apply(func() ([]ngac.Change, error) {
+		if err := c.Constraints.RemoveSSoD(name); err != nil {
+			return nil, err
+		}
+
+		return []ngac.Change{{Kind: ngac.ConstraintRemoved, SSoD: &ngac.SSoD{Name: name}}}, nil
+	})
+}
+
+func (c watchedConstraints) AddDSoD(constraint ngac.DSoD) error {
+	return c.hub.apply(func() ([]ngac.Change, error) {
+		if err := c.Constraints.AddDSoD(constraint); err != nil {
+			return nil, err
+		}
+
+		return []ngac.Change{{Kind: ngac.ConstraintAdded, DSoD: &constraint}}, nil
+	})
+}
+
+func (c watchedConstraints) RemoveDSoD(name string) error {
+	return c.hub.apply(func() ([]ngac.Change, error) {
+		if err := c.Constraints.RemoveDSoD(name); err != nil {
+			return nil, err
+		}
+
+		return []ngac.Change{{Kind: ngac.ConstraintRemoved, DSoD: &ngac.DSoD{Name: name}}}, nil
+	})
+}
+
+func (c watchedConstraints) UnmarshalJSON(bytes []byte) error {
+	return c.hub.apply(func() ([]ngac.Change, error) {
+		if err := c.Constraints.UnmarshalJSON(bytes); err != nil {
+			return nil, err
+		}
+
+		return []ngac.Change{loaded(ngac.ConstraintsPart, bytes)}, nil
+	})
+}
+
+// copyChange returns a copy of the change that does not share maps or slices with it.
+func copyChange(c ngac.Change) ngac.Change {
+	if c.Node != nil {
+		node := copyNode(*c.Node)
+		c.Node = &node
+	}
+
+	c.Parents = copyStrings(c.Parents)
+	if c.Operations != nil {
+		c.Operations = copyOps(c.Operations)
+	}
+
+	if c.Validity != nil {
+		validity := *c.Validity
+		if validity.NotBefore != nil {
+			notBefore := *validity.NotBefore
+			validity.NotBefore = &notBefore
+		}
+		if validity.NotAfter != nil {
+			notAfter := *validity.NotAfter
+			validity.NotAfter = &notAfter
+		}
+		c.Validity = &validity
+	}
+
+	if c.Prohibition != nil {
+		prohibition := *c.Prohibition
+		if prohibition.Operations != nil {
+			prohibition.Operations = copyOps(prohibition.Operations)
+		}
+		if prohibition.Containers != nil {
+			containers := make(map[string]bool, len(prohibition.Containers))
+			for container, complement := range prohibition.Containers {
+				containers[container] = complement
+			}
+			prohibition.Containers = containers
+		}
+		c.Prohibition = &prohibition
+	}
+
+	if c.Obligation != nil {
+		c.Obligation = copyObligation(c.Obligation)
+	}
+
+	if c.SSoD != nil {
+		ssod := *c.SSoD
+		ssod.Attributes = copyStrings(ssod.Attributes)
+		c.SSoD = &ssod
+	}
+
+	if c.DSoD != nil {
+		dsod := *c.DSoD
+		dsod.Operations = copyStrings(dsod.Operations)
+		c.DSoD = &dsod
+	}
+
+	return c
+}
+
+// copyObligation copies the obligation through its JSON, which includes its event and response statements. The
+// obligation is returned as is if it cannot be copied, since it was accepted by the policy.
+func copyObligation(obligation *ngac.Obligation) *ngac.Obligation {
+	bytes, err := obligation.MarshalJSON()
+	if err != nil {
+		return obligation
+	}
+
+	copied := &ngac.Obligation{}
+	if err = copied.UnmarshalJSON(bytes); err != nil {
+		return obligation
+	}
+
+	if obligation.Response.Actions == nil {
+		copied.Response.Actions = nil
+	}
+
+	return copied
+}
+
+func copyStrings(strs []string) []string {
+	if strs == nil {
+		return nil
+	}
+
+	copied := make([]string, len(strs))
+	copy(copied, strs)
+	return copied
+}
+
+func loaded(part ngac.PolicyPart, bytes []byte) ngac.Change {
+	data := make([]byte, len(bytes))
+	copy(data, bytes)
+
+	return ngac.Change{Kind: ngac.PolicyLoaded, Part: part, Data: data}
+}
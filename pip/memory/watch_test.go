@@ -0,0 +1,159 @@
+package memory
+
+import (
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestWatch(t *testing.T) {
+	pip := NewPIP()
+	changes, cancel := pip.(ngac.Watchable).Watch()
+
+	g := pip.Graph()
+	require.NoError(t, g.CreatePolicyClass("pc1"))
+	_, err := g.CreateNode("ua1", graph.UserAttribute, nil, "pc1")
+	require.NoError(t, err)
+	_, err = g.CreateNode("oa1", graph.ObjectAttribute, map[string]string{"k": "v"}, "pc1")
+	require.NoError(t, err)
+	require.NoError(t, g.UpdateNode("oa1", map[string]string{"k": "v2"}))
+	require.NoError(t, g.Associate("ua1", "oa1", graph.ToOps("read")))
+	require.NoError(t, g.Dissociate("ua1", "oa1"))
+	require.NoError(t, pip.Prohibitions().Add(ngac.Prohibition{Name: "deny1", Subject: "ua1"}))
+	require.NoError(t, pip.Prohibitions().Delete("ua1", "deny1"))
+	require.NoError(t, pip.Obligations().Add(ngac.Obligation{Label: "o1"}))
+	require.NoError(t, pip.Obligations().Remove("o1"))
+	require.NoError(t, g.DeleteNode("oa1"))
+
+	// failed changes are not published
+	require.Error(t, g.Assign("ua1", "missing"))
+
+	expected := []ngac.Change{
+		{Revision: 1, Kind: ngac.NodeCreated, Node: &graph.Node{Name: "pc1", Kind: graph.PolicyClass, Properties: map[string]string{}}},
+		{Revision: 2, Kind: ngac.NodeCreated, Node: &graph.Node{Name: "ua1", Kind: graph.UserAttribute, Properties: map[string]string{}}, Parents: []string{"pc1"}},
+		{Revision: 3, Kind: ngac.NodeCreated, Node: &graph.Node{Name: "oa1", Kind: graph.ObjectAttribute, Properties: map[string]string{"k": "v"}}, Parents: []string{"pc1"}},
+		{Revision: 4, Kind: ngac.NodeUpdated, Node: &graph.Node{Name: "oa1", Kind: graph.ObjectAttribute, Properties: map[string]string{"k": "v2"}}},
+		{Revision: 5, Kind: ngac.Associated, Subject: "ua1", Target: "oa1", Operations: graph.ToOps("read")},
+		{Revision: 6, Kind: ngac.Dissociated, Subject: "ua1", Target: "oa1"},
+		{Revision: 7, Kind: ngac.ProhibitionAdded, Prohibition: &ngac.Prohibition{Name: "deny1", Subject: "ua1"}},
+		{Revision: 8, Kind: ngac.ProhibitionDeleted, Prohibition: &ngac.Prohibition{Name: "deny1", Subject: "ua1"}},
+		{Revision: 9, Kind: ngac.ObligationAdded, Obligation: &ngac.Obligation{Label: "o1"}},
+		{Revision: 10, Kind: ngac.ObligationRemoved, Label: "o1"},
+		{Revision: 11, Kind: ngac.NodeDeleted, Node: &graph.Node{Name: "oa1", Kind: graph.ObjectAttribute, Properties: map[string]string{"k": "v2"}}},
+	}
+
+	for _, e := range expected {
+		select {
+		case change := <-changes:
+			require.Equal(t, e, change)
+		case <-time.After(time.Second):
+			t.Fatalf("expected change %d", e.Revision)
+		}
+	}
+
+	cancel()
+	_, ok := <-changes
+	require.False(t, ok)
+
+	// changes made after the subscription ended are not received
+	require.NoError(t, g.CreatePolicyClass("pc2"))
+}
+
+func TestWatchAfterChanges(t *testing.T) {
+	pip := NewPIP()
+	require.NoError(t, pip.Graph().CreatePolicyClass("pc1"))
+
+	changes, cancel := pip.(ngac.Watchable).Watch()
+	defer cancel()
+
+	require.NoError(t, pip.Constraints().AddDSoD(ngac.DSoD{Name: "c1", Operations: []string{"read", "write"}}))
+	require.Equal(t, ngac.Change{
+		Revision: 2,
+		Kind:     ngac.ConstraintAdded,
+		DSoD:     &ngac.DSoD{Name: "c1", Operations: []string{"read", "write"}},
+	}, <-changes)
+}
+
+func TestWatchUnchanged(t *testing.T) {
+	pip := NewPIP()
+	g := pip.Graph()
+	require.NoError(t, g.CreatePolicyClass("pc1"))
+	_, err := g.CreateNode("ua1", graph.UserAttribute, nil, "pc1")
+	require.NoError(t, err)
+
+	changes, cancel := pip.(ngac.Watchable).Watch()
+	defer cancel()
+
+	// none of these change the policy so none of them are published
+	require.NoError(t, g.Assign("ua1", "pc1"))
+	require.NoError(t, g.Deassign("ua1", "missing"))
+	require.NoError(t, g.Dissociate("ua1", "pc1"))
+	require.NoError(t, pip.Prohibitions().Delete("ua1", "missing"))
+
+	require.NoError(t, g.CreatePolicyClass("pc2"))
+	change := <-changes
+	require.Equal(t, uint64(3), change.Revision)
+	require.Equal(t, "pc2", change.Node.Name)
+}
+
+func TestWatchCopiesChanges(t *testing.T) {
+	pip := NewPIP()
+	changes, cancel := pip.(ngac.Watchable).Watch()
+	defer cancel()
+
+	g := pip.Graph()
+	require.NoError(t, g.CreatePolicyClass("pc1"))
+	properties := map[string]string{"k": "v"}
+	_, err := g.CreateNode("oa1", graph.ObjectAttribute, properties, "pc1")
+	require.NoError(t, err)
+	prohibition := ngac.Prohibition{
+		Name:       "deny1",
+		Subject:    "oa1",
+		Containers: map[string]bool{"pc1": false},
+		Operations: graph.ToOps("read"),
+	}
+	require.NoError(t, pip.Prohibitions().Add(prohibition))
+
+	<-changes
+	created := <-changes
+	added := <-changes
+
+	// changing the published changes does not change the policy
+	created.Node.Properties["k"] = "changed"
+	added.Prohibition.Containers["oa1"] = true
+	added.Prohibition.Operations["write"] = true
+
+	node, err := g.GetNode("oa1")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"k": "v"}, node.Properties)
+
+	prohibitions, err := pip.Prohibitions().Get("oa1")
+	require.NoError(t, err)
+	require.Equal(t, map[string]bool{"pc1": false}, prohibitions[0].Containers)
+	require.Equal(t, graph.ToOps("read"), prohibitions[0].Operations)
+}
+
+func TestWatchForwardsAncestorIndex(t *testing.T) {
+	pip := NewWatchable(mempip{
+		graph:        NewIndexedGraph(),
+		prohibitions: NewProhibitions(),
+		obligations:  NewObligations(),
+		constraints:  NewConstraints(),
+	})
+
+	g := pip.Graph()
+	require.NoError(t, g.CreatePolicyClass("pc1"))
+	_, err := g.CreateNode("ua1", graph.UserAttribute, nil, "pc1")
+	require.NoError(t, err)
+
+	index, ok := g.(ngac.AncestorIndex)
+	require.True(t, ok)
+	ancestors, err := index.Ancestors("ua1")
+	require.NoError(t, err)
+	require.True(t, ancestors["pc1"])
+
+	_, ok = NewPIP().Graph().(ngac.AncestorIndex)
+	require.False(t, ok)
+}
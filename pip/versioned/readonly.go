@@ -0,0 +1,166 @@
+package versioned
+
+import (
+	"fmt"
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
+)
+
+type (
+	// view is a read only functional entity holding the policy at a revision.
+	view struct {
+		graph        ngac.Graph
+		prohibitions ngac.Prohibitions
+		obligations  ngac.Obligations
+		constraints  ngac.Constraints
+	}
+
+	readOnlyGraph struct {
+		ngac.Graph
+		revision uint64
+	}
+
+	readOnlyProhibitions struct {
+		ngac.Prohibitions
+		revision uint64
+	}
+
+	readOnlyObligations struct {
+		ngac.Obligations
+		revision uint64
+	}
+
+	readOnlyConstraints struct {
+		ngac.Constraints
+		revision uint64
+	}
+)
+
+func readOnly(fe ngac.FunctionalEntity, revision uint64) ngac.FunctionalEntity {
+	return view{
+		graph:        readOnlyGraph{Graph: fe.Graph(), revision: revision},
+		prohibitions: readOnlyProhibitions{Prohibitions: fe.Prohibitions(), revision: revision},
+		obligations:  readOnlyObligations{Obligations: fe.Obligations(), revision: revision},
+		constraints:  readOnlyConstraints{Constraints: fe.Constraints(), revision: revision},
+	}
+}
+
+func (v view) Graph() ngac.Graph {
+	return v.graph
+}
+
+func (v view) Prohibitions() ngac.Prohibitions {
+	return v.prohibitions
+}
+
+func (v view) Obligations() ngac.Obligations {
+	return v.obligations
+}
+
+func (v view) Constraints() ngac.Constraints {
+	return v.constraints
+}
+
+func readOnlyError(revision uint64) error {
+	return fmt.Errorf("revision %d is read only", revision)
+}
+
+func (g readOnlyGraph) CreatePolicyClass(string) error {
+	return readOnlyError(g.revision)
+}
+
+func (g readOnlyGraph) CreateNode(string, graph.Kind, map[string]string, string, ...string) (graph.Node, error) {
+	return graph.Node{}, readOnlyError(g.revision)
+}
+
+func (g readOnlyGraph) UpdateNode(string, map[string]string) error {
+	return readOnlyError(g.revision)
+}
+
+func (g readOnlyGraph) DeleteNode(string) error {
+	return readOnlyError(g.revision)
+}
+
+func (g readOnlyGraph) Assign(string, string) error {
+	return readOnlyError(g.revision)
+}
+
+func (g readOnlyGraph) Deassign(string, string) error {
+	return readOnlyError(g.revision)
+}
+
+func (g readOnlyGraph) Associate(string, string, graph.Operations) error {
+	return readOnlyError(g.revision)
+}
+
+func (g readOnlyGraph) AssociateWithCondition(string, string, graph.Operations, string) error {
+	return readOnlyError(g.revision)
+}
+
+func (g readOnlyGraph) Dissociate(string, string) error {
+	return readOnlyError(g.revision)
+}
+
+func (g readOnlyGraph) SetAssociationValidity(string, string, graph.Validity) error {
+	return readOnlyError(g.revision)
+}
+
+func (g readOnlyGraph) UnmarshalJSON([]byte) error {
+	return readOnlyError(g.revision)
+}
+
+func (p readOnlyProhibitions) Add(ngac.Prohibition) error {
+	return readOnlyError(p.revision)
+}
+
+func (p readOnlyProhibitions) Update(ngac.Prohibition) error {
+	return readOnlyError(p.revision)
+}
+
+func (p readOnlyProhibitions) Delete(string, string) error {
+	return readOnlyError(p.revision)
+}
+
+func (p readOnlyProhibitions) UnmarshalJSON([]byte) error {
+	return readOnlyError(p.revision)
+}
+
+func (o readOnlyObligations) Add(ngac.Obligation) error {
+	return readOnlyError(o.revision)
+}
+
+func (o readOnlyObligations) Remove(string) error {
+	return readOnlyError(o.revision)
+}
+
+func (o readOnlyObligations) Enable(string) error {
+	return readOnlyError(o.revision)
+}
+
+func (o readOnlyObligations) Disable(string) error {
+	return readOnlyError(o.revision)
+}
+
+func (o readOnlyObligations) UnmarshalJSON([]byte) error {
+	return readOnlyError(o.revision)
+}
+
+func (c readOnlyConstraints) AddSSoD(ngac.SSoD) error {
+	return readOnlyError(c.revision)
+}
+
+func (c readOnlyConstraints) RemoveSSoD(string) error {
+	return readOnlyError(c.revision)
+}
+
+func (c readOnlyConstraints) AddDSoD(ngac.DSoD) error {
+	return readOnlyError(c.revision)
+}
+
+func (c readOnlyConstraints) RemoveDSoD(string) error {
+	return readOnlyError(c.revision)
+}
+
+func (c readOnlyConstraints) UnmarshalJSON([]byte) error {
+	return readOnlyError(c.revision)
+}
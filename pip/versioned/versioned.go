@@ -0,0 +1,784 @@
+// Package versioned records the revisions of a policy so earlier revisions can be read and restored.
+package versioned
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
+	"github.com/PM-Master/policy-machine-go/pip/memory"
+	"sync"
+	"time"
+)
+
+// DefaultSnapshotInterval is the number of revisions between snapshots used by DefaultOptions.
+const DefaultSnapshotInterval = 100
+
+type (
+	// PIP is a functional entity that records a revision for every successful change to the policy it wraps. A
+	// revision records how to replay its change, and every Options.SnapshotInterval revisions the policy is written
+	// by memory.Marshal, so the policy at a revision is rebuilt from the snapshot before it. Changes that replace a
+	// whole part of the policy, such as UnmarshalJSON and Rollback, are always recorded as a snapshot.
+	//
+	// Changes are made and recorded under a lock that reads through the PIP also take, so a read never sees a change
+	// that has not been recorded yet. A caller that makes several reads that must agree can read At(Revision()).
+	PIP struct {
+		fe           ngac.FunctionalEntity
+		graph        ngac.Graph
+		prohibitions ngac.Prohibitions
+		obligations  ngac.Obligations
+		constraints  ngac.Constraints
+		options      Options
+
+		mu        sync.RWMutex
+		revisions []revision
+		// sinceSnapshot is the number of revisions recorded since the last snapshot.
+		sinceSnapshot int
+	}
+
+	// Options configure how a PIP records revisions.
+	Options struct {
+		// SnapshotInterval is the number of revisions between snapshots of the policy. Reading a revision replays
+		// at most this many changes. If 0, DefaultSnapshotInterval is used.
+		SnapshotInterval int
+		// Retain is the number of revisions kept. When more revisions are recorded the oldest are dropped and can
+		// no longer be read or restored. If 0, every revision is kept.
+		Retain int
+		// Clock returns the time recorded for each revision. If nil, the current time is used.
+		Clock func() time.Time
+	}
+
+	// Revision describes a change to the policy. Revision 0 is the policy the PIP was created with.
+	Revision struct {
+		Number    uint64    `json:"number"`
+		Time      time.Time `json:"time"`
+		Operation string    `json:"operation"`
+	}
+
+	// revision holds either a snapshot of the policy at the revision or the change that was made to the policy at the
+	// previous revision.
+	revision struct {
+		Revision
+		snapshot []byte
+		replay   func(fe ngac.FunctionalEntity) error
+	}
+
+	versionedGraph struct {
+		ngac.Graph
+		pip *PIP
+	}
+
+	// indexedVersionedGraph is a versionedGraph of a graph that implements ngac.AncestorIndex, so the decider can still
+	// use the index.
+	indexedVersionedGraph struct {
+		versionedGraph
+		index ngac.AncestorIndex
+	}
+
+	versionedProhibitions struct {
+		ngac.Prohibitions
+		pip *PIP
+	}
+
+	versionedObligations struct {
+		ngac.Obligations
+		pip *PIP
+	}
+
+	versionedConstraints struct {
+		ngac.Constraints
+		pip *PIP
+	}
+)
+
+func DefaultOptions() Options {
+	return Options{SnapshotInterval: DefaultSnapshotInterval}
+}
+
+// New returns a PIP that records the revisions of the functional entity with the default options. Changes made to
+// the functional entity directly are not recorded.
+func New(fe ngac.FunctionalEntity) (*PIP, error) {
+	return NewWithOptions(fe, DefaultOptions())
+}
+
+// NewWithOptions returns a PIP that records the revisions of the functional entity. Changes made to the functional
+// entity directly are not recorded, and are only seen in earlier revisions after the next snapshot.
+// If the graph of the functional entity implements ngac.AncestorIndex, so does the graph of the PIP.
+func NewWithOptions(fe ngac.FunctionalEntity, options Options) (*PIP, error) {
+	if options.SnapshotInterval <= 0 {
+		options.SnapshotInterval = DefaultSnapshotInterval
+	}
+	if options.Clock == nil {
+		options.Clock = time.Now
+	}
+
+	p := &PIP{fe: fe, options: options}
+	p.graph = versionedGraph{Graph: fe.Graph(), pip: p}
+	if index, ok := fe.Graph().(ngac.AncestorIndex); ok {
+		p.graph = indexedVersionedGraph{versionedGraph: p.graph.(versionedGraph), index: index}
+	}
+	p.prohibitions = versionedProhibitions{Prohibitions: fe.Prohibitions(), pip: p}
+	p.obligations = versionedObligations{Obligations: fe.Obligations(), pip: p}
+	p.constraints = versionedConstraints{Constraints: fe.Constraints(), pip: p}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.commitLocked("initial", nil); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (p *PIP) Graph() ngac.Graph {
+	return p.graph
+}
+
+func (p *PIP) Prohibitions() ngac.Prohibitions {
+	return p.prohibitions
+}
+
+func (p *PIP) Obligations() ngac.Obligations {
+	return p.obligations
+}
+
+func (p *PIP) Constraints() ngac.Constraints {
+	return p.constraints
+}
+
+// Revision returns the number of the current revision.
+func (p *PIP) Revision() uint64 {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.revisions[len(p.revisions)-1].Number
+}
+
+// Revisions returns every revision that is kept, oldest first.
+func (p *PIP) Revisions() []Revision {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	revisions := make([]Revision, 0, len(p.revisions))
+	for _, r := range p.revisions {
+		revisions = append(revisions, r.Revision)
+	}
+
+	return revisions
+}
+
+// RevisionAt returns the number of the revision that was current at the given time.
+func (p *PIP) RevisionAt(t time.Time) (uint64, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	for i := len(p.revisions) - 1; i >= 0; i-- {
+		if !p.revisions[i].Time.After(t) {
+			return p.revisions[i].Number, nil
+		}
+	}
+
+	return 0, fmt.Errorf("there is no revision at %s", t.Format(time.RFC3339))
+}
+
+// Snapshot returns a snapshot of the current revision.
+func (p *PIP) Snapshot() ([]byte, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return memory.Marshal(p.fe)
+}
+
+// At returns a read only view of the policy at the given revision. The view can be passed to pdp.NewDecider to make
+// decisions as they would have been made at the revision.
+func (p *PIP) At(number uint64) (ngac.FunctionalEntity, error) {
+	revisions, err := p.history(number)
+	if err != nil {
+		return nil, err
+	}
+
+	fe, err := build(revisions)
+	if err != nil {
+		return nil, fmt.Errorf("error reading revision %d: %w", number, err)
+	}
+
+	return readOnly(fe, number), nil
+}
+
+// Rollback restores the policy at the given revision. The rollback is recorded as a new revision, so the revisions
+// after the given one are kept and can be restored in turn.
+func (p *PIP) Rollback(number uint64) error {
+	revisions, err := p.history(number)
+	if err != nil {
+		return err
+	}
+
+	fe, err := build(revisions)
+	if err != nil {
+		return fmt.Errorf("error reading revision %d: %w", number, err)
+	}
+
+	snapshot, err := memory.Marshal(fe)
+	if err != nil {
+		return fmt.Errorf("error reading revision %d: %w", number, err)
+	}
+
+	return p.record(fmt.Sprintf("rollback to %d", number), func() error {
+		return memory.Restore(p.fe, snapshot)
+	}, nil)
+}
+
+// history returns the revisions needed to build the policy at the given revision: the last revision with a snapshot
+// up to the given revision and the revisions after it.
+func (p *PIP) history(number uint64) ([]revision, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	// the revisions that are kept are numbered without gaps
+	first := p.revisions[0].Number
+	if number < first || number-first >= uint64(len(p.revisions)) {
+		return nil, fmt.Errorf("revision %d does not exist", number)
+	}
+
+	end := int(number-first) + 1
+	start := end - 1
+	for p.revisions[start].snapshot == nil {
+		start--
+	}
+
+	revisions := make([]revision, end-start)
+	copy(revisions, p.revisions[start:end])
+	return revisions, nil
+}
+
+// build returns the policy at the last of the revisions. The first revision must hold a snapshot.
+func build(revisions []revision) (ngac.FunctionalEntity, error) {
+	fe, err := memory.Unmarshal(revisions[0].snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, r := range revisions[1:] {
+		if err = r.replay(fe); err != nil {
+			return nil, fmt.Errorf("error replaying revision %d: %w", r.Number, err)
+		}
+	}
+
+	return fe, nil
+}
+
+// record applies a change to the policy and records a revision if it succeeds. replay makes the same change to
+// another functional entity; it must not share maps or slices with the caller. If replay is nil the revision records
+// a snapshot.
+func (p *PIP) record(operation string, change func() error, replay func(fe ngac.FunctionalEntity) error) error {
+	return p.recordChanged(operation, func() (bool, error) {
+		return true, change()
+	}, replay)
+}
+
+// recordChanged is like record for changes that may leave the policy as it was, in which case change returns false
+// and no revision is recorded.
+func (p *PIP) recordChanged(operation string, change func() (bool, error), replay func(fe ngac.FunctionalEntity) error) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	changed, err := change()
+	if err != nil || !changed {
+		return err
+	}
+
+	return p.commitLocked(operation, replay)
+}
+
+func (p *PIP) commitLocked(operation string, replay func(fe ngac.FunctionalEntity) error) error {
+	var number uint64
+	if len(p.revisions) > 0 {
+		number = p.revisions[len(p.revisions)-1].Number + 1
+	}
+
+	r := revision{
+		Revision: Revision{
+			Number:    number,
+			Time:      p.options.Clock(),
+			Operation: operation,
+		},
+		replay: replay,
+	}
+
+	p.sinceSnapshot++
+	if replay == nil || p.sinceSnapshot >= p.options.SnapshotInterval {
+		snapshot, err := memory.Marshal(p.fe)
+		if err != nil {
+			return fmt.Errorf("error recording revision: %w", err)
+		}
+
+		r.snapshot = snapshot
+		p.sinceSnapshot = 0
+	}
+
+	p.revisions = append(p.revisions, r)
+	return p.compactLocked()
+}
+
+// compactLocked drops the revisions that are not retained. If the oldest revision that is kept has no snapshot, one is
+// built for it so the revisions after it can still be read.
+func (p *PIP) compactLocked() error {
+	if p.options.Retain <= 0 || len(p.revisions) <= p.options.Retain {
+		return nil
+	}
+
+	drop := len(p.revisions) - p.options.Retain
+	if p.revisions[drop].snapshot == nil {
+		start := drop
+		for p.revisions[start].snapshot == nil {
+			start--
+		}
+
+		fe, err := build(p.revisions[start : drop+1])
+		if err != nil {
+			return fmt.Errorf("error compacting revisions: %w", err)
+		}
+
+		snapshot, err := memory.Marshal(fe)
+		if err != nil {
+			return fmt.Errorf("error compacting revisions: %w", err)
+		}
+
+		p.revisions[drop].snapshot = snapshot
+	}
+
+	// clear the dropped revisions so their snapshots can be collected before the slice is reallocated
+	for i := 0; i < drop; i++ {
+		p.revisions[i] = revision{}
+	}
+	p.revisions = p.revisions[drop:]
+
+	return nil
+}
+
+// rlock read locks the PIP and returns the function that unlocks it.
+func (p *PIP) rlock() func() {
+	p.mu.RLock()
+	return p.mu.RUnlock
+}
+
+func (g versionedGraph) CreatePolicyClass(name string) error {
+	return g.pip.record(fmt.Sprintf("create policy class %s", name), func() error {
+		return g.Graph.CreatePolicyClass(name)
+	}, func(fe ngac.FunctionalEntity) error {
+		return fe.Graph().CreatePolicyClass(name)
+	})
+}
+
+func (g versionedGraph) CreateNode(name string, kind graph.Kind, properties map[string]string, parent string, parents ...string) (graph.Node, error) {
+	props := copyProperties(properties)
+	allParents := copyStrings(parents)
+
+	var node graph.Node
+	err := g.pip.record(fmt.Sprintf("create %s %s", kind, name), func() error {
+		var err error
+		node, err = g.Graph.CreateNode(name, kind, properties, parent, parents...)
+		return err
+	}, func(fe ngac.FunctionalEntity) error {
+		_, err := fe.Graph().CreateNode(name, kind, props, parent, allParents...)
+		return err
+	})
+
+	return node, err
+}
+
+func (g versionedGraph) UpdateNode(name string, properties map[string]string) error {
+	props := copyProperties(properties)
+	return g.pip.record(fmt.Sprintf("update %s", name), func() error {
+		return g.Graph.UpdateNode(name, properties)
+	}, func(fe ngac.FunctionalEntity) error {
+		return fe.Graph().UpdateNode(name, props)
+	})
+}
+
+func (g versionedGraph) DeleteNode(name string) error {
+	return g.pip.record(fmt.Sprintf("delete %s", name), func() error {
+		return g.Graph.DeleteNode(name)
+	}, func(fe ngac.FunctionalEntity) error {
+		return fe.Graph().DeleteNode(name)
+	})
+}
+
+func (g versionedGraph) Assign(child string, parent string) error {
+	return g.pip.record(fmt.Sprintf("assign %s to %s", child, parent), func() error {
+		return g.Graph.Assign(child, parent)
+	}, func(fe ngac.FunctionalEntity) error {
+		return fe.Graph().Assign(child, parent)
+	})
+}
+
+func (g versionedGraph) Deassign(child string, parent string) error {
+	return g.pip.recordChanged(fmt.Sprintf("deassign %s from %s", child, parent), func() (bool, error) {
+		parents, err := g.Graph.GetParents(child)
+		if err != nil {
+			return false, err
+		}
+
+		_, assigned := parents[parent]
+		return assigned, g.Graph.Deassign(child, parent)
+	}, func(fe ngac.FunctionalEntity) error {
+		return fe.Graph().Deassign(child, parent)
+	})
+}
+
+func (g versionedGraph) Associate(subject string, target string, operations graph.Operations) error {
+	ops := copyOps(operations)
+	return g.pip.record(fmt.Sprintf("associate %s with %s", subject, target), func() error {
+		return g.Graph.Associate(subject, target, operations)
+	}, func(fe ngac.FunctionalEntity) error {
+		return fe.Graph().Associate(subject, target, ops)
+	})
+}
+
+func (g versionedGraph) AssociateWithCondition(subject string, target string, operations graph.Operations, condition string) error {
+	ops := copyOps(operations)
+	return g.pip.record(fmt.Sprintf("associate %s with %s", subject, target), func() error {
+		return g.Graph.AssociateWithCondition(subject, target, operations, condition)
+	}, func(fe ngac.FunctionalEntity) error {
+		return fe.Graph().AssociateWithCondition(subject, target, ops, condition)
+	})
+}
+
+func (g versionedGraph) Dissociate(subject string, target string) error {
+	return g.pip.recordChanged(fmt.Sprintf("dissociate %s from %s", subject, target), func() (bool, error) {
+		assocs, err := g.Graph.GetAssociationsForSubject(subject)
+		if err != nil {
+			return false, err
+		}
+
+		_, associated := assocs[target]
+		return associated, g.Graph.Dissociate(subject, target)
+	}, func(fe ngac.FunctionalEntity) error {
+		return fe.Graph().Dissociate(subject, target)
+	})
+}
+
+func (g versionedGraph) SetAssociationValidity(subject string, target string, validity graph.Validity) error {
+	copied := copyValidity(validity)
+	return g.pip.record(fmt.Sprintf("set validity of %s on %s", subject, target), func() error {
+		return g.Graph.SetAssociationValidity(subject, target, validity)
+	}, func(fe ngac.FunctionalEntity) error {
+		return fe.Graph().SetAssociationValidity(subject, target, copied)
+	})
+}
+
+func (g versionedGraph) UnmarshalJSON(bytes []byte) error {
+	return g.pip.record("unmarshal graph", func() error {
+		return g.Graph.UnmarshalJSON(bytes)
+	}, nil)
+}
+
+func (g versionedGraph) Exists(name string) (bool, error) {
+	defer g.pip.rlock()()
+	return g.Graph.Exists(name)
+}
+
+func (g versionedGraph) GetNodes() (map[string]graph.Node, error) {
+	defer g.pip.rlock()()
+	return g.Graph.GetNodes()
+}
+
+func (g versionedGraph) GetNode(name string) (graph.Node, error) {
+	defer g.pip.rlock()()
+	return g.Graph.GetNode(name)
+}
+
+func (g versionedGraph) Find(kind graph.Kind, properties map[string]string) (map[string]graph.Node, error) {
+	defer g.pip.rlock()()
+	return g.Graph.Find(kind, properties)
+}
+
+func (g versionedGraph) GetChildren(name string) (map[string]graph.Node, error) {
+	defer g.pip.rlock()()
+	return g.Graph.GetChildren(name)
+}
+
+func (g versionedGraph) GetParents(name string) (map[string]graph.Node, error) {
+	defer g.pip.rlock()()
+	return g.Graph.GetParents(name)
+}
+
+func (g versionedGraph) GetAssignments() (map[string]map[string]bool, error) {
+	defer g.pip.rlock()()
+	return g.Graph.GetAssignments()
+}
+
+func (g versionedGraph) GetAssociationsForSubject(subject string) (map[string]graph.Operations, error) {
+	defer g.pip.rlock()()
+	return g.Graph.GetAssociationsForSubject(subject)
+}
+
+func (g versionedGraph) GetAssociations() (map[string]map[string]graph.Operations, error) {
+	defer g.pip.rlock()()
+	return g.Graph.GetAssociations()
+}
+
+func (g versionedGraph) GetAssociationConditions(subject string) (map[string]string, error) {
+	defer g.pip.rlock()()
+	return g.Graph.GetAssociationConditions(subject)
+}
+
+func (g versionedGraph) GetAssociationValidity(subject string) (map[string]graph.Validity, error) {
+	defer g.pip.rlock()()
+	return g.Graph.GetAssociationValidity(subject)
+}
+
+func (g versionedGraph) MarshalJSON() ([]byte, error) {
+	defer g.pip.rlock()()
+	return g.Graph.MarshalJSON()
+}
+
+func (g indexedVersionedGraph) Ancestors(name string) (map[string]bool, error) {
+	defer g.pip.rlock()()
+	return g.index.Ancestors(name)
+}
+
+func (p versionedProhibitions) Add(prohibition ngac.Prohibition) error {
+	copied := copyProhibition(prohibition)
+	return p.pip.record(fmt.Sprintf("add prohibition %s", prohibition.Name), func() error {
+		return p.Prohibitions.Add(prohibition)
+	}, func(fe ngac.FunctionalEntity) error {
+		return fe.Prohibitions().Add(copied)
+	})
+}
+
+func (p versionedProhibitions) Update(prohibition ngac.Prohibition) error {
+	copied := copyProhibition(prohibition)
+	return p.pip.record(fmt.Sprintf("update prohibition %s", prohibition.Name), func() error {
+		return p.Prohibitions.Update(prohibition)
+	}, func(fe ngac.FunctionalEntity) error {
+		return fe.Prohibitions().Update(copied)
+	})
+}
+
+func (p versionedProhibitions) Delete(subject string, prohibitionName string) error {
+	return p.pip.recordChanged(fmt.Sprintf("delete prohibition %s", prohibitionName), func() (bool, error) {
+		prohibitions, err := p.Prohibitions.Get(subject)
+		if err != nil {
+			return false, err
+		}
+
+		exists := false
+		for _, prohibition := range prohibitions {
+			exists = exists || prohibition.Name == prohibitionName
+		}
+
+		return exists, p.Prohibitions.Delete(subject, prohibitionName)
+	}, func(fe ngac.FunctionalEntity) error {
+		return fe.Prohibitions().Delete(subject, prohibitionName)
+	})
+}
+
+func (p versionedProhibitions) UnmarshalJSON(bytes []byte) error {
+	return p.pip.record("unmarshal prohibitions", func() error {
+		return p.Prohibitions.UnmarshalJSON(bytes)
+	}, nil)
+}
+
+func (p versionedProhibitions) Get(subject string) ([]ngac.Prohibition, error) {
+	defer p.pip.rlock()()
+	return p.Prohibitions.Get(subject)
+}
+
+func (p versionedProhibitions) GetByName(name string) (ngac.Prohibition, error) {
+	defer p.pip.rlock()()
+	return p.Prohibitions.GetByName(name)
+}
+
+func (p versionedProhibitions) All() ([]ngac.Prohibition, error) {
+	defer p.pip.rlock()()
+	return p.Prohibitions.All()
+}
+
+func (p versionedProhibitions) MarshalJSON() ([]byte, error) {
+	defer p.pip.rlock()()
+	return p.Prohibitions.MarshalJSON()
+}
+
+func (o versionedObligations) Add(obligation ngac.Obligation) error {
+	// the obligation is replayed from its JSON so the replay does not share its statements. If it cannot be written
+	// the revision records a snapshot instead.
+	var replay func(fe ngac.FunctionalEntity) error
+	if bytes, err := json.Marshal(&obligation); err == nil {
+		replay = func(fe ngac.FunctionalEntity) error {
+			copied := ngac.Obligation{}
+			if err := json.Unmarshal(bytes, &copied); err != nil {
+				return err
+			}
+
+			return fe.Obligations().Add(copied)
+		}
+	}
+
+	return o.pip.record(fmt.Sprintf("add obligation %s", obligation.Label), func() error {
+		return o.Obligations.Add(obligation)
+	}, replay)
+}
+
+func (o versionedObligations) Remove(label string) error {
+	return o.pip.record(fmt.Sprintf("remove obligation %s", label), func() error {
+		return o.Obligations.Remove(label)
+	}, func(fe ngac.FunctionalEntity) error {
+		return fe.Obligations().Remove(label)
+	})
+}
+
+func (o versionedObligations) Enable(label string) error {
+	return o.pip.record(fmt.Sprintf("enable obligation %s", label), func() error {
+		return o.Obligations.Enable(label)
+	}, func(fe ngac.FunctionalEntity) error {
+		return fe.Obligations().Enable(label)
+	})
+}
+
+func (o versionedObligations) Disable(label string) error {
+	return o.pip.record(fmt.Sprintf("disable obligation %s", label), func() error {
+		return o.Obligations.Disable(label)
+	}, func(fe ngac.FunctionalEntity) error {
+		return fe.Obligations().Disable(label)
+	})
+}
+
+func (o versionedObligations) UnmarshalJSON(bytes []byte) error {
+	return o.pip.record("unmarshal obligations", func() error {
+		return o.Obligations.UnmarshalJSON(bytes)
+	}, nil)
+}
+
+func (o versionedObligations) Get(label string) (ngac.Obligation, error) {
+	defer o.pip.rlock()()
+	return o.Obligations.Get(label)
+}
+
+func (o versionedObligations) All() ([]ngac.Obligation, error) {
+	defer o.pip.rlock()()
+	return o.Obligations.All()
+}
+
+func (o versionedObligations) MarshalJSON() ([]byte, error) {
+	defer o.pip.rlock()()
+	return o.Obligations.MarshalJSON()
+}
+
+func (c versionedConstraints) AddSSoD(constraint ngac.SSoD) error {
+	copied := constraint
+	copied.Attributes = copyStrings(constraint.Attributes)
+	return c.pip.record(fmt.Sprintf("add ssod %s", constraint.Name), func() error {
+		return c.Constraints.AddSSoD(constraint)
+	}, func(fe ngac.FunctionalEntity) error {
+		return fe.Constraints().AddSSoD(copied)
+	})
+}
+
+func (c versionedConstraints) RemoveSSoD(name string) error {
+	return c.pip.record(fmt.Sprintf("remove ssod %s", name), func() error {
+		return c.Constraints.RemoveSSoD(name)
+	}, func(fe ngac.FunctionalEntity) error {
+		return fe.Constraints().RemoveSSoD(name)
+	})
+}
+
+func (c versionedConstraints) AddDSoD(constraint ngac.DSoD) error {
+	copied := constraint
+	copied.Operations = copyStrings(constraint.Operations)
+	return c.pip.record(fmt.Sprintf("add dsod %s", constraint.Name), func() error {
+		return c.Constraints.AddDSoD(constraint)
+	}, func(fe ngac.FunctionalEntity) error {
+		return fe.Constraints().AddDSoD(copied)
+	})
+}
+
+func (c versionedConstraints) RemoveDSoD(name string) error {
+	return c.pip.record(fmt.Sprintf("remove dsod %s", name), func() error {
+		return c.Constraints.RemoveDSoD(name)
+	}, func(fe ngac.FunctionalEntity) error {
+		return fe.Constraints().RemoveDSoD(name)
+	})
+}
+
+func (c versionedConstraints) UnmarshalJSON(bytes []byte) error {
+	return c.pip.record("unmarshal constraints", func() error {
+		return c.Constraints.UnmarshalJSON(bytes)
+	}, nil)
+}
+
+func (c versionedConstraints) GetSSoD() ([]ngac.SSoD, error) {
+	defer c.pip.rlock()()
+	return c.Constraints.GetSSoD()
+}
+
+func (c versionedConstraints) GetDSoD() ([]ngac.DSoD, error) {
+	defer c.pip.rlock()()
+	return c.Constraints.GetDSoD()
+}
+
+func (c versionedConstraints) MarshalJSON() ([]byte, error) {
+	defer c.pip.rlock()()
+	return c.Constraints.MarshalJSON()
+}
+
+func copyProperties(properties map[string]string) map[string]string {
+	if properties == nil {
+		return nil
+	}
+
+	c := make(map[string]string, len(properties))
+	for k, v := range properties {
+		c[k] = v
+	}
+	return c
+}
+
+func copyOps(operations graph.Operations) graph.Operations {
+	if operations == nil {
+		return nil
+	}
+
+	c := make(graph.Operations, len(operations))
+	for op, ok := range operations {
+		c[op] = ok
+	}
+	return c
+}
+
+func copyStrings(strs []string) []string {
+	if strs == nil {
+		return nil
+	}
+
+	c := make([]string, len(strs))
+	copy(c, strs)
+	return c
+}
+
+func copyValidity(validity graph.Validity) graph.Validity {
+	if validity.NotBefore != nil {
+		notBefore := *validity.NotBefore
+		validity.NotBefore = &notBefore
+	}
+	if validity.NotAfter != nil {
+		notAfter := *validity.NotAfter
+		validity.NotAfter = &notAfter
+	}
+	return validity
+}
+
+func copyProhibition(prohibition ngac.Prohibition) ngac.Prohibition {
+	if prohibition.Containers != nil {
+		containers := make(map[string]bool, len(prohibition.Containers))
+		for container, complement := range prohibition.Containers {
+			containers[container] = complement
+		}
+		prohibition.Containers = containers
+	}
+
+	prohibition.Operations = copyOps(prohibition.Operations)
+	prohibition.Validity = copyValidity(prohibition.Validity)
+	return prohibition
+}
@@ -0,0 +1,190 @@
+package versioned
+
+import (
+	"fmt"
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
+	"github.com/PM-Master/policy-machine-go/pdp"
+	"github.com/PM-Master/policy-machine-go/pip/memory"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestRevisions(t *testing.T) {
+	start := time.Date(2021, 1, 5, 0, 0, 0, 0, time.UTC)
+	clock := start
+	pip, err := NewWithOptions(memory.NewPIP(), Options{Clock: func() time.Time {
+		clock = clock.Add(time.Hour)
+		return clock
+	}})
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), pip.Revision())
+
+	g := pip.Graph()
+	require.NoError(t, g.CreatePolicyClass("pc1"))
+	_, err = g.CreateNode("ua1", graph.UserAttribute, nil, "pc1")
+	require.NoError(t, err)
+	_, err = g.CreateNode("oa1", graph.ObjectAttribute, nil, "pc1")
+	require.NoError(t, err)
+	_, err = g.CreateNode("u1", graph.User, nil, "ua1")
+	require.NoError(t, err)
+	_, err = g.CreateNode("o1", graph.Object, nil, "oa1")
+	require.NoError(t, err)
+	require.NoError(t, g.Associate("ua1", "oa1", graph.ToOps("read", "write")))
+	granted := pip.Revision()
+
+	require.NoError(t, pip.Prohibitions().Add(ngac.Prohibition{
+		Name:       "deny1",
+		Subject:    "u1",
+		Containers: map[string]bool{"oa1": false},
+		Operations: graph.ToOps("write"),
+	}))
+	require.NoError(t, g.Dissociate("ua1", "oa1"))
+
+	// failed changes are not recorded
+	require.Error(t, g.Assign("u1", "missing"))
+
+	// changes that leave the policy as it was are not recorded
+	require.NoError(t, g.Dissociate("ua1", "oa1"))
+	require.NoError(t, g.Deassign("u1", "oa1"))
+	require.NoError(t, pip.Prohibitions().Delete("u1", "missing"))
+	require.Equal(t, uint64(8), pip.Revision())
+	require.Len(t, pip.Revisions(), 9)
+	require.Equal(t, "dissociate ua1 from oa1", pip.Revisions()[8].Operation)
+
+	// decisions at an earlier revision
+	view, err := pip.At(granted)
+	require.NoError(t, err)
+	ops, err := pdp.NewDecider(view.Graph(), view.Prohibitions()).ListPermissions("u1", "o1")
+	require.NoError(t, err)
+	require.Equal(t, graph.ToOps("read", "write"), ops)
+
+	view, err = pip.At(granted + 1)
+	require.NoError(t, err)
+	ops, err = pdp.NewDecider(view.Graph(), view.Prohibitions()).ListPermissions("u1", "o1")
+	require.NoError(t, err)
+	require.Equal(t, graph.ToOps("read"), ops)
+
+	require.Error(t, view.Graph().CreatePolicyClass("pc2"))
+	require.Error(t, view.Prohibitions().Delete("u1", "deny1"))
+
+	_, err = pip.At(9)
+	require.Error(t, err)
+
+	// the revision current at a time
+	number, err := pip.RevisionAt(start.Add(6*time.Hour + time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), number)
+	_, err = pip.RevisionAt(start)
+	require.Error(t, err)
+
+	// rolling back is recorded as a revision
+	require.NoError(t, pip.Rollback(granted))
+	require.Equal(t, uint64(9), pip.Revision())
+	require.Equal(t, "rollback to 6", pip.Revisions()[9].Operation)
+
+	ops, err = pdp.NewDecider(pip.Graph(), pip.Prohibitions()).ListPermissions("u1", "o1")
+	require.NoError(t, err)
+	require.Equal(t, graph.ToOps("read", "write"), ops)
+	_, err = pip.Prohibitions().GetByName("deny1")
+	require.Error(t, err)
+
+	snapshot, err := pip.Snapshot()
+	require.NoError(t, err)
+	restored, err := memory.Unmarshal(snapshot)
+	require.NoError(t, err)
+	ok, err := restored.Graph().Exists("o1")
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestRevisionsRetained(t *testing.T) {
+	pip, err := NewWithOptions(memory.NewPIP(), Options{SnapshotInterval: 3, Retain: 4})
+	require.NoError(t, err)
+
+	g := pip.Graph()
+	require.NoError(t, g.CreatePolicyClass("pc1"))
+	for _, name := range []string{"oa1", "oa2", "oa3", "oa4", "oa5", "oa6"} {
+		_, err = g.CreateNode(name, graph.ObjectAttribute, map[string]string{"k": name}, "pc1")
+		require.NoError(t, err)
+	}
+	require.Equal(t, uint64(7), pip.Revision())
+
+	// only the last 4 revisions are kept
+	revisions := pip.Revisions()
+	require.Len(t, revisions, 4)
+	require.Equal(t, uint64(4), revisions[0].Number)
+	_, err = pip.At(3)
+	require.Error(t, err)
+
+	// every kept revision is rebuilt from the snapshot before it and the changes after the snapshot
+	for number := uint64(4); number <= 7; number++ {
+		view, err := pip.At(number)
+		require.NoError(t, err)
+
+		nodes, err := view.Graph().GetNodes()
+		require.NoError(t, err)
+		require.Len(t, nodes, int(number))
+
+		node, err := view.Graph().GetNode(fmt.Sprintf("oa%d", number-1))
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"k": fmt.Sprintf("oa%d", number-1)}, node.Properties)
+	}
+
+	require.NoError(t, pip.Rollback(5))
+	nodes, err := g.GetNodes()
+	require.NoError(t, err)
+	require.Len(t, nodes, 5)
+}
+
+func TestRevisionsDoNotShareArguments(t *testing.T) {
+	pip, err := New(memory.NewPIP())
+	require.NoError(t, err)
+
+	g := pip.Graph()
+	require.NoError(t, g.CreatePolicyClass("pc1"))
+	properties := map[string]string{"k": "v"}
+	_, err = g.CreateNode("oa1", graph.ObjectAttribute, properties, "pc1")
+	require.NoError(t, err)
+
+	// changing the arguments after the change does not change the revision
+	properties["k"] = "changed"
+
+	view, err := pip.At(pip.Revision())
+	require.NoError(t, err)
+	node, err := view.Graph().GetNode("oa1")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"k": "v"}, node.Properties)
+}
+
+// indexedPIP is a functional entity whose graph implements ngac.AncestorIndex.
+type indexedPIP struct {
+	ngac.FunctionalEntity
+	graph ngac.Graph
+}
+
+func (p indexedPIP) Graph() ngac.Graph {
+	return p.graph
+}
+
+func TestVersionedForwardsAncestorIndex(t *testing.T) {
+	pip, err := New(indexedPIP{FunctionalEntity: memory.NewPIP(), graph: memory.NewIndexedGraph()})
+	require.NoError(t, err)
+
+	g := pip.Graph()
+	require.NoError(t, g.CreatePolicyClass("pc1"))
+	_, err = g.CreateNode("ua1", graph.UserAttribute, nil, "pc1")
+	require.NoError(t, err)
+
+	index, ok := g.(ngac.AncestorIndex)
+	require.True(t, ok)
+	ancestors, err := index.Ancestors("ua1")
+	require.NoError(t, err)
+	require.True(t, ancestors["pc1"])
+
+	pip, err = New(memory.NewPIP())
+	require.NoError(t, err)
+	_, ok = pip.Graph().(ngac.AncestorIndex)
+	require.False(t, ok)
+}
@@ -0,0 +1,136 @@
+// Package replication copies the changes made to a leader policy to follower policies. The leader streams the changes
+// it observes through ngac.Watchable and followers bootstrap from a snapshot of the leader's policy.
+package replication
+
+import (
+	"fmt"
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
+)
+
+// Apply makes the change to the functional entity.
+func Apply(fe ngac.FunctionalEntity, change ngac.Change) error {
+	g := fe.Graph()
+	switch change.Kind {
+	case ngac.NodeCreated:
+		if change.Node == nil {
+			return fmt.Errorf("change %d does not have a node", change.Revision)
+		}
+
+		node := change.Node
+		if node.Kind == graph.PolicyClass {
+			if err := g.CreatePolicyClass(node.Name); err != nil {
+				return err
+			}
+
+			if len(node.Properties) == 0 {
+				return nil
+			}
+
+			return g.UpdateNode(node.Name, node.Properties)
+		}
+
+		if len(change.Parents) == 0 {
+			return fmt.Errorf("change %d does not have the parents of node %q", change.Revision, node.Name)
+		}
+
+		_, err := g.CreateNode(node.Name, node.Kind, node.Properties, change.Parents[0], change.Parents[1:]...)
+		return err
+	case ngac.NodeUpdated:
+		if change.Node == nil {
+			return fmt.Errorf("change %d does not have a node", change.Revision)
+		}
+
+		return g.UpdateNode(change.Node.Name, change.Node.Properties)
+	case ngac.NodeDeleted:
+		if change.Node == nil {
+			return fmt.Errorf("change %d does not have a node", change.Revision)
+		}
+
+		return g.DeleteNode(change.Node.Name)
+	case ngac.Assigned:
+		return g.Assign(change.Child, change.Parent)
+	case ngac.Deassigned:
+		return g.Deassign(change.Child, change.Parent)
+	case ngac.Associated:
+		var err error
+		if change.Condition == "" {
+			err = g.Associate(change.Subject, change.Target, change.Operations)
+		} else {
+			err = g.AssociateWithCondition(change.Subject, change.Target, change.Operations, change.Condition)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if change.Validity == nil {
+			return nil
+		}
+
+		return g.SetAssociationValidity(change.Subject, change.Target, *change.Validity)
+	case ngac.Dissociated:
+		return g.Dissociate(change.Subject, change.Target)
+	case ngac.ProhibitionAdded, ngac.ProhibitionUpdated, ngac.ProhibitionDeleted:
+		if change.Prohibition == nil {
+			return fmt.Errorf("change %d does not have a prohibition", change.Revision)
+		}
+
+		switch change.Kind {
+		case ngac.ProhibitionAdded:
+			return fe.Prohibitions().Add(*change.Prohibition)
+		case ngac.ProhibitionUpdated:
+			return fe.Prohibitions().Update(*change.Prohibition)
+		default:
+			return fe.Prohibitions().Delete(change.Prohibition.Subject, change.Prohibition.Name)
+		}
+	case ngac.ObligationAdded:
+		if change.Obligation == nil {
+			return fmt.Errorf("change %d does not have an obligation", change.Revision)
+		}
+
+		return fe.Obligations().Add(*change.Obligation)
+	case ngac.ObligationRemoved:
+		return fe.Obligations().Remove(change.Label)
+	case ngac.ObligationEnabled:
+		return fe.Obligations().Enable(change.Label)
+	case ngac.ObligationDisabled:
+		return fe.Obligations().Disable(change.Label)
+	case ngac.ConstraintAdded, ngac.ConstraintRemoved:
+		return applyConstraint(fe.Constraints(), change)
+	case ngac.PolicyLoaded:
+		return applyLoaded(fe, change)
+	default:
+		return fmt.Errorf("unknown change %q", change.Kind)
+	}
+}
+
+func applyConstraint(constraints ngac.Constraints, change ngac.Change) error {
+	switch {
+	case change.SSoD != nil && change.Kind == ngac.ConstraintAdded:
+		return constraints.AddSSoD(*change.SSoD)
+	case change.SSoD != nil:
+		return constraints.RemoveSSoD(change.SSoD.Name)
+	case change.DSoD != nil && change.Kind == ngac.ConstraintAdded:
+		return constraints.AddDSoD(*change.DSoD)
+	case change.DSoD != nil:
+		return constraints.RemoveDSoD(change.DSoD.Name)
+	default:
+		return fmt.Errorf("change %d does not have a constraint", change.Revision)
+	}
+}
+
+func applyLoaded(fe ngac.FunctionalEntity, change ngac.Change) error {
+	switch change.Part {
+	case ngac.GraphPart:
+		return fe.Graph().UnmarshalJSON(change.Data)
+	case ngac.ProhibitionsPart:
+		return fe.Prohibitions().UnmarshalJSON(change.Data)
+	case ngac.ObligationsPart:
+		return fe.Obligations().UnmarshalJSON(change.Data)
+	case ngac.ConstraintsPart:
+		return fe.Constraints().UnmarshalJSON(change.Data)
+	default:
+		return fmt.Errorf("unknown policy part %q", change.Part)
+	}
+}
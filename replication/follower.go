@@ -0,0 +1,225 @@
+package replication
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/pip/memory"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultReportInterval is the interval at which Follow reports the revision of a follower if ReportInterval is 0.
+const DefaultReportInterval = time.Second
+
+// Follower holds a copy of the policy of a leader and applies the leader's changes to it in order.
+type Follower struct {
+	// ReportInterval is the minimum interval between the reports of the revision of the follower made by Follow.
+	ReportInterval time.Duration
+
+	mu       sync.RWMutex
+	fe       ngac.FunctionalEntity
+	revision uint64
+}
+
+// NewFollower returns a follower holding the policy of the snapshot.
+func NewFollower(snapshot Snapshot) (*Follower, error) {
+	fe, err := memory.Unmarshal(snapshot.Policy)
+	if err != nil {
+		return nil, fmt.Errorf("error reading snapshot: %w", err)
+	}
+
+	return &Follower{fe: fe, revision: snapshot.Revision}, nil
+}
+
+// Bootstrap returns a follower holding the snapshot served by the leader at the given URL.
+func Bootstrap(ctx context.Context, client *http.Client, url string) (*Follower, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(url, "/")+"/snapshot", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error requesting snapshot: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error requesting snapshot: %s", resp.Status)
+	}
+
+	snapshot := Snapshot{}
+	if err = json.NewDecoder(resp.Body).Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("error reading snapshot: %w", err)
+	}
+
+	return NewFollower(snapshot)
+}
+
+// Revision returns the revision of the leader the follower has applied.
+func (f *Follower) Revision() uint64 {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.revision
+}
+
+// Read calls read with the policy of the follower. Changes are not applied while read runs, so decisions made in read
+// are made against a single revision.
+func (f *Follower) Read(read func(fe ngac.FunctionalEntity) error) error {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return read(f.fe)
+}
+
+// Apply applies the change if it is the next change of the leader. Changes the follower has already applied are
+// ignored, so a stream can be resumed from an earlier revision.
+func (f *Follower) Apply(change ngac.Change) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if change.Revision <= f.revision {
+		return nil
+	} else if change.Revision != f.revision+1 {
+		return fmt.Errorf("change %d cannot be applied at revision %d", change.Revision, f.revision)
+	}
+
+	if err := Apply(f.fe, change); err != nil {
+		return fmt.Errorf("error applying change %d: %w", change.Revision, err)
+	}
+
+	f.revision = change.Revision
+
+	return nil
+}
+
+// Consume applies the changes read from r as JSON lines until r is exhausted and calls report, if it is not nil,
+// with the revision of the follower after each change.
+func (f *Follower) Consume(r io.Reader, report func(revision uint64)) error {
+	decoder := json.NewDecoder(r)
+	for {
+		change := ngac.Change{}
+		if err := decoder.Decode(&change); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("error reading change: %w", err)
+		}
+
+		if err := f.Apply(change); err != nil {
+			return err
+		}
+
+		if report != nil {
+			report(f.Revision())
+		}
+	}
+}
+
+// Follow streams the changes of the leader at the given URL after the revision of the follower and applies them until
+// the context is done or the leader ends the stream. The follower reports its revision to the leader under the given
+// name at most once per ReportInterval while changes are applied, and once more when the stream ends. An error
+// wrapping ErrCompacted is returned if the leader has dropped the changes after the revision of the follower.
+func (f *Follower) Follow(ctx context.Context, client *http.Client, url string, name string) error {
+	url = strings.TrimSuffix(url, "/")
+	after := f.Revision()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/changes?after=%d", url, after), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error requesting changes: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusGone {
+		return fmt.Errorf("error requesting changes: %w", ErrCompacted)
+	} else if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("error requesting changes: %s", resp.Status)
+	}
+
+	stop := make(chan struct{})
+	reported := make(chan error, 1)
+	go func() {
+		reported <- f.report(ctx, client, url, name, after, stop)
+	}()
+
+	err = f.Consume(resp.Body, nil)
+	close(stop)
+	reportErr := <-reported
+
+	if ctx.Err() != nil {
+		return ctx.Err()
+	} else if err != nil {
+		return err
+	}
+
+	return reportErr
+}
+
+// report posts the revision of the follower to the leader every ReportInterval if it changed since the last report,
+// starting from the given revision, and once more when stop is closed. It returns the first error reporting the
+// revision.
+func (f *Follower) report(ctx context.Context, client *http.Client, url string, name string, reported uint64, stop <-chan struct{}) error {
+	interval := f.ReportInterval
+	if interval <= 0 {
+		interval = DefaultReportInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		stopped := false
+		select {
+		case <-ticker.C:
+		case <-stop:
+			stopped = true
+		}
+
+		if revision := f.Revision(); revision != reported && ctx.Err() == nil {
+			if err := postRevision(ctx, client, url, name, revision); err != nil {
+				return err
+			}
+
+			reported = revision
+		}
+
+		if stopped {
+			return nil
+		}
+	}
+}
+
+func postRevision(ctx context.Context, client *http.Client, url string, name string, revision uint64) error {
+	body, err := json.Marshal(report{Follower: name, Revision: revision})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url+"/revision", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error reporting revision: %w", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("error reporting revision: %s", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,328 @@
+package replication
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/pip/memory"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// DefaultRetain is the number of changes kept by a leader created with NewLeader.
+const DefaultRetain = 1024
+
+// ErrCompacted is returned when the changes after a revision have been dropped by the leader. A follower at that
+// revision must bootstrap from a new snapshot.
+var ErrCompacted = errors.New("changes have been compacted")
+
+type (
+	// Leader records the changes made to a policy so they can be streamed to followers. The changes are numbered from 1
+	// in the order they were made, independently of the revisions published by the policy.
+	//
+	// The leader keeps the most recent changes, and drops older ones since a follower can bootstrap from a snapshot
+	// taken at a later revision. If a change cannot be applied to the copy of the policy the leader takes snapshots
+	// from, the leader stops: snapshots fail and streams end with the error returned by Err.
+	Leader struct {
+		mu sync.Mutex
+		// mirror is a copy of the policy that only changes when a change is recorded, so a snapshot of it always
+		// matches the revision of the leader
+		mirror  ngac.FunctionalEntity
+		changes []ngac.Change
+		// base is the revision before the first change that is kept
+		base      uint64
+		retain    int
+		followers map[string]uint64
+		err       error
+		// notify is closed and replaced when a change is recorded or the leader is closed
+		notify chan struct{}
+		closed bool
+		cancel func()
+	}
+
+	// Snapshot is a snapshot of a policy written by memory.Marshal and the revision of the leader it was taken at.
+	Snapshot struct {
+		Revision uint64          `json:"revision"`
+		Policy   json.RawMessage `json:"policy"`
+	}
+
+	// LeaderOptions configure a leader.
+	LeaderOptions struct {
+		// Retain is the number of changes the leader keeps at least. Followers behind the changes that are kept get
+		// ErrCompacted. If 0, DefaultRetain is used.
+		Retain int
+	}
+
+	report struct {
+		Follower string `json:"follower"`
+		Revision uint64 `json:"revision"`
+	}
+)
+
+// NewLeader returns a leader for the policy, which must implement ngac.Watchable, that keeps DefaultRetain changes.
+// The policy must not be changed while NewLeader is called.
+func NewLeader(fe ngac.FunctionalEntity) (*Leader, error) {
+	return NewLeaderWithOptions(fe, LeaderOptions{Retain: DefaultRetain})
+}
+
+// NewLeaderWithOptions returns a leader for the policy, which must implement ngac.Watchable. The policy must not be
+// changed while NewLeaderWithOptions is called.
+func NewLeaderWithOptions(fe ngac.FunctionalEntity, options LeaderOptions) (*Leader, error) {
+	if options.Retain <= 0 {
+		options.Retain = DefaultRetain
+	}
+
+	watchable, ok := fe.(ngac.Watchable)
+	if !ok {
+		return nil, fmt.Errorf("policy of type %T cannot be watched", fe)
+	}
+
+	changes, cancel := watchable.Watch()
+	mirror, err := memory.Clone(fe)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	l := &Leader{
+		mirror:    mirror,
+		changes:   make([]ngac.Change, 0),
+		retain:    options.Retain,
+		followers: make(map[string]uint64),
+		notify:    make(chan struct{}),
+		cancel:    cancel,
+	}
+
+	go l.record(changes)
+
+	return l, nil
+}
+
+func (l *Leader) record(changes <-chan ngac.Change) {
+	for change := range changes {
+		if !l.recordChange(change) {
+			// the mirror no longer matches the changes, so stop watching the policy
+			l.cancel()
+			return
+		}
+	}
+}
+
+// recordChange applies the change to the mirror and adds it to the log. It returns false if the change cannot be
+// applied, in which case the leader stops.
+func (l *Leader) recordChange(change ngac.Change) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	defer l.broadcast()
+
+	change.Revision = l.revision() + 1
+	if err := Apply(l.mirror, change); err != nil {
+		l.err = fmt.Errorf("error recording change %d: %w", change.Revision, err)
+		return false
+	}
+
+	l.changes = append(l.changes, change)
+
+	// drop the oldest changes once twice as many as retained are kept, so they are copied once per retained changes
+	if len(l.changes) >= 2*l.retain {
+		drop := len(l.changes) - l.retain
+		kept := make([]ngac.Change, l.retain, 2*l.retain)
+		copy(kept, l.changes[drop:])
+		l.changes = kept
+		l.base += uint64(drop)
+	}
+
+	return true
+}
+
+// revision returns the revision of the last change. It must be called with the lock held.
+func (l *Leader) revision() uint64 {
+	return l.base + uint64(len(l.changes))
+}
+
+// broadcast wakes the streams waiting for changes. It must be called with the lock held.
+func (l *Leader) broadcast() {
+	close(l.notify)
+	l.notify = make(chan struct{})
+}
+
+// Close stops recording changes and ends the streams of the leader.
+func (l *Leader) Close() {
+	l.cancel()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.closed {
+		l.closed = true
+		l.broadcast()
+	}
+}
+
+// Revision returns the number of changes the leader has recorded.
+func (l *Leader) Revision() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.revision()
+}
+
+// Err returns the error that stopped the leader, or nil if it is recording changes.
+func (l *Leader) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.err
+}
+
+// Snapshot returns a snapshot of the policy at the current revision of the leader.
+func (l *Leader) Snapshot() (Snapshot, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.err != nil {
+		return Snapshot{}, l.err
+	}
+
+	policy, err := memory.Marshal(l.mirror)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	return Snapshot{Revision: l.revision(), Policy: policy}, nil
+}
+
+// Stream writes the changes after the given revision to w as JSON lines, waiting for new changes until the context
+// is done or the leader is closed. ErrCompacted is returned if the changes after the revision have been dropped, and
+// the error that stopped the leader is returned if it stops.
+func (l *Leader) Stream(ctx context.Context, w io.Writer, after uint64) error {
+	encoder := json.NewEncoder(w)
+	for {
+		l.mu.Lock()
+		if err := l.check(after); err != nil {
+			l.mu.Unlock()
+			return err
+		}
+
+		changes := l.changes[after-l.base:]
+		notify, closed := l.notify, l.closed
+		l.mu.Unlock()
+
+		for _, change := range changes {
+			if err := encoder.Encode(change); err != nil {
+				return err
+			}
+		}
+
+		after += uint64(len(changes))
+		if len(changes) > 0 {
+			if flusher, ok := w.(http.Flusher); ok {
+				flusher.Flush()
+			}
+
+			continue
+		}
+
+		if closed {
+			return nil
+		}
+
+		select {
+		case <-notify:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// check returns an error if the changes after the revision cannot be streamed. It must be called with the lock held.
+func (l *Leader) check(after uint64) error {
+	if l.err != nil {
+		return l.err
+	} else if after > l.revision() {
+		return fmt.Errorf("revision %d is ahead of the leader", after)
+	} else if after < l.base {
+		return fmt.Errorf("%w: revision %d is behind revision %d", ErrCompacted, after, l.base)
+	}
+
+	return nil
+}
+
+// Report records the revision a follower has applied.
+func (l *Leader) Report(follower string, revision uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.followers[follower] = revision
+}
+
+// Followers returns the revisions the followers reported keyed by follower.
+func (l *Leader) Followers() map[string]uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	followers := make(map[string]uint64, len(l.followers))
+	for follower, revision := range l.followers {
+		followers[follower] = revision
+	}
+
+	return followers
+}
+
+// ServeHTTP serves the snapshot at GET /snapshot, streams the changes after a revision at GET /changes?after=n and
+// records the revision of a follower posted to /revision. Requesting changes that have been dropped responds with
+// 410 Gone, and a stopped leader responds with 500 Internal Server Error.
+func (l *Leader) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/snapshot":
+		snapshot, err := l.Snapshot()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(snapshot)
+	case r.Method == http.MethodGet && r.URL.Path == "/changes":
+		after, err := strconv.ParseUint(r.URL.Query().Get("after"), 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid revision %q", r.URL.Query().Get("after")), http.StatusBadRequest)
+			return
+		}
+
+		l.mu.Lock()
+		err = l.check(after)
+		stopped := l.err != nil
+		l.mu.Unlock()
+
+		switch {
+		case stopped:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		case errors.Is(err, ErrCompacted):
+			http.Error(w, err.Error(), http.StatusGone)
+			return
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		_ = l.Stream(r.Context(), w, after)
+	case r.Method == http.MethodPost && r.URL.Path == "/revision":
+		rep := report{}
+		if err := json.NewDecoder(r.Body).Decode(&rep); err != nil || rep.Follower == "" {
+			http.Error(w, "invalid report", http.StatusBadRequest)
+			return
+		}
+
+		l.Report(rep.Follower, rep.Revision)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.NotFound(w, r)
+	}
+}
@@ -0,0 +1,275 @@
+package replication
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/PM-Master/policy-machine-go/diff"
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
+	"github.com/PM-Master/policy-machine-go/pdp"
+	"github.com/PM-Master/policy-machine-go/pip/memory"
+	"github.com/stretchr/testify/require"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newLeaderPolicy(t *testing.T) ngac.FunctionalEntity {
+	pip := memory.NewPIP()
+	g := pip.Graph()
+	require.NoError(t, g.CreatePolicyClass("pc1"))
+	_, err := g.CreateNode("ua1", graph.UserAttribute, nil, "pc1")
+	require.NoError(t, err)
+	_, err = g.CreateNode("oa1", graph.ObjectAttribute, nil, "pc1")
+	require.NoError(t, err)
+	_, err = g.CreateNode("u1", graph.User, nil, "ua1")
+	require.NoError(t, err)
+
+	return pip
+}
+
+// change makes changes of every kind to the policy.
+func change(t *testing.T, pip ngac.FunctionalEntity) {
+	g := pip.Graph()
+	_, err := g.CreateNode("o1", graph.Object, map[string]string{"k": "v"}, "oa1")
+	require.NoError(t, err)
+	require.NoError(t, g.UpdateNode("o1", map[string]string{"k": "v2"}))
+	_, err = g.CreateNode("oa2", graph.ObjectAttribute, nil, "pc1")
+	require.NoError(t, err)
+	require.NoError(t, g.Assign("o1", "oa2"))
+	require.NoError(t, g.Deassign("o1", "oa1"))
+	require.NoError(t, g.Associate("ua1", "oa2", graph.ToOps("read", "write")))
+	require.NoError(t, g.AssociateWithCondition("ua1", "oa1", graph.ToOps("read"), `env.network == "internal"`))
+	notAfter := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, g.SetAssociationValidity("ua1", "oa1", graph.Validity{NotAfter: &notAfter}))
+	require.NoError(t, pip.Prohibitions().Add(ngac.Prohibition{
+		Name:       "deny1",
+		Subject:    "u1",
+		Containers: map[string]bool{"oa2": false},
+		Operations: graph.ToOps("write"),
+	}))
+	require.NoError(t, pip.Obligations().Add(ngac.Obligation{
+		Label: "o1",
+		Event: ngac.EventPattern{Subject: "any_user", Operations: []ngac.EventOperation{{Operation: "read"}}},
+		Response: ngac.ResponsePattern{Actions: []ngac.Statement{
+			&ngac.CreateNodeStatement{Name: "copy", Kind: graph.Object, Parents: []string{"oa1"}},
+		}},
+	}))
+	require.NoError(t, pip.Obligations().Disable("o1"))
+	require.NoError(t, pip.Constraints().AddDSoD(ngac.DSoD{Name: "c1", Operations: []string{"read", "write"}}))
+	_, err = g.CreateNode("tmp", graph.Object, nil, "oa1")
+	require.NoError(t, err)
+	require.NoError(t, g.DeleteNode("tmp"))
+}
+
+func requireReplicated(t *testing.T, leader *Leader, follower *Follower, pip ngac.FunctionalEntity) {
+	require.Eventually(t, func() bool {
+		return follower.Revision() == leader.Revision()
+	}, 5*time.Second, time.Millisecond)
+
+	require.NoError(t, follower.Read(func(fe ngac.FunctionalEntity) error {
+		d, err := diff.Compare(pip, fe)
+		require.NoError(t, err)
+		require.True(t, d.Empty(), d.String())
+
+		dsod, err := fe.Constraints().GetDSoD()
+		require.NoError(t, err)
+		require.Len(t, dsod, 1)
+
+		ops, err := pdp.NewDecider(fe.Graph(), fe.Prohibitions()).ListPermissions("u1", "o1")
+		require.NoError(t, err)
+		require.Equal(t, graph.ToOps("read"), ops)
+
+		return nil
+	}))
+}
+
+func TestReplicationOverHTTP(t *testing.T) {
+	pip := newLeaderPolicy(t)
+	leader, err := NewLeader(pip)
+	require.NoError(t, err)
+	defer leader.Close()
+
+	var reports int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/revision" {
+			atomic.AddInt32(&reports, 1)
+		}
+		leader.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	follower, err := Bootstrap(ctx, server.Client(), server.URL)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), follower.Revision())
+
+	done := make(chan error, 1)
+	go func() {
+		done <- follower.Follow(ctx, server.Client(), server.URL, "f1")
+	}()
+
+	change(t, pip)
+	requireReplicated(t, leader, follower, pip)
+	require.Eventually(t, func() bool {
+		return leader.Followers()["f1"] == leader.Revision()
+	}, 5*time.Second, time.Millisecond)
+
+	// the revision is reported once per interval rather than after every change
+	require.Less(t, int(atomic.LoadInt32(&reports)), int(leader.Revision()))
+
+	// a new follower bootstraps from a snapshot that includes the changes
+	late, err := Bootstrap(ctx, server.Client(), server.URL)
+	require.NoError(t, err)
+	require.Equal(t, leader.Revision(), late.Revision())
+	requireReplicated(t, leader, late, pip)
+
+	cancel()
+	require.Equal(t, context.Canceled, <-done)
+}
+
+func TestReplicationOverPipe(t *testing.T) {
+	pip := newLeaderPolicy(t)
+	leader, err := NewLeader(pip)
+	require.NoError(t, err)
+
+	snapshot, err := leader.Snapshot()
+	require.NoError(t, err)
+	follower, err := NewFollower(snapshot)
+	require.NoError(t, err)
+
+	r, w := io.Pipe()
+	go func() {
+		w.CloseWithError(leader.Stream(context.Background(), w, snapshot.Revision))
+	}()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- follower.Consume(r, func(revision uint64) {
+			leader.Report("f1", revision)
+		})
+	}()
+
+	change(t, pip)
+	requireReplicated(t, leader, follower, pip)
+
+	// restoring a snapshot on the leader replaces the policy of the follower
+	initial, err := memory.Marshal(newLeaderPolicy(t))
+	require.NoError(t, err)
+	require.NoError(t, memory.Restore(pip, initial))
+	require.Eventually(t, func() bool {
+		return follower.Revision() == leader.Revision()
+	}, 5*time.Second, time.Millisecond)
+	require.NoError(t, follower.Read(func(fe ngac.FunctionalEntity) error {
+		ok, err := fe.Graph().Exists("o1")
+		require.NoError(t, err)
+		require.False(t, ok)
+		return nil
+	}))
+
+	// closing the leader ends the stream
+	leader.Close()
+	require.NoError(t, <-done)
+	require.Equal(t, leader.Revision(), leader.Followers()["f1"])
+}
+
+func TestFollowerApplyOrder(t *testing.T) {
+	pip := newLeaderPolicy(t)
+	policy, err := memory.Marshal(pip)
+	require.NoError(t, err)
+
+	follower, err := NewFollower(Snapshot{Revision: 3, Policy: policy})
+	require.NoError(t, err)
+
+	// changes already applied are ignored and gaps are rejected
+	require.NoError(t, follower.Apply(ngac.Change{Revision: 3, Kind: ngac.NodeDeleted, Node: &graph.Node{Name: "u1"}}))
+	require.Error(t, follower.Apply(ngac.Change{Revision: 5, Kind: ngac.NodeDeleted, Node: &graph.Node{Name: "u1"}}))
+	require.NoError(t, follower.Apply(ngac.Change{Revision: 4, Kind: ngac.NodeDeleted, Node: &graph.Node{Name: "u1"}}))
+	require.Equal(t, uint64(4), follower.Revision())
+}
+
+func TestLeaderCompaction(t *testing.T) {
+	pip := newLeaderPolicy(t)
+	leader, err := NewLeaderWithOptions(pip, LeaderOptions{Retain: 2})
+	require.NoError(t, err)
+	defer leader.Close()
+
+	server := httptest.NewServer(leader)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stale, err := Bootstrap(ctx, server.Client(), server.URL)
+	require.NoError(t, err)
+
+	change(t, pip)
+	require.Eventually(t, func() bool {
+		return leader.Revision() == 14
+	}, 5*time.Second, time.Millisecond)
+
+	// the changes after revision 0 have been dropped, so the follower must bootstrap again
+	require.True(t, errors.Is(leader.Stream(ctx, ioutil.Discard, 0), ErrCompacted))
+	require.True(t, errors.Is(stale.Follow(ctx, server.Client(), server.URL, "f1"), ErrCompacted))
+
+	follower, err := Bootstrap(ctx, server.Client(), server.URL)
+	require.NoError(t, err)
+	requireReplicated(t, leader, follower, pip)
+
+	// the retained changes can still be streamed
+	r, w := io.Pipe()
+	go func() {
+		w.CloseWithError(leader.Stream(ctx, w, leader.Revision()-2))
+	}()
+	require.NoError(t, json.NewDecoder(r).Decode(&ngac.Change{}))
+	r.Close()
+}
+
+func TestLeaderStopsWhenMirrorDiverges(t *testing.T) {
+	pip := newLeaderPolicy(t)
+	leader, err := NewLeader(pip)
+	require.NoError(t, err)
+	defer leader.Close()
+
+	server := httptest.NewServer(leader)
+	defer server.Close()
+
+	r, w := io.Pipe()
+	streamed := make(chan error, 1)
+	go func() {
+		err := leader.Stream(context.Background(), w, 0)
+		w.Close()
+		streamed <- err
+	}()
+	go io.Copy(ioutil.Discard, r)
+
+	// a change the mirror cannot apply stops the leader
+	leader.mu.Lock()
+	_, err = leader.mirror.Graph().CreateNode("o1", graph.Object, nil, "oa1")
+	leader.mu.Unlock()
+	require.NoError(t, err)
+	_, err = pip.Graph().CreateNode("o1", graph.Object, nil, "oa1")
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		return leader.Err() != nil
+	}, 5*time.Second, time.Millisecond)
+	require.Error(t, <-streamed)
+
+	_, err = leader.Snapshot()
+	require.Error(t, err)
+	_, err = Bootstrap(context.Background(), server.Client(), server.URL)
+	require.Error(t, err)
+
+	resp, err := server.Client().Get(server.URL + "/changes?after=0")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusInternalServerError, resp.StatusCode)
+}
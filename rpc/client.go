@@ -0,0 +1,653 @@
+package rpc
+
+import (
+	"context"
+	"github.com/PM-Master/policy-machine-go/epp"
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
+	"github.com/PM-Master/policy-machine-go/pdp"
+	"github.com/PM-Master/policy-machine-go/rpc/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"time"
+)
+
+type (
+	// Client implements ngac.FunctionalEntity, pdp.Decider and epp.EventProcessor with the services of a Server, so it
+	// can be used in place of a local policy. Errors returned by the server are returned with the same message and
+	// keep their status, which status.Code returns, and errors.Is matches errors with codes.NotFound,
+	// codes.AlreadyExists and codes.InvalidArgument with ngac.ErrNotFound, ngac.ErrAlreadyExists and ngac.ErrInvalid.
+	Client struct {
+		administration pb.PolicyAdministrationClient
+		decision       pb.PolicyDecisionClient
+		events         pb.EventProcessingClient
+		environment    map[string]string
+		timeout        time.Duration
+	}
+
+	// ClientOptions configure a client.
+	ClientOptions struct {
+		// Timeout is the deadline of each call to the server. If 0, DefaultTimeout is used.
+		Timeout time.Duration
+	}
+
+	remoteGraph        struct{ c *Client }
+	remoteProhibitions struct{ c *Client }
+	remoteObligations  struct{ c *Client }
+	remoteConstraints  struct{ c *Client }
+)
+
+var (
+	_ ngac.FunctionalEntity = (*Client)(nil)
+	_ pdp.Decider           = (*Client)(nil)
+	_ epp.EventProcessor    = (*Client)(nil)
+)
+
+// DefaultTimeout is the deadline of each call made by a client created by NewClient.
+const DefaultTimeout = 30 * time.Second
+
+// NewClient returns a client for the services served on the connection whose calls time out after DefaultTimeout.
+func NewClient(conn grpc.ClientConnInterface) *Client {
+	return NewClientWithOptions(conn, ClientOptions{Timeout: DefaultTimeout})
+}
+
+// NewClientWithOptions returns a client for the services served on the connection.
+func NewClientWithOptions(conn grpc.ClientConnInterface, options ClientOptions) *Client {
+	if options.Timeout <= 0 {
+		options.Timeout = DefaultTimeout
+	}
+
+	return &Client{
+		administration: pb.NewPolicyAdministrationClient(conn),
+		decision:       pb.NewPolicyDecisionClient(conn),
+		events:         pb.NewEventProcessingClient(conn),
+		timeout:        options.Timeout,
+	}
+}
+
+// context returns the context of a call to the server and the function that releases it.
+func (c *Client) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), c.timeout)
+}
+
+func (c *Client) Graph() ngac.Graph {
+	return remoteGraph{c}
+}
+
+func (c *Client) Prohibitions() ngac.Prohibitions {
+	return remoteProhibitions{c}
+}
+
+func (c *Client) Obligations() ngac.Obligations {
+	return remoteObligations{c}
+}
+
+func (c *Client) Constraints() ngac.Constraints {
+	return remoteConstraints{c}
+}
+
+func (c *Client) marshal(part pb.PolicyPart) ([]byte, error) {
+	ctx, cancel := c.context()
+	defer cancel()
+	resp, err := c.administration.MarshalPolicy(ctx, &pb.PolicyData{Part: part})
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	return resp.GetJson(), nil
+}
+
+func (c *Client) unmarshal(part pb.PolicyPart, bytes []byte) error {
+	ctx, cancel := c.context()
+	defer cancel()
+	_, err := c.administration.UnmarshalPolicy(ctx, &pb.PolicyData{Part: part, Json: bytes})
+	return toError(err)
+}
+
+func (g remoteGraph) CreatePolicyClass(name string) error {
+	ctx, cancel := g.c.context()
+	defer cancel()
+	_, err := g.c.administration.CreatePolicyClass(ctx, &pb.NodeRequest{Name: name})
+	return toError(err)
+}
+
+func (g remoteGraph) CreateNode(name string, kind graph.Kind, properties map[string]string, parent string, parents ...string) (graph.Node, error) {
+	ctx, cancel := g.c.context()
+	defer cancel()
+	node, err := g.c.administration.CreateNode(ctx, &pb.CreateNodeRequest{
+		Name:       name,
+		Kind:       pb.Kind(kind),
+		Properties: properties,
+		Parents:    append([]string{parent}, parents...),
+	})
+	if err != nil {
+		return graph.Node{}, toError(err)
+	}
+
+	return toNode(node), nil
+}
+
+func (g remoteGraph) UpdateNode(name string, properties map[string]string) error {
+	ctx, cancel := g.c.context()
+	defer cancel()
+	_, err := g.c.administration.UpdateNode(ctx, &pb.UpdateNodeRequest{Name: name, Properties: properties})
+	return toError(err)
+}
+
+func (g remoteGraph) DeleteNode(name string) error {
+	ctx, cancel := g.c.context()
+	defer cancel()
+	_, err := g.c.administration.DeleteNode(ctx, &pb.NodeRequest{Name: name})
+	return toError(err)
+}
+
+func (g remoteGraph) Exists(name string) (bool, error) {
+	ctx, cancel := g.c.context()
+	defer cancel()
+	resp, err := g.c.administration.Exists(ctx, &pb.NodeRequest{Name: name})
+	if err != nil {
+		return false, toError(err)
+	}
+
+	return resp.GetExists(), nil
+}
+
+func (g remoteGraph) GetNodes() (map[string]graph.Node, error) {
+	ctx, cancel := g.c.context()
+	defer cancel()
+	resp, err := g.c.administration.GetNodes(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	return toNodes(resp), nil
+}
+
+func (g remoteGraph) GetNode(name string) (graph.Node, error) {
+	ctx, cancel := g.c.context()
+	defer cancel()
+	node, err := g.c.administration.GetNode(ctx, &pb.NodeRequest{Name: name})
+	if err != nil {
+		return graph.Node{}, toError(err)
+	}
+
+	return toNode(node), nil
+}
+
+func (g remoteGraph) Find(kind graph.Kind, properties map[string]string) (map[string]graph.Node, error) {
+	ctx, cancel := g.c.context()
+	defer cancel()
+	resp, err := g.c.administration.Find(ctx, &pb.FindRequest{Kind: pb.Kind(kind), Properties: properties})
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	return toNodes(resp), nil
+}
+
+func (g remoteGraph) Assign(child string, parent string) error {
+	ctx, cancel := g.c.context()
+	defer cancel()
+	_, err := g.c.administration.Assign(ctx, &pb.Assignment{Child: child, Parent: parent})
+	return toError(err)
+}
+
+func (g remoteGraph) Deassign(child string, parent string) error {
+	ctx, cancel := g.c.context()
+	defer cancel()
+	_, err := g.c.administration.Deassign(ctx, &pb.Assignment{Child: child, Parent: parent})
+	return toError(err)
+}
+
+func (g remoteGraph) GetChildren(name string) (map[string]graph.Node, error) {
+	ctx, cancel := g.c.context()
+	defer cancel()
+	resp, err := g.c.administration.GetChildren(ctx, &pb.NodeRequest{Name: name})
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	return toNodes(resp), nil
+}
+
+func (g remoteGraph) GetParents(name string) (map[string]graph.Node, error) {
+	ctx, cancel := g.c.context()
+	defer cancel()
+	resp, err := g.c.administration.GetParents(ctx, &pb.NodeRequest{Name: name})
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	return toNodes(resp), nil
+}
+
+func (g remoteGraph) GetAssignments() (map[string]map[string]bool, error) {
+	ctx, cancel := g.c.context()
+	defer cancel()
+	resp, err := g.c.administration.GetAssignments(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	assignments := make(map[string]map[string]bool, len(resp.GetAssignments()))
+	for child, parents := range resp.GetAssignments() {
+		assignments[child] = make(map[string]bool, len(parents.GetNames()))
+		for _, parent := range parents.GetNames() {
+			assignments[child][parent] = true
+		}
+	}
+
+	return assignments, nil
+}
+
+func (g remoteGraph) Associate(subject string, target string, operations graph.Operations) error {
+	ctx, cancel := g.c.context()
+	defer cancel()
+	_, err := g.c.administration.Associate(ctx, &pb.Association{
+		Subject:    subject,
+		Target:     target,
+		Operations: fromOperations(operations),
+	})
+	return toError(err)
+}
+
+func (g remoteGraph) AssociateWithCondition(subject string, target string, operations graph.Operations, condition string) error {
+	ctx, cancel := g.c.context()
+	defer cancel()
+	_, err := g.c.administration.AssociateWithCondition(ctx, &pb.Association{
+		Subject:    subject,
+		Target:     target,
+		Operations: fromOperations(operations),
+		Condition:  condition,
+	})
+	return toError(err)
+}
+
+func (g remoteGraph) Dissociate(subject string, target string) error {
+	ctx, cancel := g.c.context()
+	defer cancel()
+	_, err := g.c.administration.Dissociate(ctx, &pb.Association{Subject: subject, Target: target})
+	return toError(err)
+}
+
+func (g remoteGraph) GetAssociationsForSubject(subject string) (map[string]graph.Operations, error) {
+	ctx, cancel := g.c.context()
+	defer cancel()
+	resp, err := g.c.administration.GetAssociationsForSubject(ctx, &pb.NodeRequest{Name: subject})
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	return toTargets(resp), nil
+}
+
+func (g remoteGraph) GetAssociations() (map[string]map[string]graph.Operations, error) {
+	ctx, cancel := g.c.context()
+	defer cancel()
+	resp, err := g.c.administration.GetAssociations(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	associations := make(map[string]map[string]graph.Operations, len(resp.GetAssociations()))
+	for subject, targets := range resp.GetAssociations() {
+		associations[subject] = toTargets(targets)
+	}
+
+	return associations, nil
+}
+
+func (g remoteGraph) GetAssociationConditions(subject string) (map[string]string, error) {
+	ctx, cancel := g.c.context()
+	defer cancel()
+	resp, err := g.c.administration.GetAssociationConditions(ctx, &pb.NodeRequest{Name: subject})
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	return toProperties(resp.GetConditions()), nil
+}
+
+func (g remoteGraph) SetAssociationValidity(subject string, target string, validity graph.Validity) error {
+	ctx, cancel := g.c.context()
+	defer cancel()
+	_, err := g.c.administration.SetAssociationValidity(ctx, &pb.Association{
+		Subject:  subject,
+		Target:   target,
+		Validity: fromValidity(validity),
+	})
+	return toError(err)
+}
+
+func (g remoteGraph) GetAssociationValidity(subject string) (map[string]graph.Validity, error) {
+	ctx, cancel := g.c.context()
+	defer cancel()
+	resp, err := g.c.administration.GetAssociationValidity(ctx, &pb.NodeRequest{Name: subject})
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	validity := make(map[string]graph.Validity, len(resp.GetValidity()))
+	for target, v := range resp.GetValidity() {
+		validity[target] = toValidity(v)
+	}
+
+	return validity, nil
+}
+
+func (g remoteGraph) MarshalJSON() ([]byte, error) {
+	return g.c.marshal(pb.PolicyPart_GRAPH)
+}
+
+func (g remoteGraph) UnmarshalJSON(bytes []byte) error {
+	return g.c.unmarshal(pb.PolicyPart_GRAPH, bytes)
+}
+
+func (p remoteProhibitions) Add(prohibition ngac.Prohibition) error {
+	ctx, cancel := p.c.context()
+	defer cancel()
+	_, err := p.c.administration.AddProhibition(ctx, fromProhibition(prohibition))
+	return toError(err)
+}
+
+func (p remoteProhibitions) Get(subject string) ([]ngac.Prohibition, error) {
+	ctx, cancel := p.c.context()
+	defer cancel()
+	resp, err := p.c.administration.GetProhibitions(ctx, &pb.NodeRequest{Name: subject})
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	return toProhibitions(resp), nil
+}
+
+func (p remoteProhibitions) GetByName(name string) (ngac.Prohibition, error) {
+	ctx, cancel := p.c.context()
+	defer cancel()
+	prohibition, err := p.c.administration.GetProhibition(ctx, &pb.NameRequest{Name: name})
+	if err != nil {
+		return ngac.Prohibition{}, toError(err)
+	}
+
+	return toProhibition(prohibition), nil
+}
+
+func (p remoteProhibitions) All() ([]ngac.Prohibition, error) {
+	ctx, cancel := p.c.context()
+	defer cancel()
+	resp, err := p.c.administration.AllProhibitions(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	return toProhibitions(resp), nil
+}
+
+func (p remoteProhibitions) Update(prohibition ngac.Prohibition) error {
+	ctx, cancel := p.c.context()
+	defer cancel()
+	_, err := p.c.administration.UpdateProhibition(ctx, fromProhibition(prohibition))
+	return toError(err)
+}
+
+func (p remoteProhibitions) Delete(subject string, prohibitionName string) error {
+	ctx, cancel := p.c.context()
+	defer cancel()
+	_, err := p.c.administration.DeleteProhibition(ctx, &pb.DeleteProhibitionRequest{
+		Subject: subject,
+		Name:    prohibitionName,
+	})
+	return toError(err)
+}
+
+func (p remoteProhibitions) MarshalJSON() ([]byte, error) {
+	return p.c.marshal(pb.PolicyPart_PROHIBITIONS)
+}
+
+func (p remoteProhibitions) UnmarshalJSON(bytes []byte) error {
+	return p.c.unmarshal(pb.PolicyPart_PROHIBITIONS, bytes)
+}
+
+func (o remoteObligations) Add(obligation ngac.Obligation) error {
+	req, err := fromObligation(obligation)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := o.c.context()
+	defer cancel()
+	_, err = o.c.administration.AddObligation(ctx, req)
+	return toError(err)
+}
+
+func (o remoteObligations) Remove(label string) error {
+	ctx, cancel := o.c.context()
+	defer cancel()
+	_, err := o.c.administration.RemoveObligation(ctx, &pb.NameRequest{Name: label})
+	return toError(err)
+}
+
+func (o remoteObligations) Get(label string) (ngac.Obligation, error) {
+	ctx, cancel := o.c.context()
+	defer cancel()
+	obligation, err := o.c.administration.GetObligation(ctx, &pb.NameRequest{Name: label})
+	if err != nil {
+		return ngac.Obligation{}, toError(err)
+	}
+
+	return toObligation(obligation)
+}
+
+func (o remoteObligations) All() ([]ngac.Obligation, error) {
+	ctx, cancel := o.c.context()
+	defer cancel()
+	resp, err := o.c.administration.AllObligations(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	obligations := make([]ngac.Obligation, 0, len(resp.GetObligations()))
+	for _, obligation := range resp.GetObligations() {
+		ob, err := toObligation(obligation)
+		if err != nil {
+			return nil, err
+		}
+
+		obligations = append(obligations, ob)
+	}
+
+	return obligations, nil
+}
+
+func (o remoteObligations) Enable(label string) error {
+	ctx, cancel := o.c.context()
+	defer cancel()
+	_, err := o.c.administration.EnableObligation(ctx, &pb.NameRequest{Name: label})
+	return toError(err)
+}
+
+func (o remoteObligations) Disable(label string) error {
+	ctx, cancel := o.c.context()
+	defer cancel()
+	_, err := o.c.administration.DisableObligation(ctx, &pb.NameRequest{Name: label})
+	return toError(err)
+}
+
+func (o remoteObligations) MarshalJSON() ([]byte, error) {
+	return o.c.marshal(pb.PolicyPart_OBLIGATIONS)
+}
+
+func (o remoteObligations) UnmarshalJSON(bytes []byte) error {
+	return o.c.unmarshal(pb.PolicyPart_OBLIGATIONS, bytes)
+}
+
+func (r remoteConstraints) AddSSoD(constraint ngac.SSoD) error {
+	ctx, cancel := r.c.context()
+	defer cancel()
+	_, err := r.c.administration.AddSSoD(ctx, fromSSoD(constraint))
+	return toError(err)
+}
+
+func (r remoteConstraints) GetSSoD() ([]ngac.SSoD, error) {
+	ctx, cancel := r.c.context()
+	defer cancel()
+	resp, err := r.c.administration.GetSSoD(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	constraints := make([]ngac.SSoD, 0, len(resp.GetConstraints()))
+	for _, constraint := range resp.GetConstraints() {
+		constraints = append(constraints, toSSoD(constraint))
+	}
+
+	return constraints, nil
+}
+
+func (r remoteConstraints) RemoveSSoD(name string) error {
+	ctx, cancel := r.c.context()
+	defer cancel()
+	_, err := r.c.administration.RemoveSSoD(ctx, &pb.NameRequest{Name: name})
+	return toError(err)
+}
+
+func (r remoteConstraints) AddDSoD(constraint ngac.DSoD) error {
+	ctx, cancel := r.c.context()
+	defer cancel()
+	_, err := r.c.administration.AddDSoD(ctx, fromDSoD(constraint))
+	return toError(err)
+}
+
+func (r remoteConstraints) GetDSoD() ([]ngac.DSoD, error) {
+	ctx, cancel := r.c.context()
+	defer cancel()
+	resp, err := r.c.administration.GetDSoD(ctx, &emptypb.Empty{})
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	constraints := make([]ngac.DSoD, 0, len(resp.GetConstraints()))
+	for _, constraint := range resp.GetConstraints() {
+		constraints = append(constraints, toDSoD(constraint))
+	}
+
+	return constraints, nil
+}
+
+func (r remoteConstraints) RemoveDSoD(name string) error {
+	ctx, cancel := r.c.context()
+	defer cancel()
+	_, err := r.c.administration.RemoveDSoD(ctx, &pb.NameRequest{Name: name})
+	return toError(err)
+}
+
+func (r remoteConstraints) MarshalJSON() ([]byte, error) {
+	return r.c.marshal(pb.PolicyPart_CONSTRAINTS)
+}
+
+func (r remoteConstraints) UnmarshalJSON(bytes []byte) error {
+	return r.c.unmarshal(pb.PolicyPart_CONSTRAINTS, bytes)
+}
+
+func (c *Client) HasPermissions(user string, target string, permissions ...string) (bool, error) {
+	ctx, cancel := c.context()
+	defer cancel()
+	resp, err := c.decision.HasPermissions(ctx, &pb.DecisionRequest{
+		User:        user,
+		Target:      target,
+		Permissions: permissions,
+		Environment: c.environment,
+	})
+	if err != nil {
+		return false, toError(err)
+	}
+
+	return resp.GetAllowed(), nil
+}
+
+func (c *Client) ListPermissions(user string, target string) (graph.Operations, error) {
+	ctx, cancel := c.context()
+	defer cancel()
+	resp, err := c.decision.ListPermissions(ctx, &pb.DecisionRequest{
+		User:        user,
+		Target:      target,
+		Environment: c.environment,
+	})
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	return graph.ToOps(resp.GetOperations()...), nil
+}
+
+func (c *Client) DecideFor(user string, process string, target string, permissions ...string) (bool, error) {
+	ctx, cancel := c.context()
+	defer cancel()
+	resp, err := c.decision.DecideFor(ctx, &pb.DecisionRequest{
+		User:        user,
+		Process:     process,
+		Target:      target,
+		Permissions: permissions,
+		Environment: c.environment,
+	})
+	if err != nil {
+		return false, toError(err)
+	}
+
+	return resp.GetAllowed(), nil
+}
+
+func (c *Client) ListPermissionsBatch(user string, targets []string) (map[string]graph.Operations, error) {
+	ctx, cancel := c.context()
+	defer cancel()
+	resp, err := c.decision.ListPermissionsBatch(ctx, &pb.BatchRequest{
+		User:        user,
+		Targets:     targets,
+		Environment: c.environment,
+	})
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	permissions := make(map[string]graph.Operations, len(resp.GetPermissions()))
+	for target, ops := range resp.GetPermissions() {
+		permissions[target] = graph.ToOps(ops.GetOperations()...)
+	}
+
+	return permissions, nil
+}
+
+func (c *Client) FilterAccessible(user string, targets []string, permissions ...string) ([]string, error) {
+	ctx, cancel := c.context()
+	defer cancel()
+	resp, err := c.decision.FilterAccessible(ctx, &pb.BatchRequest{
+		User:        user,
+		Targets:     targets,
+		Permissions: permissions,
+		Environment: c.environment,
+	})
+	if err != nil {
+		return nil, toError(err)
+	}
+
+	return append(make([]string, 0), resp.GetTargets()...), nil
+}
+
+// WithEnvironment returns a client that sends the environment attributes with its decision requests.
+func (c *Client) WithEnvironment(environment map[string]string) pdp.Decider {
+	clone := *c
+	clone.environment = environment
+	return &clone
+}
+
+func (c *Client) ProcessEvent(eventCtx epp.EventContext) error {
+	ctx, cancel := c.context()
+	defer cancel()
+	_, err := c.events.ProcessEvent(ctx, &pb.Event{
+		User:   eventCtx.User,
+		Event:  eventCtx.Event,
+		Target: eventCtx.Target,
+		Args:   eventCtx.Args,
+	})
+	return toError(err)
+}
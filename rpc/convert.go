@@ -0,0 +1,250 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
+	"github.com/PM-Master/policy-machine-go/rpc/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"sort"
+	"time"
+)
+
+func toNode(node *pb.Node) graph.Node {
+	return graph.Node{Name: node.GetName(), Kind: graph.Kind(node.GetKind()), Properties: toProperties(node.GetProperties())}
+}
+
+func fromNode(node graph.Node) *pb.Node {
+	return &pb.Node{Name: node.Name, Kind: pb.Kind(node.Kind), Properties: node.Properties}
+}
+
+// toProperties returns an empty map for properties that were not sent, as the graph never returns nil properties.
+func toProperties(properties map[string]string) map[string]string {
+	if properties == nil {
+		return make(map[string]string)
+	}
+
+	return properties
+}
+
+func toNodes(resp *pb.NodesResponse) map[string]graph.Node {
+	nodes := make(map[string]graph.Node, len(resp.GetNodes()))
+	for _, node := range resp.GetNodes() {
+		nodes[node.GetName()] = toNode(node)
+	}
+
+	return nodes
+}
+
+func fromNodes(nodes map[string]graph.Node) *pb.NodesResponse {
+	resp := &pb.NodesResponse{Nodes: make([]*pb.Node, 0, len(nodes))}
+	for _, name := range sortedKeys(nodes) {
+		resp.Nodes = append(resp.Nodes, fromNode(nodes[name]))
+	}
+
+	return resp
+}
+
+func sortedKeys(nodes map[string]graph.Node) []string {
+	names := make([]string, 0, len(nodes))
+	for name := range nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func fromOperations(operations graph.Operations) []string {
+	ops := make([]string, 0, len(operations))
+	for op := range operations {
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+
+	return ops
+}
+
+func toTargets(targets *pb.Targets) map[string]graph.Operations {
+	associations := make(map[string]graph.Operations, len(targets.GetTargets()))
+	for target, ops := range targets.GetTargets() {
+		associations[target] = graph.ToOps(ops.GetOperations()...)
+	}
+
+	return associations
+}
+
+func fromTargets(associations map[string]graph.Operations) *pb.Targets {
+	targets := &pb.Targets{Targets: make(map[string]*pb.Operations, len(associations))}
+	for target, ops := range associations {
+		targets.Targets[target] = &pb.Operations{Operations: fromOperations(ops)}
+	}
+
+	return targets
+}
+
+func toTime(timestamp *timestamppb.Timestamp) *time.Time {
+	if timestamp == nil {
+		return nil
+	}
+
+	t := timestamp.AsTime()
+	return &t
+}
+
+func fromTime(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+
+	return timestamppb.New(*t)
+}
+
+func toValidity(validity *pb.Validity) graph.Validity {
+	return graph.Validity{NotBefore: toTime(validity.GetNotBefore()), NotAfter: toTime(validity.GetNotAfter())}
+}
+
+func fromValidity(validity graph.Validity) *pb.Validity {
+	if validity.IsZero() {
+		return nil
+	}
+
+	return &pb.Validity{NotBefore: fromTime(validity.NotBefore), NotAfter: fromTime(validity.NotAfter)}
+}
+
+func toProhibition(prohibition *pb.Prohibition) ngac.Prohibition {
+	return ngac.Prohibition{
+		Name:         prohibition.GetName(),
+		Subject:      prohibition.GetSubject(),
+		Process:      prohibition.GetProcess(),
+		Containers:   prohibition.GetContainers(),
+		Operations:   graph.ToOps(prohibition.GetOperations()...),
+		Intersection: prohibition.GetIntersection(),
+		Validity:     toValidity(prohibition.GetValidity()),
+	}
+}
+
+func fromProhibition(prohibition ngac.Prohibition) *pb.Prohibition {
+	return &pb.Prohibition{
+		Name:         prohibition.Name,
+		Subject:      prohibition.Subject,
+		Process:      prohibition.Process,
+		Containers:   prohibition.Containers,
+		Operations:   fromOperations(prohibition.Operations),
+		Intersection: prohibition.Intersection,
+		Validity:     fromValidity(prohibition.Validity),
+	}
+}
+
+func toProhibitions(resp *pb.ProhibitionsResponse) []ngac.Prohibition {
+	prohibitions := make([]ngac.Prohibition, 0, len(resp.GetProhibitions()))
+	for _, prohibition := range resp.GetProhibitions() {
+		prohibitions = append(prohibitions, toProhibition(prohibition))
+	}
+
+	return prohibitions
+}
+
+func fromProhibitions(prohibitions []ngac.Prohibition) *pb.ProhibitionsResponse {
+	resp := &pb.ProhibitionsResponse{Prohibitions: make([]*pb.Prohibition, 0, len(prohibitions))}
+	for _, prohibition := range prohibitions {
+		resp.Prohibitions = append(resp.Prohibitions, fromProhibition(prohibition))
+	}
+
+	return resp
+}
+
+func toObligation(obligation *pb.Obligation) (ngac.Obligation, error) {
+	o := ngac.Obligation{}
+	err := json.Unmarshal(obligation.GetJson(), &o)
+	return o, err
+}
+
+func fromObligation(obligation ngac.Obligation) (*pb.Obligation, error) {
+	bytes, err := json.Marshal(&obligation)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.Obligation{Json: bytes}, nil
+}
+
+func toSSoD(constraint *pb.SSoD) ngac.SSoD {
+	return ngac.SSoD{Name: constraint.GetName(), Attributes: constraint.GetAttributes(), Max: int(constraint.GetMax())}
+}
+
+func fromSSoD(constraint ngac.SSoD) *pb.SSoD {
+	return &pb.SSoD{Name: constraint.Name, Attributes: constraint.Attributes, Max: int32(constraint.Max)}
+}
+
+func toDSoD(constraint *pb.DSoD) ngac.DSoD {
+	return ngac.DSoD{Name: constraint.GetName(), Operations: constraint.GetOperations()}
+}
+
+func fromDSoD(constraint ngac.DSoD) *pb.DSoD {
+	return &pb.DSoD{Name: constraint.Name, Operations: constraint.Operations}
+}
+
+// toStatus returns the error with the status code for the kind of policy error it is. Errors that already have a
+// status are returned as they are.
+func toStatus(err error) error {
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	code := codes.Unknown
+	switch {
+	case errors.Is(err, ngac.ErrNotFound):
+		code = codes.NotFound
+	case errors.Is(err, ngac.ErrAlreadyExists):
+		code = codes.AlreadyExists
+	case errors.Is(err, ngac.ErrInvalid):
+		code = codes.InvalidArgument
+	}
+
+	return status.Error(code, err.Error())
+}
+
+// remoteError is an error returned by the server. It has the message of the error so remote errors read the same as
+// the errors of a local policy, and it keeps the status, so status.Code returns the code of the error and errors.Is
+// matches it with the ngac error for the code.
+type remoteError struct {
+	status *status.Status
+}
+
+func toError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	return remoteError{status: status.Convert(err)}
+}
+
+func (e remoteError) Error() string {
+	return e.status.Message()
+}
+
+func (e remoteError) GRPCStatus() *status.Status {
+	return e.status
+}
+
+func (e remoteError) Is(target error) bool {
+	switch e.status.Code() {
+	case codes.NotFound:
+		return target == ngac.ErrNotFound
+	case codes.AlreadyExists:
+		return target == ngac.ErrAlreadyExists
+	case codes.InvalidArgument:
+		return target == ngac.ErrInvalid
+	case codes.DeadlineExceeded:
+		return target == context.DeadlineExceeded
+	case codes.Canceled:
+		return target == context.Canceled
+	default:
+		return false
+	}
+}
@@ -0,0 +1,239 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"github.com/PM-Master/policy-machine-go/diff"
+	"github.com/PM-Master/policy-machine-go/epp"
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
+	"github.com/PM-Master/policy-machine-go/pdp"
+	"github.com/PM-Master/policy-machine-go/pip/memory"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"net"
+	"testing"
+	"time"
+)
+
+func newClient(t *testing.T, server *Server) *Client {
+	listener := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	server.Register(s)
+	go func() {
+		_ = s.Serve(listener)
+	}()
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+		return listener.Dial()
+	}))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		conn.Close()
+	})
+
+	return NewClient(conn)
+}
+
+func newServer(pip ngac.FunctionalEntity) *Server {
+	return NewServer(pip, pdp.NewDecider(pip.Graph(), pip.Prohibitions()), epp.NewEPP(pip))
+}
+
+func TestAdministration(t *testing.T) {
+	pip := memory.NewPIP()
+	client := newClient(t, newServer(pip))
+
+	g := client.Graph()
+	require.NoError(t, g.CreatePolicyClass("pc1"))
+	_, err := g.CreateNode("ua1", graph.UserAttribute, nil, "pc1")
+	require.NoError(t, err)
+	_, err = g.CreateNode("oa1", graph.ObjectAttribute, nil, "pc1")
+	require.NoError(t, err)
+	_, err = g.CreateNode("oa2", graph.ObjectAttribute, nil, "pc1")
+	require.NoError(t, err)
+	_, err = g.CreateNode("u1", graph.User, nil, "ua1")
+	require.NoError(t, err)
+	node, err := g.CreateNode("o1", graph.Object, map[string]string{"k": "v"}, "oa1", "oa2")
+	require.NoError(t, err)
+	require.Equal(t, graph.Node{Name: "o1", Kind: graph.Object, Properties: map[string]string{"k": "v"}}, node)
+	require.NoError(t, g.UpdateNode("o1", map[string]string{"k": "v2"}))
+	require.NoError(t, g.Deassign("o1", "oa2"))
+	require.NoError(t, g.Assign("o1", "oa2"))
+	require.NoError(t, g.Associate("ua1", "oa1", graph.ToOps("read", "write")))
+	require.NoError(t, g.AssociateWithCondition("ua1", "oa2", graph.ToOps("read"), `env.network == "internal"`))
+	notAfter := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, g.SetAssociationValidity("ua1", "oa1", graph.Validity{NotAfter: &notAfter}))
+	require.NoError(t, client.Prohibitions().Add(ngac.Prohibition{
+		Name:       "deny1",
+		Subject:    "u1",
+		Containers: map[string]bool{"oa1": false},
+		Operations: graph.ToOps("write"),
+	}))
+	require.NoError(t, client.Obligations().Add(ngac.Obligation{
+		Label: "obligation1",
+		Event: ngac.EventPattern{Subject: "any_user", Operations: []ngac.EventOperation{{Operation: "read"}}},
+		Response: ngac.ResponsePattern{Actions: []ngac.Statement{
+			&ngac.CreateNodeStatement{Name: "copy", Kind: graph.Object, Parents: []string{"oa1"}},
+		}},
+	}))
+	require.NoError(t, client.Constraints().AddDSoD(ngac.DSoD{Name: "c1", Operations: []string{"read", "write"}}))
+
+	// the remote policy reads the same as the local policy
+	d, err := diff.Compare(pip, client)
+	require.NoError(t, err)
+	require.True(t, d.Empty(), d.String())
+
+	validity, err := g.GetAssociationValidity("ua1")
+	require.NoError(t, err)
+	require.Equal(t, map[string]graph.Validity{"oa1": {NotAfter: &notAfter}}, validity)
+
+	obligation, err := client.Obligations().Get("obligation1")
+	require.NoError(t, err)
+	require.Len(t, obligation.Response.Actions, 1)
+
+	// errors of the local policy are returned with the same message
+	_, localErr := pip.Graph().GetNode("missing")
+	_, err = g.GetNode("missing")
+	require.EqualError(t, err, localErr.Error())
+
+	// policy parts are marshaled and unmarshaled on the server
+	bytes, err := g.MarshalJSON()
+	require.NoError(t, err)
+	require.NoError(t, g.DeleteNode("o1"))
+	require.NoError(t, g.UnmarshalJSON(bytes))
+	ok, err := pip.Graph().Exists("o1")
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestDecisionsAndEvents(t *testing.T) {
+	pip := memory.NewPIP()
+	g := pip.Graph()
+	require.NoError(t, g.CreatePolicyClass("pc1"))
+	_, err := g.CreateNode("ua1", graph.UserAttribute, nil, "pc1")
+	require.NoError(t, err)
+	_, err = g.CreateNode("oa1", graph.ObjectAttribute, nil, "pc1")
+	require.NoError(t, err)
+	_, err = g.CreateNode("oa2", graph.ObjectAttribute, nil, "pc1")
+	require.NoError(t, err)
+	_, err = g.CreateNode("u1", graph.User, nil, "ua1")
+	require.NoError(t, err)
+	_, err = g.CreateNode("o1", graph.Object, nil, "oa1")
+	require.NoError(t, err)
+	_, err = g.CreateNode("o2", graph.Object, nil, "oa2")
+	require.NoError(t, err)
+	require.NoError(t, g.Associate("ua1", "oa1", graph.ToOps("read", "write")))
+	require.NoError(t, g.AssociateWithCondition("ua1", "oa2", graph.ToOps("read"), `env.network == "internal"`))
+	require.NoError(t, pip.Obligations().Add(ngac.Obligation{
+		Label: "obligation1",
+		Event: ngac.EventPattern{Subject: "any_user", Operations: []ngac.EventOperation{{Operation: "read"}}},
+		Response: ngac.ResponsePattern{Actions: []ngac.Statement{
+			&ngac.CreateNodeStatement{Name: "copy", Kind: graph.Object, Parents: []string{"oa1"}},
+		}},
+	}))
+
+	client := newClient(t, newServer(pip))
+	var decider pdp.Decider = client
+
+	ok, err := decider.HasPermissions("u1", "o1", "read", "write")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ops, err := decider.ListPermissions("u1", "o1")
+	require.NoError(t, err)
+	require.Equal(t, graph.ToOps("read", "write"), ops)
+
+	ok, err = decider.DecideFor("u1", "p1", "o1", "write")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	permissions, err := decider.ListPermissionsBatch("u1", []string{"o1", "o2"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]graph.Operations{"o1": graph.ToOps("read", "write"), "o2": graph.ToOps()}, permissions)
+
+	// the environment is sent with the requests of the returned decider only
+	internal := decider.WithEnvironment(map[string]string{"network": "internal"})
+	targets, err := internal.FilterAccessible("u1", []string{"o2", "o1"}, "read")
+	require.NoError(t, err)
+	require.Equal(t, []string{"o2", "o1"}, targets)
+	targets, err = decider.FilterAccessible("u1", []string{"o2", "o1"}, "read")
+	require.NoError(t, err)
+	require.Equal(t, []string{"o1"}, targets)
+
+	require.NoError(t, client.ProcessEvent(epp.EventContext{User: "u1", Event: "read", Target: "o1"}))
+	ok, err = pip.Graph().Exists("copy")
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	// a server without a decider or event processor does not serve them
+	client = newClient(t, NewServer(pip, nil, nil))
+	_, err = client.HasPermissions("u1", "o1", "read")
+	require.Error(t, err)
+	require.Error(t, client.ProcessEvent(epp.EventContext{User: "u1", Event: "read", Target: "o1"}))
+}
+
+func TestErrors(t *testing.T) {
+	pip := memory.NewPIP()
+	client := newClient(t, newServer(pip))
+
+	g := client.Graph()
+	require.NoError(t, g.CreatePolicyClass("pc1"))
+	_, err := g.CreateNode("oa1", graph.ObjectAttribute, nil, "pc1")
+	require.NoError(t, err)
+
+	// errors keep the message of the local error and have the code of its kind
+	_, err = g.GetNode("missing")
+	require.EqualError(t, err, `node "missing" does not exist`)
+	require.Equal(t, codes.NotFound, status.Code(err))
+	require.True(t, errors.Is(err, ngac.ErrNotFound))
+
+	err = g.CreatePolicyClass("pc1")
+	require.Equal(t, codes.AlreadyExists, status.Code(err))
+	require.True(t, errors.Is(err, ngac.ErrAlreadyExists))
+
+	err = g.Assign("pc1", "oa1")
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	require.True(t, errors.Is(err, ngac.ErrInvalid))
+
+	err = client.Constraints().AddDSoD(ngac.DSoD{Name: "d1", Operations: []string{"read"}})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+// blockingEPP processes events once release is closed.
+type blockingEPP struct {
+	release chan struct{}
+}
+
+func (b blockingEPP) ProcessEvent(epp.EventContext) error {
+	<-b.release
+	return nil
+}
+
+func TestClientTimeout(t *testing.T) {
+	pip := memory.NewPIP()
+	processor := blockingEPP{release: make(chan struct{})}
+	defer close(processor.release)
+
+	listener := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	NewServer(pip, nil, processor).Register(s)
+	go func() {
+		_ = s.Serve(listener)
+	}()
+	defer s.Stop()
+
+	conn, err := grpc.Dial("bufnet", grpc.WithInsecure(), grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+		return listener.Dial()
+	}))
+	require.NoError(t, err)
+	defer conn.Close()
+
+	client := NewClientWithOptions(conn, ClientOptions{Timeout: 50 * time.Millisecond})
+	err = client.ProcessEvent(epp.EventContext{User: "u1", Event: "read", Target: "o1"})
+	require.Equal(t, codes.DeadlineExceeded, status.Code(err))
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+}
@@ -0,0 +1,616 @@
+// Package rpc serves a policy, a decider and an event processor over gRPC and provides a client that implements
+// ngac.FunctionalEntity, pdp.Decider and epp.EventProcessor remotely. The services are defined in ngac.proto and the
+// code in the pb package is generated from it with buf.
+package rpc
+
+//go:generate buf generate
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/PM-Master/policy-machine-go/epp"
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
+	"github.com/PM-Master/policy-machine-go/pdp"
+	"github.com/PM-Master/policy-machine-go/rpc/pb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"sync"
+)
+
+type (
+	// Server implements the PolicyAdministration, PolicyDecision and EventProcessing services. Requests that change
+	// the policy, including events, are handled one at a time and requests that read the policy are handled
+	// concurrently with each other. Errors matching ngac.ErrNotFound, ngac.ErrAlreadyExists and ngac.ErrInvalid are
+	// returned with codes.NotFound, codes.AlreadyExists and codes.InvalidArgument.
+	Server struct {
+		mu        sync.RWMutex
+		fe        ngac.FunctionalEntity
+		decider   pdp.Decider
+		processor epp.EventProcessor
+	}
+
+	policyPart interface {
+		json.Marshaler
+		json.Unmarshaler
+	}
+)
+
+var (
+	_ pb.PolicyAdministrationServer = (*Server)(nil)
+	_ pb.PolicyDecisionServer       = (*Server)(nil)
+	_ pb.EventProcessingServer      = (*Server)(nil)
+
+	empty = &emptypb.Empty{}
+)
+
+// NewServer returns a server for the policy. The decider and event processor should be created for the same policy.
+// If either is nil the corresponding service responds with codes.Unimplemented.
+func NewServer(fe ngac.FunctionalEntity, decider pdp.Decider, processor epp.EventProcessor) *Server {
+	return &Server{fe: fe, decider: decider, processor: processor}
+}
+
+// Register registers the services of the server with the gRPC server.
+func (s *Server) Register(server *grpc.Server) {
+	pb.RegisterPolicyAdministrationServer(server, s)
+	pb.RegisterPolicyDecisionServer(server, s)
+	pb.RegisterEventProcessingServer(server, s)
+}
+
+func (s *Server) write(write func() error) (*emptypb.Empty, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := write(); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return empty, nil
+}
+
+func (s *Server) read() func() {
+	s.mu.RLock()
+	return s.mu.RUnlock
+}
+
+func (s *Server) CreatePolicyClass(_ context.Context, req *pb.NodeRequest) (*emptypb.Empty, error) {
+	return s.write(func() error {
+		return s.fe.Graph().CreatePolicyClass(req.GetName())
+	})
+}
+
+func (s *Server) CreateNode(_ context.Context, req *pb.CreateNodeRequest) (*pb.Node, error) {
+	if len(req.GetParents()) == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "node %q must have a parent", req.GetName())
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	parents := req.GetParents()
+	node, err := s.fe.Graph().CreateNode(req.GetName(), graph.Kind(req.GetKind()), req.GetProperties(), parents[0], parents[1:]...)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return fromNode(node), nil
+}
+
+func (s *Server) UpdateNode(_ context.Context, req *pb.UpdateNodeRequest) (*emptypb.Empty, error) {
+	return s.write(func() error {
+		return s.fe.Graph().UpdateNode(req.GetName(), req.GetProperties())
+	})
+}
+
+func (s *Server) DeleteNode(_ context.Context, req *pb.NodeRequest) (*emptypb.Empty, error) {
+	return s.write(func() error {
+		return s.fe.Graph().DeleteNode(req.GetName())
+	})
+}
+
+func (s *Server) Exists(_ context.Context, req *pb.NodeRequest) (*pb.ExistsResponse, error) {
+	defer s.read()()
+
+	exists, err := s.fe.Graph().Exists(req.GetName())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.ExistsResponse{Exists: exists}, nil
+}
+
+func (s *Server) GetNodes(context.Context, *emptypb.Empty) (*pb.NodesResponse, error) {
+	defer s.read()()
+
+	nodes, err := s.fe.Graph().GetNodes()
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return fromNodes(nodes), nil
+}
+
+func (s *Server) GetNode(_ context.Context, req *pb.NodeRequest) (*pb.Node, error) {
+	defer s.read()()
+
+	node, err := s.fe.Graph().GetNode(req.GetName())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return fromNode(node), nil
+}
+
+func (s *Server) Find(_ context.Context, req *pb.FindRequest) (*pb.NodesResponse, error) {
+	defer s.read()()
+
+	nodes, err := s.fe.Graph().Find(graph.Kind(req.GetKind()), req.GetProperties())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return fromNodes(nodes), nil
+}
+
+func (s *Server) Assign(_ context.Context, req *pb.Assignment) (*emptypb.Empty, error) {
+	return s.write(func() error {
+		return s.fe.Graph().Assign(req.GetChild(), req.GetParent())
+	})
+}
+
+func (s *Server) Deassign(_ context.Context, req *pb.Assignment) (*emptypb.Empty, error) {
+	return s.write(func() error {
+		return s.fe.Graph().Deassign(req.GetChild(), req.GetParent())
+	})
+}
+
+func (s *Server) GetChildren(_ context.Context, req *pb.NodeRequest) (*pb.NodesResponse, error) {
+	defer s.read()()
+
+	nodes, err := s.fe.Graph().GetChildren(req.GetName())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return fromNodes(nodes), nil
+}
+
+func (s *Server) GetParents(_ context.Context, req *pb.NodeRequest) (*pb.NodesResponse, error) {
+	defer s.read()()
+
+	nodes, err := s.fe.Graph().GetParents(req.GetName())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return fromNodes(nodes), nil
+}
+
+func (s *Server) GetAssignments(context.Context, *emptypb.Empty) (*pb.AssignmentsResponse, error) {
+	defer s.read()()
+
+	assignments, err := s.fe.Graph().GetAssignments()
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &pb.AssignmentsResponse{Assignments: make(map[string]*pb.Names, len(assignments))}
+	for child, parents := range assignments {
+		names := &pb.Names{Names: make([]string, 0, len(parents))}
+		for parent := range parents {
+			names.Names = append(names.Names, parent)
+		}
+
+		resp.Assignments[child] = names
+	}
+
+	return resp, nil
+}
+
+func (s *Server) Associate(_ context.Context, req *pb.Association) (*emptypb.Empty, error) {
+	return s.write(func() error {
+		return s.fe.Graph().Associate(req.GetSubject(), req.GetTarget(), graph.ToOps(req.GetOperations()...))
+	})
+}
+
+func (s *Server) AssociateWithCondition(_ context.Context, req *pb.Association) (*emptypb.Empty, error) {
+	return s.write(func() error {
+		return s.fe.Graph().AssociateWithCondition(req.GetSubject(), req.GetTarget(), graph.ToOps(req.GetOperations()...),
+			req.GetCondition())
+	})
+}
+
+func (s *Server) Dissociate(_ context.Context, req *pb.Association) (*emptypb.Empty, error) {
+	return s.write(func() error {
+		return s.fe.Graph().Dissociate(req.GetSubject(), req.GetTarget())
+	})
+}
+
+func (s *Server) GetAssociationsForSubject(_ context.Context, req *pb.NodeRequest) (*pb.Targets, error) {
+	defer s.read()()
+
+	associations, err := s.fe.Graph().GetAssociationsForSubject(req.GetName())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return fromTargets(associations), nil
+}
+
+func (s *Server) GetAssociations(context.Context, *emptypb.Empty) (*pb.AssociationsResponse, error) {
+	defer s.read()()
+
+	associations, err := s.fe.Graph().GetAssociations()
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &pb.AssociationsResponse{Associations: make(map[string]*pb.Targets, len(associations))}
+	for subject, targets := range associations {
+		resp.Associations[subject] = fromTargets(targets)
+	}
+
+	return resp, nil
+}
+
+func (s *Server) GetAssociationConditions(_ context.Context, req *pb.NodeRequest) (*pb.ConditionsResponse, error) {
+	defer s.read()()
+
+	conditions, err := s.fe.Graph().GetAssociationConditions(req.GetName())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.ConditionsResponse{Conditions: conditions}, nil
+}
+
+func (s *Server) SetAssociationValidity(_ context.Context, req *pb.Association) (*emptypb.Empty, error) {
+	return s.write(func() error {
+		return s.fe.Graph().SetAssociationValidity(req.GetSubject(), req.GetTarget(), toValidity(req.GetValidity()))
+	})
+}
+
+func (s *Server) GetAssociationValidity(_ context.Context, req *pb.NodeRequest) (*pb.ValidityResponse, error) {
+	defer s.read()()
+
+	validity, err := s.fe.Graph().GetAssociationValidity(req.GetName())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &pb.ValidityResponse{Validity: make(map[string]*pb.Validity, len(validity))}
+	for target, v := range validity {
+		resp.Validity[target] = fromValidity(v)
+	}
+
+	return resp, nil
+}
+
+func (s *Server) AddProhibition(_ context.Context, req *pb.Prohibition) (*emptypb.Empty, error) {
+	return s.write(func() error {
+		return s.fe.Prohibitions().Add(toProhibition(req))
+	})
+}
+
+func (s *Server) GetProhibitions(_ context.Context, req *pb.NodeRequest) (*pb.ProhibitionsResponse, error) {
+	defer s.read()()
+
+	prohibitions, err := s.fe.Prohibitions().Get(req.GetName())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return fromProhibitions(prohibitions), nil
+}
+
+func (s *Server) GetProhibition(_ context.Context, req *pb.NameRequest) (*pb.Prohibition, error) {
+	defer s.read()()
+
+	prohibition, err := s.fe.Prohibitions().GetByName(req.GetName())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return fromProhibition(prohibition), nil
+}
+
+func (s *Server) AllProhibitions(context.Context, *emptypb.Empty) (*pb.ProhibitionsResponse, error) {
+	defer s.read()()
+
+	prohibitions, err := s.fe.Prohibitions().All()
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return fromProhibitions(prohibitions), nil
+}
+
+func (s *Server) UpdateProhibition(_ context.Context, req *pb.Prohibition) (*emptypb.Empty, error) {
+	return s.write(func() error {
+		return s.fe.Prohibitions().Update(toProhibition(req))
+	})
+}
+
+func (s *Server) DeleteProhibition(_ context.Context, req *pb.DeleteProhibitionRequest) (*emptypb.Empty, error) {
+	return s.write(func() error {
+		return s.fe.Prohibitions().Delete(req.GetSubject(), req.GetName())
+	})
+}
+
+func (s *Server) AddObligation(_ context.Context, req *pb.Obligation) (*emptypb.Empty, error) {
+	obligation, err := toObligation(req)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "error reading obligation: %v", err)
+	}
+
+	return s.write(func() error {
+		return s.fe.Obligations().Add(obligation)
+	})
+}
+
+func (s *Server) RemoveObligation(_ context.Context, req *pb.NameRequest) (*emptypb.Empty, error) {
+	return s.write(func() error {
+		return s.fe.Obligations().Remove(req.GetName())
+	})
+}
+
+func (s *Server) GetObligation(_ context.Context, req *pb.NameRequest) (*pb.Obligation, error) {
+	defer s.read()()
+
+	obligation, err := s.fe.Obligations().Get(req.GetName())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return fromObligation(obligation)
+}
+
+func (s *Server) AllObligations(context.Context, *emptypb.Empty) (*pb.ObligationsResponse, error) {
+	defer s.read()()
+
+	obligations, err := s.fe.Obligations().All()
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &pb.ObligationsResponse{Obligations: make([]*pb.Obligation, 0, len(obligations))}
+	for _, obligation := range obligations {
+		o, err := fromObligation(obligation)
+		if err != nil {
+			return nil, toStatus(err)
+		}
+
+		resp.Obligations = append(resp.Obligations, o)
+	}
+
+	return resp, nil
+}
+
+func (s *Server) EnableObligation(_ context.Context, req *pb.NameRequest) (*emptypb.Empty, error) {
+	return s.write(func() error {
+		return s.fe.Obligations().Enable(req.GetName())
+	})
+}
+
+func (s *Server) DisableObligation(_ context.Context, req *pb.NameRequest) (*emptypb.Empty, error) {
+	return s.write(func() error {
+		return s.fe.Obligations().Disable(req.GetName())
+	})
+}
+
+func (s *Server) AddSSoD(_ context.Context, req *pb.SSoD) (*emptypb.Empty, error) {
+	return s.write(func() error {
+		return s.fe.Constraints().AddSSoD(toSSoD(req))
+	})
+}
+
+func (s *Server) GetSSoD(context.Context, *emptypb.Empty) (*pb.SSoDResponse, error) {
+	defer s.read()()
+
+	constraints, err := s.fe.Constraints().GetSSoD()
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &pb.SSoDResponse{Constraints: make([]*pb.SSoD, 0, len(constraints))}
+	for _, constraint := range constraints {
+		resp.Constraints = append(resp.Constraints, fromSSoD(constraint))
+	}
+
+	return resp, nil
+}
+
+func (s *Server) RemoveSSoD(_ context.Context, req *pb.NameRequest) (*emptypb.Empty, error) {
+	return s.write(func() error {
+		return s.fe.Constraints().RemoveSSoD(req.GetName())
+	})
+}
+
+func (s *Server) AddDSoD(_ context.Context, req *pb.DSoD) (*emptypb.Empty, error) {
+	return s.write(func() error {
+		return s.fe.Constraints().AddDSoD(toDSoD(req))
+	})
+}
+
+func (s *Server) GetDSoD(context.Context, *emptypb.Empty) (*pb.DSoDResponse, error) {
+	defer s.read()()
+
+	constraints, err := s.fe.Constraints().GetDSoD()
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &pb.DSoDResponse{Constraints: make([]*pb.DSoD, 0, len(constraints))}
+	for _, constraint := range constraints {
+		resp.Constraints = append(resp.Constraints, fromDSoD(constraint))
+	}
+
+	return resp, nil
+}
+
+func (s *Server) RemoveDSoD(_ context.Context, req *pb.NameRequest) (*emptypb.Empty, error) {
+	return s.write(func() error {
+		return s.fe.Constraints().RemoveDSoD(req.GetName())
+	})
+}
+
+func (s *Server) part(part pb.PolicyPart) (policyPart, error) {
+	switch part {
+	case pb.PolicyPart_GRAPH:
+		return s.fe.Graph(), nil
+	case pb.PolicyPart_PROHIBITIONS:
+		return s.fe.Prohibitions(), nil
+	case pb.PolicyPart_OBLIGATIONS:
+		return s.fe.Obligations(), nil
+	case pb.PolicyPart_CONSTRAINTS:
+		return s.fe.Constraints(), nil
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unknown policy part %d", part)
+	}
+}
+
+func (s *Server) MarshalPolicy(_ context.Context, req *pb.PolicyData) (*pb.PolicyData, error) {
+	defer s.read()()
+
+	part, err := s.part(req.GetPart())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	bytes, err := part.MarshalJSON()
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.PolicyData{Part: req.GetPart(), Json: bytes}, nil
+}
+
+func (s *Server) UnmarshalPolicy(_ context.Context, req *pb.PolicyData) (*emptypb.Empty, error) {
+	part, err := s.part(req.GetPart())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return s.write(func() error {
+		if err := part.UnmarshalJSON(req.GetJson()); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		return nil
+	})
+}
+
+// deciderFor returns the decider of the server with the environment of the request.
+func (s *Server) deciderFor(environment map[string]string) (pdp.Decider, error) {
+	if s.decider == nil {
+		return nil, status.Error(codes.Unimplemented, "decisions are not served")
+	}
+
+	if len(environment) == 0 {
+		return s.decider, nil
+	}
+
+	return s.decider.WithEnvironment(environment), nil
+}
+
+func (s *Server) HasPermissions(_ context.Context, req *pb.DecisionRequest) (*pb.DecisionResponse, error) {
+	decider, err := s.deciderFor(req.GetEnvironment())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	defer s.read()()
+
+	allowed, err := decider.HasPermissions(req.GetUser(), req.GetTarget(), req.GetPermissions()...)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.DecisionResponse{Allowed: allowed}, nil
+}
+
+func (s *Server) ListPermissions(_ context.Context, req *pb.DecisionRequest) (*pb.Operations, error) {
+	decider, err := s.deciderFor(req.GetEnvironment())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	defer s.read()()
+
+	ops, err := decider.ListPermissions(req.GetUser(), req.GetTarget())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.Operations{Operations: fromOperations(ops)}, nil
+}
+
+func (s *Server) DecideFor(_ context.Context, req *pb.DecisionRequest) (*pb.DecisionResponse, error) {
+	decider, err := s.deciderFor(req.GetEnvironment())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	defer s.read()()
+
+	allowed, err := decider.DecideFor(req.GetUser(), req.GetProcess(), req.GetTarget(), req.GetPermissions()...)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.DecisionResponse{Allowed: allowed}, nil
+}
+
+func (s *Server) ListPermissionsBatch(_ context.Context, req *pb.BatchRequest) (*pb.BatchResponse, error) {
+	decider, err := s.deciderFor(req.GetEnvironment())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	defer s.read()()
+
+	permissions, err := decider.ListPermissionsBatch(req.GetUser(), req.GetTargets())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &pb.BatchResponse{Permissions: make(map[string]*pb.Operations, len(permissions))}
+	for target, ops := range permissions {
+		resp.Permissions[target] = &pb.Operations{Operations: fromOperations(ops)}
+	}
+
+	return resp, nil
+}
+
+func (s *Server) FilterAccessible(_ context.Context, req *pb.BatchRequest) (*pb.FilterResponse, error) {
+	decider, err := s.deciderFor(req.GetEnvironment())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	defer s.read()()
+
+	targets, err := decider.FilterAccessible(req.GetUser(), req.GetTargets(), req.GetPermissions()...)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &pb.FilterResponse{Targets: targets}, nil
+}
+
+func (s *Server) ProcessEvent(_ context.Context, req *pb.Event) (*emptypb.Empty, error) {
+	if s.processor == nil {
+		return nil, status.Error(codes.Unimplemented, "events are not processed")
+	}
+
+	return s.write(func() error {
+		return s.processor.ProcessEvent(epp.EventContext{
+			User:   req.GetUser(),
+			Event:  req.GetEvent(),
+			Target: req.GetTarget(),
+			Args:   req.GetArgs(),
+		})
+	})
+}
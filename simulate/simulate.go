@@ -0,0 +1,162 @@
+// Package simulate reports the effect of policy changes on access before they are applied.
+package simulate
+
+import (
+	"fmt"
+	"github.com/PM-Master/policy-machine-go/author"
+	"github.com/PM-Master/policy-machine-go/internal/sets"
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
+	"github.com/PM-Master/policy-machine-go/pdp"
+	"github.com/PM-Master/policy-machine-go/pip/memory"
+	"io/ioutil"
+	"sort"
+	"strings"
+)
+
+type (
+	// Change is a difference in the permissions a user has on a target.
+	Change struct {
+		User   string   `json:"user"`
+		Target string   `json:"target"`
+		Gained []string `json:"gained,omitempty"`
+		Lost   []string `json:"lost,omitempty"`
+	}
+
+	// permissions holds the permissions of every user on every node of a policy, keyed by user and target.
+	permissions map[string]map[string]graph.Operations
+)
+
+// Simulate applies the statements to a copy of the policy and returns the changes in the effective permissions of
+// every user on every node, ordered by user and target. Users and targets that only exist before or after the
+// statements are applied have no permissions in the other state. The policy is not modified.
+func Simulate(fe ngac.FunctionalEntity, stmts []ngac.Statement) ([]Change, error) {
+	before, err := effectivePermissions(fe)
+	if err != nil {
+		return nil, err
+	}
+
+	clone, err := memory.Clone(fe)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, stmt := range stmts {
+		if err = stmt.Apply(clone); err != nil {
+			return nil, fmt.Errorf("error applying statement: %w", err)
+		}
+	}
+
+	after, err := effectivePermissions(clone)
+	if err != nil {
+		return nil, err
+	}
+
+	return diff(before, after), nil
+}
+
+// SimulateFile simulates the statements of the PAL file at the given path.
+func SimulateFile(fe ngac.FunctionalEntity, path string) ([]Change, error) {
+	pal, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file %q: %w", path, err)
+	}
+
+	stmts, _, err := author.Parse(string(pal))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing policy author language: %w", err)
+	}
+
+	return Simulate(fe, stmts)
+}
+
+func effectivePermissions(fe ngac.FunctionalEntity) (permissions, error) {
+	nodes, err := fe.Graph().GetNodes()
+	if err != nil {
+		return nil, err
+	}
+
+	targets := make([]string, 0, len(nodes))
+	for name := range nodes {
+		targets = append(targets, name)
+	}
+	sort.Strings(targets)
+
+	decider := pdp.NewDecider(fe.Graph(), fe.Prohibitions())
+	perms := make(permissions)
+	for name, node := range nodes {
+		if node.Kind != graph.User {
+			continue
+		}
+
+		if perms[name], err = decider.ListPermissionsBatch(name, targets); err != nil {
+			return nil, err
+		}
+	}
+
+	return perms, nil
+}
+
+func diff(before permissions, after permissions) []Change {
+	users := make(map[string]bool)
+	for user := range before {
+		users[user] = true
+	}
+	for user := range after {
+		users[user] = true
+	}
+
+	changes := make([]Change, 0)
+	for _, user := range sets.Sorted(users) {
+		targets := make(map[string]bool)
+		for target := range before[user] {
+			targets[target] = true
+		}
+		for target := range after[user] {
+			targets[target] = true
+		}
+
+		for _, target := range sets.Sorted(targets) {
+			gained := missing(after[user][target], before[user][target])
+			lost := missing(before[user][target], after[user][target])
+			if len(gained) == 0 && len(lost) == 0 {
+				continue
+			}
+
+			changes = append(changes, Change{User: user, Target: target, Gained: gained, Lost: lost})
+		}
+	}
+
+	return changes
+}
+
+// missing returns the operations in ops that are not in other, sorted.
+func missing(ops graph.Operations, other graph.Operations) []string {
+	result := make([]string, 0)
+	for op := range ops {
+		if !other[op] {
+			result = append(result, op)
+		}
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+
+	sort.Strings(result)
+	return result
+}
+
+// String formats the change as the user and target followed by the gained and lost operations.
+func (c Change) String() string {
+	b := strings.Builder{}
+	fmt.Fprintf(&b, "%s on %s:", c.User, c.Target)
+	for _, op := range c.Gained {
+		fmt.Fprintf(&b, " +%s", op)
+	}
+	for _, op := range c.Lost {
+		fmt.Fprintf(&b, " -%s", op)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,52 @@
+package simulate
+
+import (
+	"github.com/PM-Master/policy-machine-go/author"
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/pip/memory"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestSimulate(t *testing.T) {
+	fe := memory.NewPIP()
+	stmts, _, err := author.Parse(`
+create policy pc1;
+create user attribute ua1 in pc1;
+create object attribute oa1 in pc1;
+create user u1 in ua1;
+create object o1 in oa1;
+grant ua1 read on oa1;
+`)
+	require.NoError(t, err)
+	for _, stmt := range stmts {
+		require.NoError(t, stmt.Apply(fe))
+	}
+
+	stmts, _, err = author.Parse(`
+create user attribute ua2 in pc1;
+create user u2 in ua2;
+create object o2 in oa1;
+grant ua2 write on oa1;
+deny u1 read on oa1;
+`)
+	require.NoError(t, err)
+
+	changes, err := Simulate(fe, stmts)
+	require.NoError(t, err)
+	require.Equal(t, []Change{
+		{User: "u1", Target: "o1", Lost: []string{"read"}},
+		{User: "u2", Target: "o1", Gained: []string{"write"}},
+		{User: "u2", Target: "o2", Gained: []string{"write"}},
+		{User: "u2", Target: "oa1", Gained: []string{"write"}},
+	}, changes)
+	require.Equal(t, "u1 on o1: -read", changes[0].String())
+
+	// the policy is not modified
+	exists, err := fe.Graph().Exists("u2")
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	_, err = Simulate(fe, []ngac.Statement{&ngac.AssignStatement{Child: "u1", Parents: []string{"unknown"}}})
+	require.Error(t, err)
+}
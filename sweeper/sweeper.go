@@ -0,0 +1,179 @@
+// Package sweeper removes associations and prohibitions once their validity period has ended.
+package sweeper
+
+import (
+	"context"
+	"fmt"
+	"github.com/PM-Master/policy-machine-go/epp"
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"sort"
+	"time"
+)
+
+const (
+	// User is the user of the events processed by the sweeper.
+	User = "sweeper"
+	// AssociationExpired is processed for each association that is removed. The target of the event is the target
+	// of the association and the subject is passed as the "subject" arg.
+	AssociationExpired = "association_expired"
+	// ProhibitionExpired is processed for each prohibition that is removed. The target of the event is the subject
+	// of the prohibition and the name is passed as the "name" arg.
+	ProhibitionExpired = "prohibition_expired"
+)
+
+type (
+	Sweeper struct {
+		pap   ngac.FunctionalEntity
+		epp   epp.EventProcessor
+		clock func() time.Time
+		// pending are the events of removed entries that have not been processed successfully yet
+		pending []epp.EventContext
+	}
+)
+
+// New returns a Sweeper that removes expired entries from the PAP and processes an event for each with the event
+// processor, if it is not nil. If clock is nil the current time is used.
+func New(pap ngac.FunctionalEntity, eventProcessor epp.EventProcessor, clock func() time.Time) *Sweeper {
+	if clock == nil {
+		clock = time.Now
+	}
+
+	return &Sweeper{
+		pap:   pap,
+		epp:   eventProcessor,
+		clock: clock,
+	}
+}
+
+// Run sweeps every interval until the context is done or a sweep fails.
+func (s *Sweeper) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Sweep(); err != nil {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep removes the associations and prohibitions that have expired and then processes an event for each of them.
+// An entry is not seen again once it is removed, so events that fail to process are kept and retried, in order,
+// on the next sweep. Every pending event is attempted even if an earlier one fails; the first error is returned.
+func (s *Sweeper) Sweep() error {
+	now := s.clock()
+
+	sweepErr := s.sweepAssociations(now)
+	if sweepErr == nil {
+		sweepErr = s.sweepProhibitions(now)
+	}
+
+	// process the events of the entries removed before any sweep error so they are not lost
+	if err := s.processPending(); err != nil {
+		return err
+	}
+
+	return sweepErr
+}
+
+func (s *Sweeper) processPending() error {
+	if s.epp == nil {
+		s.pending = nil
+		return nil
+	}
+
+	var (
+		failed   []epp.EventContext
+		firstErr error
+	)
+	for _, event := range s.pending {
+		if err := s.epp.ProcessEvent(event); err != nil {
+			failed = append(failed, event)
+			if firstErr == nil {
+				firstErr = fmt.Errorf("error processing %s event for %q: %w", event.Event, event.Target, err)
+			}
+		}
+	}
+
+	s.pending = failed
+	if firstErr != nil && len(failed) > 1 {
+		return fmt.Errorf("%w (%d events pending)", firstErr, len(failed))
+	}
+
+	return firstErr
+}
+
+func (s *Sweeper) sweepAssociations(now time.Time) error {
+	assocs, err := s.pap.Graph().GetAssociations()
+	if err != nil {
+		return err
+	}
+
+	subjects := make([]string, 0, len(assocs))
+	for subject := range assocs {
+		subjects = append(subjects, subject)
+	}
+	sort.Strings(subjects)
+
+	for _, subject := range subjects {
+		validity, err := s.pap.Graph().GetAssociationValidity(subject)
+		if err != nil {
+			return err
+		}
+
+		targets := make([]string, 0, len(validity))
+		for target, v := range validity {
+			if v.Expired(now) {
+				targets = append(targets, target)
+			}
+		}
+		sort.Strings(targets)
+
+		for _, target := range targets {
+			if err = s.pap.Graph().Dissociate(subject, target); err != nil {
+				return fmt.Errorf("error removing expired association %q -> %q: %w", subject, target, err)
+			}
+
+			s.pending = append(s.pending, epp.EventContext{
+				User:   User,
+				Event:  AssociationExpired,
+				Target: target,
+				Args:   map[string]string{"subject": subject},
+			})
+		}
+	}
+
+	return nil
+}
+
+func (s *Sweeper) sweepProhibitions(now time.Time) error {
+	prohibitions, err := s.pap.Prohibitions().All()
+	if err != nil {
+		return err
+	}
+
+	for _, prohibition := range prohibitions {
+		if !prohibition.Expired(now) {
+			continue
+		}
+
+		if err = s.pap.Prohibitions().Delete(prohibition.Subject, prohibition.Name); err != nil {
+			return fmt.Errorf("error removing expired prohibition %q: %w", prohibition.Name, err)
+		}
+
+		s.pending = append(s.pending, epp.EventContext{
+			User:   User,
+			Event:  ProhibitionExpired,
+			Target: prohibition.Subject,
+			Args:   map[string]string{"name": prohibition.Name},
+		})
+	}
+
+	return nil
+}
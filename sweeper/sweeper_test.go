@@ -0,0 +1,153 @@
+package sweeper
+
+import (
+	"context"
+	"errors"
+	"github.com/PM-Master/policy-machine-go/epp"
+	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
+	"github.com/PM-Master/policy-machine-go/pip/memory"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestSweep(t *testing.T) {
+	pip := memory.NewPIP()
+	g := pip.Graph()
+	require.NoError(t, g.CreatePolicyClass("pc1"))
+	_, err := g.CreateNode("oa1", graph.ObjectAttribute, nil, "pc1")
+	require.NoError(t, err)
+	_, err = g.CreateNode("oa2", graph.ObjectAttribute, nil, "pc1")
+	require.NoError(t, err)
+	_, err = g.CreateNode("ua1", graph.UserAttribute, nil, "pc1")
+	require.NoError(t, err)
+
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	require.NoError(t, g.Associate("ua1", "oa1", graph.ToOps("read")))
+	require.NoError(t, g.SetAssociationValidity("ua1", "oa1", graph.Validity{NotAfter: &past}))
+	require.NoError(t, g.Associate("ua1", "oa2", graph.ToOps("read")))
+	require.NoError(t, g.SetAssociationValidity("ua1", "oa2", graph.Validity{NotAfter: &future}))
+
+	require.NoError(t, pip.Prohibitions().Add(ngac.Prohibition{
+		Name:       "expired",
+		Subject:    "ua1",
+		Containers: map[string]bool{"oa1": false},
+		Operations: graph.ToOps("read"),
+		Validity:   graph.Validity{NotAfter: &past},
+	}))
+	require.NoError(t, pip.Prohibitions().Add(ngac.Prohibition{
+		Name:       "active",
+		Subject:    "ua1",
+		Containers: map[string]bool{"oa2": false},
+		Operations: graph.ToOps("read"),
+	}))
+
+	// record the expired entries in the graph
+	require.NoError(t, pip.Obligations().Add(ngac.Obligation{
+		Label: "record association",
+		Event: ngac.EventPattern{
+			Subject:    User,
+			Operations: []ngac.EventOperation{{Operation: AssociationExpired}},
+		},
+		Response: ngac.ResponsePattern{Actions: []ngac.Statement{
+			&ngac.CreateNodeStatement{Name: "$subject-$target", Kind: graph.ObjectAttribute, Parents: []string{"pc1"}},
+		}},
+	}))
+	require.NoError(t, pip.Obligations().Add(ngac.Obligation{
+		Label: "record prohibition",
+		Event: ngac.EventPattern{
+			Subject:    User,
+			Operations: []ngac.EventOperation{{Operation: ProhibitionExpired}},
+		},
+		Response: ngac.ResponsePattern{Actions: []ngac.Statement{
+			&ngac.CreateNodeStatement{Name: "$name", Kind: graph.ObjectAttribute, Parents: []string{"pc1"}},
+		}},
+	}))
+
+	sweeper := New(pip, epp.NewEPP(pip), func() time.Time { return now })
+	require.NoError(t, sweeper.Sweep())
+
+	assocs, err := g.GetAssociationsForSubject("ua1")
+	require.NoError(t, err)
+	require.Equal(t, map[string]graph.Operations{"oa2": graph.ToOps("read")}, assocs)
+
+	prohibitions, err := pip.Prohibitions().All()
+	require.NoError(t, err)
+	require.Len(t, prohibitions, 1)
+	require.Equal(t, "active", prohibitions[0].Name)
+
+	for _, name := range []string{"ua1-oa1", "expired"} {
+		ok, err := g.Exists(name)
+		require.NoError(t, err)
+		require.True(t, ok, name)
+	}
+
+	// nothing else has expired
+	require.NoError(t, sweeper.Sweep())
+	assocs, err = g.GetAssociationsForSubject("ua1")
+	require.NoError(t, err)
+	require.Len(t, assocs, 1)
+}
+
+func TestRun(t *testing.T) {
+	pip := memory.NewPIP()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := New(pip, nil, nil).Run(ctx, time.Millisecond)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+type flakyEPP struct {
+	fail      map[string]bool
+	processed []string
+}
+
+func (f *flakyEPP) ProcessEvent(eventCtx epp.EventContext) error {
+	if f.fail[eventCtx.Target] {
+		return errors.New("unavailable")
+	}
+
+	f.processed = append(f.processed, eventCtx.Target)
+	return nil
+}
+
+func TestSweepRetriesFailedEvents(t *testing.T) {
+	pip := memory.NewPIP()
+	g := pip.Graph()
+	require.NoError(t, g.CreatePolicyClass("pc1"))
+	_, err := g.CreateNode("ua1", graph.UserAttribute, nil, "pc1")
+	require.NoError(t, err)
+
+	now := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	past := now.Add(-time.Hour)
+	for _, oa := range []string{"oa1", "oa2", "oa3"} {
+		_, err = g.CreateNode(oa, graph.ObjectAttribute, nil, "pc1")
+		require.NoError(t, err)
+		require.NoError(t, g.Associate("ua1", oa, graph.ToOps("read")))
+		require.NoError(t, g.SetAssociationValidity("ua1", oa, graph.Validity{NotAfter: &past}))
+	}
+
+	processor := &flakyEPP{fail: map[string]bool{"oa2": true}}
+	sweeper := New(pip, processor, func() time.Time { return now })
+
+	// the events after the failed one are still processed
+	require.Error(t, sweeper.Sweep())
+	require.Equal(t, []string{"oa1", "oa3"}, processor.processed)
+
+	assocs, err := g.GetAssociationsForSubject("ua1")
+	require.NoError(t, err)
+	require.Empty(t, assocs)
+
+	// the failed event is retried on the next sweep
+	processor.fail = nil
+	require.NoError(t, sweeper.Sweep())
+	require.Equal(t, []string{"oa1", "oa3", "oa2"}, processor.processed)
+
+	require.NoError(t, sweeper.Sweep())
+	require.Len(t, processor.processed, 3)
+}
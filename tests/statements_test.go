@@ -2,6 +2,7 @@ package tests
 
 import (
 	"github.com/PM-Master/policy-machine-go/ngac"
+	"github.com/PM-Master/policy-machine-go/ngac/graph"
 	"github.com/PM-Master/policy-machine-go/pip/memory"
 	"github.com/stretchr/testify/require"
 	"testing"
@@ -32,3 +33,61 @@ func TestCreatePolicyClassStatementWithStatements(t *testing.T) {
 	require.NoError(t, err)
 	require.True(t, exists)
 }
+
+func TestDenyStatements(t *testing.T) {
+	pip := memory.NewPIP()
+
+	stmt := ngac.DenyStatement{
+		Name:       "no_writes",
+		Subject:    "ua1",
+		Operations: graph.ToOps("write"),
+		Containers: []string{"!oa1"},
+	}
+	require.NoError(t, stmt.Apply(pip))
+	require.Error(t, stmt.Apply(pip))
+
+	prohibition, err := pip.Prohibitions().GetByName("no_writes")
+	require.NoError(t, err)
+	require.Equal(t, "ua1", prohibition.Subject)
+	require.Equal(t, map[string]bool{"oa1": true}, prohibition.Containers)
+
+	deleteStmt := ngac.DeleteProhibitionStatement{Name: "no_writes"}
+	require.NoError(t, deleteStmt.Apply(pip))
+	require.Error(t, deleteStmt.Apply(pip))
+
+	all, err := pip.Prohibitions().All()
+	require.NoError(t, err)
+	require.Empty(t, all)
+}
+
+func TestSSoDStatement(t *testing.T) {
+	pip := memory.NewPIP()
+	g := pip.Graph()
+	require.NoError(t, g.CreatePolicyClass("pc1"))
+	_, err := g.CreateNode("Requester", graph.UserAttribute, nil, "pc1")
+	require.NoError(t, err)
+	_, err = g.CreateNode("Approver", graph.UserAttribute, nil, "pc1")
+	require.NoError(t, err)
+	_, err = g.CreateNode("u1", graph.User, nil, "Requester", "Approver")
+	require.NoError(t, err)
+	_, err = g.CreateNode("u2", graph.User, nil, "Requester")
+	require.NoError(t, err)
+
+	// constraints are not checked against the existing graph when they are added
+	stmt := &ngac.SSoDStatement{Attributes: []string{"Requester", "Approver"}, Max: 1}
+	require.NoError(t, stmt.Apply(pip))
+
+	constraints, err := pip.Constraints().GetSSoD()
+	require.NoError(t, err)
+	require.Equal(t, "ssod-Requester-Approver", constraints[0].Name)
+
+	violations, err := ngac.ValidateSSoD(g, constraints)
+	require.NoError(t, err)
+	require.Equal(t, []ngac.SSoDViolation{{
+		Constraint: "ssod-Requester-Approver",
+		User:       "u1",
+		Attributes: []string{"Requester", "Approver"},
+	}}, violations)
+
+	require.Error(t, g.Assign("u2", "Approver"))
+}